@@ -0,0 +1,156 @@
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Codes of the errors, so the clients branch on them instead of the messages.
+const (
+	CODE_BAD_REQUEST       = "bad_request"
+	CODE_VALIDATION        = "validation_failed"
+	CODE_UNAUTHORIZED      = "unauthorized"
+	CODE_FORBIDDEN         = "forbidden"
+	CODE_NOT_FOUND         = "not_found"
+	CODE_CONFLICT          = "conflict"
+	CODE_TOO_MANY_REQUESTS = "too_many_requests"
+	CODE_UNAVAILABLE       = "unavailable"
+	CODE_INTERNAL          = "internal_error"
+)
+
+const (
+	INTERNAL_MESSAGE     = "Erro interno, tente novamente mais tarde"
+	UNAVAILABLE_MESSAGE  = "Serviço indisponível, tente novamente mais tarde"
+	NOT_FOUND_MESSAGE    = "Registro não encontrado"
+	CONFLICT_MESSAGE     = "Registro já existente"
+	INVALID_BODY_MESSAGE = "Corpo da requisição inválido"
+)
+
+// Error is an error to be answered to the client. Err is the cause, which is
+// logged but never answered.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Details interface{}
+	Err     error
+}
+
+// FieldError is the detail of a Validation error about one field of the body.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Response is the body answered for an Error.
+type Response struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestId string      `json:"request_id,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap returns a copy of the error with the cause.
+func (e *Error) Wrap(err error) *Error {
+	result := *e
+	result.Err = err
+	return &result
+}
+
+// WithDetails returns a copy of the error with the details.
+func (e *Error) WithDetails(details interface{}) *Error {
+	result := *e
+	result.Details = details
+	return &result
+}
+
+// Response returns the body of the error for the request.
+func (e *Error) Response(requestId string) Response {
+	return Response{Code: e.Code, Message: e.Message, Details: e.Details, RequestId: requestId}
+}
+
+func New(status int, code string, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// BadRequest is for requests that cannot be read, such as a malformed body.
+func BadRequest(message string) *Error {
+	return New(http.StatusBadRequest, CODE_BAD_REQUEST, message)
+}
+
+// Validation is for requests that were read but hold invalid values; fields
+// tells which ones.
+func Validation(message string, fields ...FieldError) *Error {
+	err := New(http.StatusUnprocessableEntity, CODE_VALIDATION, message)
+	if len(fields) > 0 {
+		err.Details = fields
+	}
+	return err
+}
+
+// InvalidField is a Validation error about a single field.
+func InvalidField(field string, message string) *Error {
+	return Validation(message, FieldError{Field: field, Message: message})
+}
+
+func Unauthorized(message string) *Error {
+	return New(http.StatusUnauthorized, CODE_UNAUTHORIZED, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(http.StatusForbidden, CODE_FORBIDDEN, message)
+}
+
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, CODE_NOT_FOUND, message)
+}
+
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, CODE_CONFLICT, message)
+}
+
+func TooManyRequests(message string) *Error {
+	return New(http.StatusTooManyRequests, CODE_TOO_MANY_REQUESTS, message)
+}
+
+// Internal hides the cause from the client behind a generic message.
+func Internal(err error) *Error {
+	return New(http.StatusInternalServerError, CODE_INTERNAL, INTERNAL_MESSAGE).Wrap(err)
+}
+
+// From returns the Error of err, mapping the known errors of the database and
+// hiding the unknown ones behind Internal.
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var appError *Error
+
+	switch {
+	case errors.As(err, &appError):
+		return appError
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return NotFound(NOT_FOUND_MESSAGE).Wrap(err)
+	case mongo.IsDuplicateKeyError(err):
+		return Conflict(CONFLICT_MESSAGE).Wrap(err)
+	case errors.Is(err, context.DeadlineExceeded), mongo.IsTimeout(err), mongo.IsNetworkError(err):
+		return New(http.StatusServiceUnavailable, CODE_UNAVAILABLE, UNAVAILABLE_MESSAGE).Wrap(err)
+	}
+
+	return Internal(err)
+}
@@ -0,0 +1,38 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestFrom(t *testing.T) {
+	assert.Nil(t, From(nil))
+
+	conflict := Conflict("Nome de usuário já em uso")
+	assert.Same(t, conflict, From(fmt.Errorf("updating the user: %w", conflict)))
+
+	notFound := From(fmt.Errorf("finding the job: %w", mongo.ErrNoDocuments))
+	assert.Equal(t, http.StatusNotFound, notFound.Status)
+	assert.Equal(t, CODE_NOT_FOUND, notFound.Code)
+
+	duplicate := From(mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "E11000 duplicate key"}}})
+	assert.Equal(t, http.StatusConflict, duplicate.Status)
+
+	internal := From(errors.New("connection refused to mongodb://secret@db"))
+	assert.Equal(t, http.StatusInternalServerError, internal.Status)
+	assert.Equal(t, INTERNAL_MESSAGE, internal.Response("req-1").Message)
+	assert.Contains(t, internal.Error(), "connection refused")
+}
+
+func TestValidation(t *testing.T) {
+	err := InvalidField("email", "E-mail não informado")
+
+	assert.Equal(t, http.StatusUnprocessableEntity, err.Status)
+	assert.Equal(t, []FieldError{{Field: "email", Message: "E-mail não informado"}}, err.Response("").Details)
+	assert.Nil(t, Validation("Dados inválidos").Details)
+}
@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Fallback is a Store that uses the primary store, usually Redis, and falls
+// back to an in-memory cache while the primary is unreachable. After a
+// failure the primary is only tried again once the retry interval elapses,
+// so the requests do not wait for the connection timeout each time.
+type Fallback struct {
+	primary       Store
+	memory        *Memory
+	retryInterval time.Duration
+
+	mutex    sync.Mutex
+	degraded bool
+	retryAt  time.Time
+	now      func() time.Time
+}
+
+func NewFallback(primary Store, memory *Memory, retryInterval time.Duration) *Fallback {
+	return &Fallback{
+		primary:       primary,
+		memory:        memory,
+		retryInterval: retryInterval,
+		now:           time.Now,
+	}
+}
+
+// Degraded reports whether the in-memory cache is in use.
+func (cache *Fallback) Degraded() bool {
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return cache.degraded
+}
+
+func (cache *Fallback) Get(ctx context.Context, key string) ([]byte, error) {
+
+	if cache.available() {
+
+		value, err := cache.primary.Get(ctx, key)
+
+		if err == nil || errors.Is(err, ErrNotFound) {
+			cache.recovered(ctx)
+			return value, err
+		}
+
+		cache.failed(ctx, err)
+	}
+
+	return cache.memory.Get(ctx, key)
+}
+
+func (cache *Fallback) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+
+	if cache.available() {
+
+		err := cache.primary.Set(ctx, key, value, expiration)
+
+		if err == nil {
+			cache.recovered(ctx)
+			return nil
+		}
+
+		cache.failed(ctx, err)
+	}
+
+	return cache.memory.Set(ctx, key, value, expiration)
+}
+
+// Delete removes the keys from both stores, so the in-memory cache never
+// serves a value invalidated while the primary was up.
+func (cache *Fallback) Delete(ctx context.Context, keys ...string) error {
+
+	cache.memory.Delete(ctx, keys...)
+
+	if cache.available() {
+
+		err := cache.primary.Delete(ctx, keys...)
+
+		if err == nil {
+			cache.recovered(ctx)
+			return nil
+		}
+
+		cache.failed(ctx, err)
+	}
+
+	return nil
+}
+
+func (cache *Fallback) TTL(ctx context.Context, key string) (time.Duration, error) {
+
+	if cache.available() {
+
+		ttl, err := cache.primary.TTL(ctx, key)
+
+		if err == nil || errors.Is(err, ErrNotFound) {
+			cache.recovered(ctx)
+			return ttl, err
+		}
+
+		cache.failed(ctx, err)
+	}
+
+	return cache.memory.TTL(ctx, key)
+}
+
+// Close closes the primary store, if it can be closed.
+func (cache *Fallback) Close() error {
+
+	if closer, ok := cache.primary.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+func (cache *Fallback) available() bool {
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return !cache.degraded || !cache.now().Before(cache.retryAt)
+}
+
+func (cache *Fallback) failed(ctx context.Context, err error) {
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if !cache.degraded {
+		slog.WarnContext(ctx, "Cache unreachable, using the in-memory cache", "error", err, "retry_interval", cache.retryInterval)
+	}
+
+	cache.degraded = true
+	cache.retryAt = cache.now().Add(cache.retryInterval)
+}
+
+// recovered leaves the degraded mode. The values written to memory meanwhile
+// are dropped, since the primary may have changed in the meantime.
+func (cache *Fallback) recovered(ctx context.Context) {
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if !cache.degraded {
+		return
+	}
+
+	slog.InfoContext(ctx, "Cache reachable again")
+
+	cache.degraded = false
+	cache.memory.Clear()
+}
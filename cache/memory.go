@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory is an in-process LRU cache: when it is full, setting a new key
+// evicts the least recently used one.
+type Memory struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+func NewMemory(capacity int) *Memory {
+
+	if capacity <= 0 {
+		capacity = 1
+	}
+
+	return &Memory{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+func (cache *Memory) Get(ctx context.Context, key string) ([]byte, error) {
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entry, ok := cache.lookup(key)
+
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return append([]byte(nil), entry.value...), nil
+}
+
+func (cache *Memory) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entry := &memoryEntry{key: key, value: append([]byte(nil), value...)}
+
+	if expiration > 0 {
+		entry.expiresAt = cache.now().Add(expiration)
+	}
+
+	if element, ok := cache.entries[key]; ok {
+		element.Value = entry
+		cache.order.MoveToFront(element)
+		return nil
+	}
+
+	cache.entries[key] = cache.order.PushFront(entry)
+
+	for cache.order.Len() > cache.capacity {
+		cache.remove(cache.order.Back())
+	}
+
+	return nil
+}
+
+func (cache *Memory) Delete(ctx context.Context, keys ...string) error {
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for _, key := range keys {
+		if element, ok := cache.entries[key]; ok {
+			cache.remove(element)
+		}
+	}
+
+	return nil
+}
+
+func (cache *Memory) TTL(ctx context.Context, key string) (time.Duration, error) {
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entry, ok := cache.lookup(key)
+
+	if !ok {
+		return 0, ErrNotFound
+	}
+
+	if entry.expiresAt.IsZero() {
+		return 0, nil
+	}
+
+	return entry.expiresAt.Sub(cache.now()), nil
+}
+
+// Clear removes every key.
+func (cache *Memory) Clear() {
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.entries = map[string]*list.Element{}
+	cache.order.Init()
+}
+
+// Len returns the number of keys, including the expired ones not yet evicted.
+func (cache *Memory) Len() int {
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return cache.order.Len()
+}
+
+// lookup returns the entry of the key and marks it as the most recently used;
+// an expired entry is removed.
+func (cache *Memory) lookup(key string) (*memoryEntry, bool) {
+
+	element, ok := cache.entries[key]
+
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*memoryEntry)
+
+	if !entry.expiresAt.IsZero() && !cache.now().Before(entry.expiresAt) {
+		cache.remove(element)
+		return nil, false
+	}
+
+	cache.order.MoveToFront(element)
+
+	return entry, true
+}
+
+func (cache *Memory) remove(element *list.Element) {
+	cache.order.Remove(element)
+	delete(cache.entries, element.Value.(*memoryEntry).key)
+}
@@ -1,32 +1,135 @@
 package cache
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"os"
+	"time"
+	"log/slog"
 
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/tracing"
 	"github.com/go-redis/redis"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// Redis is a Store backed by a Redis server. The client keeps a pool of
+// connections, so a single Redis is shared by the whole application.
 type Redis struct {
- RedisClient redis.Client
+ RedisClient *redis.Client
 }
 
-func NewRedis() (Redis, error) {
+// NewRedisClient returns a Redis without connecting to the server; the
+// connections are opened on the first command.
+func NewRedisClient(settings config.RedisConfig) *Redis {
 
     var client = redis.NewClient(&redis.Options{
-        Addr:     os.Getenv("REDIS_SERVER"),
-        Password: os.Getenv("REDIS_PASSWORD"), 
+        Addr:     settings.Server,
+        Password: settings.Password, 
 		DB:	   0,
     }) 
-    
-    _, err := client.Ping().Result()
-    if err != nil {
-        fmt.Println(err.Error())
-        return Redis{}, errors.New("failed to connect to Redis")
+
+    return &Redis{
+        RedisClient: client,
+    }
+}
+
+// NewRedis returns a Redis connected to the server.
+func NewRedis(settings config.RedisConfig) (*Redis, error) {
+
+    cache := NewRedisClient(settings)
+
+    if err := cache.Ping(context.Background()); err != nil {
+        slog.Error("Error connecting to Redis", "error", err)
+        cache.Close()
+        return nil, errors.New("failed to connect to Redis")
     }
 
-    return Redis{
-        RedisClient: *client,
-    }, nil
-}
\ No newline at end of file
+    return cache, nil
+}
+
+func (cache *Redis) Ping(ctx context.Context) error {
+
+	_, span := tracing.Start(ctx, "redis.PING", attribute.String("db.system", "redis"))
+
+	err := cache.RedisClient.Ping().Err()
+
+	tracing.End(span, err)
+
+	return err
+}
+
+// Get returns the value of the key, or ErrNotFound when the key does not exist.
+func (cache *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+
+	_, span := tracing.Start(ctx, "redis.GET", attribute.String("db.system", "redis"), attribute.String("db.redis.key", key))
+
+	value, err := cache.RedisClient.Get(key).Bytes()
+
+	if err == redis.Nil {
+		span.SetAttributes(attribute.Bool("cache.hit", false))
+		tracing.End(span, nil)
+		return nil, ErrNotFound
+	}
+
+	span.SetAttributes(attribute.Bool("cache.hit", err == nil))
+	tracing.End(span, err)
+
+	return value, err
+}
+
+// Set stores the value of the key; a zero expiration keeps it forever.
+func (cache *Redis) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+
+	_, span := tracing.Start(ctx, "redis.SET", attribute.String("db.system", "redis"), attribute.String("db.redis.key", key))
+
+	err := cache.RedisClient.Set(key, value, expiration).Err()
+
+	tracing.End(span, err)
+
+	return err
+}
+
+func (cache *Redis) Delete(ctx context.Context, keys ...string) error {
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	_, span := tracing.Start(ctx, "redis.DEL", attribute.String("db.system", "redis"), attribute.StringSlice("db.redis.keys", keys))
+
+	err := cache.RedisClient.Del(keys...).Err()
+
+	tracing.End(span, err)
+
+	return err
+}
+
+// TTL returns the time left before the key expires, zero when it does not
+// expire, or ErrNotFound when the key does not exist.
+func (cache *Redis) TTL(ctx context.Context, key string) (time.Duration, error) {
+
+	_, span := tracing.Start(ctx, "redis.TTL", attribute.String("db.system", "redis"), attribute.String("db.redis.key", key))
+
+	ttl, err := cache.RedisClient.TTL(key).Result()
+
+	tracing.End(span, err)
+
+	if err != nil {
+		return 0, err
+	}
+
+	// Redis answers -2 when the key does not exist and -1 when it has no
+	// expiration.
+	switch ttl {
+	case -2 * time.Second:
+		return 0, ErrNotFound
+	case -1 * time.Second:
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+func (cache *Redis) Close() error {
+	return cache.RedisClient.Close()
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/assert"
 )
@@ -23,10 +24,16 @@ func TestMain(m *testing.M) {
 
 func TestNewRedis(t *testing.T) {
     // Call the NewRedis function
-    redisInstance, err := NewRedis()
+    redisInstance, err := NewRedis(config.RedisConfig{
+        Server:   os.Getenv("REDIS_SERVER"),
+        Password: os.Getenv("REDIS_PASSWORD"),
+    })
 
     // Assert no error occurred
-    assert.NoError(t, err)
+    if !assert.NoError(t, err) {
+        return
+    }
+    defer redisInstance.Close()
 
     // Assert the Redis client is not nil
     assert.NotNil(t, redisInstance.RedisClient)    
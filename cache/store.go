@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by Get and TTL when the key does not exist or has
+// expired.
+var ErrNotFound = errors.New("cache: key not found")
+
+// Store is a key/value cache. A zero expiration keeps the value until it is
+// deleted or, in memory, evicted.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, expiration time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// TTL returns the time left before the key expires, or zero when it does
+	// not expire.
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+type namespace struct {
+	store  Store
+	prefix string
+}
+
+// Namespace prefixes the keys with "name:", so the caches of different
+// features sharing a store do not collide.
+func Namespace(store Store, name string) Store {
+	return namespace{store: store, prefix: name + ":"}
+}
+
+func (namespace namespace) Get(ctx context.Context, key string) ([]byte, error) {
+	return namespace.store.Get(ctx, namespace.prefix+key)
+}
+
+func (namespace namespace) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	return namespace.store.Set(ctx, namespace.prefix+key, value, expiration)
+}
+
+func (namespace namespace) Delete(ctx context.Context, keys ...string) error {
+
+	prefixed := make([]string, len(keys))
+
+	for i, key := range keys {
+		prefixed[i] = namespace.prefix + key
+	}
+
+	return namespace.store.Delete(ctx, prefixed...)
+}
+
+func (namespace namespace) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return namespace.store.TTL(ctx, namespace.prefix+key)
+}
@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryEvictsTheLeastRecentlyUsedKey(t *testing.T) {
+	ctx := context.Background()
+	memory := NewMemory(2)
+
+	memory.Set(ctx, "a", []byte("1"), 0)
+	memory.Set(ctx, "b", []byte("2"), 0)
+	memory.Get(ctx, "a")
+	memory.Set(ctx, "c", []byte("3"), 0)
+
+	_, err := memory.Get(ctx, "b")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	value, err := memory.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", string(value))
+	assert.Equal(t, 2, memory.Len())
+}
+
+func TestMemoryExpiration(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	memory := NewMemory(10)
+	memory.now = func() time.Time { return now }
+
+	memory.Set(ctx, "a", []byte("1"), time.Minute)
+	memory.Set(ctx, "b", []byte("2"), 0)
+
+	ttl, err := memory.TTL(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, ttl)
+
+	ttl, err = memory.TTL(ctx, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), ttl)
+
+	now = now.Add(time.Minute)
+
+	_, err = memory.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = memory.TTL(ctx, "a")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestNamespace(t *testing.T) {
+	ctx := context.Background()
+	memory := NewMemory(10)
+	roles := Namespace(memory, "roles")
+
+	roles.Set(ctx, "all", []byte("[]"), 0)
+
+	value, err := memory.Get(ctx, "roles:all")
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", string(value))
+
+	roles.Delete(ctx, "all")
+
+	_, err = memory.Get(ctx, "roles:all")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// unreachableStore fails like a Redis server that is down.
+type unreachableStore struct {
+	down bool
+	*Memory
+}
+
+var errUnreachable = errors.New("dial tcp: connection refused")
+
+func (store *unreachableStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if store.down {
+		return nil, errUnreachable
+	}
+	return store.Memory.Get(ctx, key)
+}
+
+func (store *unreachableStore) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	if store.down {
+		return errUnreachable
+	}
+	return store.Memory.Set(ctx, key, value, expiration)
+}
+
+func TestFallbackUsesMemoryWhileThePrimaryIsDown(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	primary := &unreachableStore{down: true, Memory: NewMemory(10)}
+	store := NewFallback(primary, NewMemory(10), 30*time.Second)
+	store.now = func() time.Time { return now }
+
+	assert.NoError(t, store.Set(ctx, "roles", []byte("[]"), 0))
+	assert.True(t, store.Degraded())
+
+	value, err := store.Get(ctx, "roles")
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", string(value))
+
+	// The primary is not tried again before the retry interval.
+	primary.down = false
+	_, err = store.Get(ctx, "roles")
+	assert.NoError(t, err)
+	assert.True(t, store.Degraded())
+
+	now = now.Add(30 * time.Second)
+
+	_, err = store.Get(ctx, "roles")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.False(t, store.Degraded())
+	assert.Equal(t, 0, store.memory.Len())
+}
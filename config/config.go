@@ -0,0 +1,427 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DEFAULT_ENV_FILE  = ".env"
+	DEFAULT_YAML_FILE = "config.yaml"
+	DATE_FORMAT       = "2006-01-02"
+)
+
+type Config struct {
+	Version          string        `yaml:"version" env:"VERSION"`
+	DebugMode        bool          `yaml:"debug_mode" env:"DEBUG_MODE"`
+	Port             string        `yaml:"port" env:"PORT"`
+	BaseUiHost       string        `yaml:"base_ui_host" env:"BASE_UI_HOST"`
+	UploadsDir       string        `yaml:"uploads_dir" env:"UPLOADS_DIR"`
+	ShutdownTimeout  time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	MigrateOnStartup bool          `yaml:"migrate_on_startup" env:"MIGRATE_ON_STARTUP"`
+	EnsureIndexes    bool          `yaml:"ensure_indexes" env:"ENSURE_INDEXES"`
+	// TrustedProxies are the IP addresses or CIDR ranges of the proxies whose
+	// X-Forwarded-For and X-Real-IP headers give the IP address of the client.
+	// With none, the client is the address of the connection.
+	TrustedProxies []string        `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+	Log            LogConfig       `yaml:"log"`
+	Tracing        TracingConfig   `yaml:"tracing"`
+	Auth           AuthConfig      `yaml:"auth"`
+	MongoDb        MongoDbConfig   `yaml:"mongodb"`
+	Redis          RedisConfig     `yaml:"redis"`
+	Cache          CacheConfig     `yaml:"cache"`
+	HttpCache      HttpCacheConfig `yaml:"http_cache"`
+	RateLimit      RateLimitConfig `yaml:"rate_limit"`
+	Login          LoginConfig     `yaml:"login"`
+	Api            ApiConfig       `yaml:"api"`
+	Jobs           JobsConfig      `yaml:"jobs"`
+}
+
+type LogConfig struct {
+	// Level is debug, info, warn or error.
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+	// Format is json or text.
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+type TracingConfig struct {
+	// Exporter is otlp, stdout or none.
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER"`
+	ServiceName string  `yaml:"service_name" env:"TRACING_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+	// OtlpEndpoint is the host:port of the OTLP/HTTP collector; when empty the
+	// standard OTEL_EXPORTER_OTLP_* variables are used.
+	OtlpEndpoint string `yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	OtlpInsecure bool   `yaml:"otlp_insecure" env:"TRACING_OTLP_INSECURE"`
+}
+
+type AuthConfig struct {
+	JwtSecret    string `yaml:"jwt_secret" env:"JWT_SECRET"`
+	CookieDomain string `yaml:"cookie_domain" env:"COOKIE_DOMAIN"`
+	CookieSecure bool   `yaml:"cookie_secure" env:"COOKIE_SECURE"`
+}
+
+type MongoDbConfig struct {
+	Url                    string        `yaml:"url" env:"MONGODB_URL"`
+	Database               string        `yaml:"database" env:"MONGODB_DATABASE"`
+	MaxPoolSize            uint64        `yaml:"max_pool_size" env:"MONGODB_MAX_POOL_SIZE"`
+	MinPoolSize            uint64        `yaml:"min_pool_size" env:"MONGODB_MIN_POOL_SIZE"`
+	MaxConnIdleTime        time.Duration `yaml:"max_conn_idle_time" env:"MONGODB_MAX_CONN_IDLE_TIME"`
+	ConnectTimeout         time.Duration `yaml:"connect_timeout" env:"MONGODB_CONNECT_TIMEOUT"`
+	ServerSelectionTimeout time.Duration `yaml:"server_selection_timeout" env:"MONGODB_SERVER_SELECTION_TIMEOUT"`
+}
+
+type RedisConfig struct {
+	Server   string `yaml:"server" env:"REDIS_SERVER"`
+	Password string `yaml:"password" env:"REDIS_PASSWORD"`
+}
+
+type CacheConfig struct {
+	// MemorySize is the number of keys of the in-memory cache used while
+	// Redis is unreachable.
+	MemorySize int `yaml:"memory_size" env:"CACHE_MEMORY_SIZE"`
+	// RedisRetryInterval is how long the in-memory cache is used after a Redis
+	// failure before Redis is tried again.
+	RedisRetryInterval time.Duration `yaml:"redis_retry_interval" env:"CACHE_REDIS_RETRY_INTERVAL"`
+	// JobsSearchTtl is how long a page of the public job search is cached.
+	JobsSearchTtl time.Duration `yaml:"jobs_search_ttl" env:"CACHE_JOBS_SEARCH_TTL"`
+	// JobsAggregatedTtl is how long the aggregated values of the job filters
+	// are fresh; for JobsAggregatedStaleTtl more they are still served while
+	// being refreshed in the background.
+	JobsAggregatedTtl      time.Duration `yaml:"jobs_aggregated_ttl" env:"CACHE_JOBS_AGGREGATED_TTL"`
+	JobsAggregatedStaleTtl time.Duration `yaml:"jobs_aggregated_stale_ttl" env:"CACHE_JOBS_AGGREGATED_STALE_TTL"`
+}
+
+type HttpCacheConfig struct {
+	// JobMaxAge, ProfileMaxAge and AdReferencesMaxAge are how long the
+	// browsers and the CDN may reuse the public job details, profiles and ad
+	// references before revalidating them; zero revalidates them on every use.
+	JobMaxAge          time.Duration `yaml:"job_max_age" env:"HTTP_CACHE_JOB_MAX_AGE"`
+	ProfileMaxAge      time.Duration `yaml:"profile_max_age" env:"HTTP_CACHE_PROFILE_MAX_AGE"`
+	AdReferencesMaxAge time.Duration `yaml:"ad_references_max_age" env:"HTTP_CACHE_AD_REFERENCES_MAX_AGE"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	// ApiKeys are the keys issued to the partners, each one limited on its
+	// own; the requests with any other key are limited by IP address.
+	ApiKeys []string `yaml:"api_keys" env:"RATE_LIMIT_API_KEYS"`
+}
+
+type LoginConfig struct {
+	// MaxFailedAttempts is the number of failed logins of an email within
+	// LockoutDuration, each one delayed more than the previous, before it is
+	// locked for LockoutDuration.
+	MaxFailedAttempts int           `yaml:"max_failed_attempts" env:"LOGIN_MAX_FAILED_ATTEMPTS"`
+	LockoutDuration   time.Duration `yaml:"lockout_duration" env:"LOGIN_LOCKOUT_DURATION"`
+	// IpMaxFailedAttempts is the number of failed logins from an IP address,
+	// whatever the account, before its logins are refused for IpWindow.
+	IpMaxFailedAttempts int           `yaml:"ip_max_failed_attempts" env:"LOGIN_IP_MAX_FAILED_ATTEMPTS"`
+	IpWindow            time.Duration `yaml:"ip_window" env:"LOGIN_IP_WINDOW"`
+}
+
+type ApiConfig struct {
+	// DeprecatedSince is the date, as 2006-01-02, since the routes at the
+	// root and the v1 routes replaced in v2 are deprecated.
+	DeprecatedSince string `yaml:"deprecated_since" env:"API_DEPRECATED_SINCE"`
+	// Sunset is the date, as 2006-01-02, when the deprecated routes will be
+	// removed; when empty no Sunset header is sent.
+	Sunset string `yaml:"sunset" env:"API_SUNSET"`
+}
+
+type JobsConfig struct {
+	// DefaultLifetime is how long a job stays open after it is created or
+	// renewed. ProviderLifetimes overrides it for some providers, as
+	// provider=duration items, e.g. linkedin=336h.
+	DefaultLifetime   time.Duration `yaml:"default_lifetime" env:"JOBS_DEFAULT_LIFETIME"`
+	ProviderLifetimes []string      `yaml:"provider_lifetimes" env:"JOBS_PROVIDER_LIFETIMES"`
+	// ExpiryReminder is how long before a job expires its creator is
+	// reminded by email to renew it.
+	ExpiryReminder time.Duration `yaml:"expiry_reminder" env:"JOBS_EXPIRY_REMINDER"`
+	// ExpiryCheckInterval is how often the expired jobs are closed and the
+	// reminders sent; zero disables the scheduler.
+	ExpiryCheckInterval time.Duration `yaml:"expiry_check_interval" env:"JOBS_EXPIRY_CHECK_INTERVAL"`
+}
+
+// Lifetime returns the lifetime of the jobs of the provider.
+func (jobs JobsConfig) Lifetime(provider string) time.Duration {
+
+	lifetimes, _ := jobs.providerLifetimes()
+
+	if lifetime, ok := lifetimes[strings.ToLower(strings.TrimSpace(provider))]; ok {
+		return lifetime
+	}
+
+	return jobs.DefaultLifetime
+}
+
+func (jobs JobsConfig) providerLifetimes() (map[string]time.Duration, error) {
+
+	lifetimes := map[string]time.Duration{}
+
+	for _, item := range jobs.ProviderLifetimes {
+
+		provider, value, found := strings.Cut(item, "=")
+
+		if !found {
+			return nil, fmt.Errorf("invalid provider lifetime %q", item)
+		}
+
+		lifetime, err := time.ParseDuration(strings.TrimSpace(value))
+
+		if err != nil || lifetime <= 0 {
+			return nil, fmt.Errorf("invalid provider lifetime %q", item)
+		}
+
+		lifetimes[strings.ToLower(strings.TrimSpace(provider))] = lifetime
+	}
+
+	return lifetimes, nil
+}
+
+// DeprecatedSinceDate and SunsetDate return the dates of the settings, or the
+// zero time when they are not set.
+func (api ApiConfig) DeprecatedSinceDate() time.Time {
+	date, _ := time.Parse(DATE_FORMAT, api.DeprecatedSince)
+	return date
+}
+
+func (api ApiConfig) SunsetDate() time.Time {
+	date, _ := time.Parse(DATE_FORMAT, api.Sunset)
+	return date
+}
+
+func Default() Config {
+	return Config{
+		Port:             "3001",
+		UploadsDir:       "./uploads",
+		ShutdownTimeout:  30 * time.Second,
+		MigrateOnStartup: true,
+		EnsureIndexes:    true,
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "vagasprajr-api",
+			SampleRatio: 1,
+		},
+		MongoDb: MongoDbConfig{
+			MaxPoolSize:            100,
+			MinPoolSize:            0,
+			MaxConnIdleTime:        5 * time.Minute,
+			ConnectTimeout:         10 * time.Second,
+			ServerSelectionTimeout: 10 * time.Second,
+		},
+		Cache: CacheConfig{
+			MemorySize:             10000,
+			RedisRetryInterval:     30 * time.Second,
+			JobsSearchTtl:          time.Minute,
+			JobsAggregatedTtl:      5 * time.Minute,
+			JobsAggregatedStaleTtl: time.Hour,
+		},
+		HttpCache: HttpCacheConfig{
+			JobMaxAge:          5 * time.Minute,
+			ProfileMaxAge:      time.Minute,
+			AdReferencesMaxAge: 10 * time.Minute,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+		},
+		Login: LoginConfig{
+			MaxFailedAttempts:   10,
+			LockoutDuration:     15 * time.Minute,
+			IpMaxFailedAttempts: 50,
+			IpWindow:            15 * time.Minute,
+		},
+		Jobs: JobsConfig{
+			DefaultLifetime:     30 * 24 * time.Hour,
+			ExpiryReminder:      3 * 24 * time.Hour,
+			ExpiryCheckInterval: time.Hour,
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the optional YAML file
+// (CONFIG_FILE, config.yaml by default), the optional .env file (ENV_FILE,
+// .env by default) and the environment variables, each one overriding the
+// previous. The .env file never overrides variables already set.
+func Load() (Config, error) {
+
+	config := Default()
+
+	yamlFile, yamlFileRequired := lookupFile("CONFIG_FILE", DEFAULT_YAML_FILE)
+
+	if err := loadYaml(&config, yamlFile, yamlFileRequired); err != nil {
+		return Config{}, err
+	}
+
+	envFile, envFileRequired := lookupFile("ENV_FILE", DEFAULT_ENV_FILE)
+
+	if err := loadEnvFile(envFile, envFileRequired); err != nil {
+		return Config{}, err
+	}
+
+	if err := loadEnv(&config, os.LookupEnv); err != nil {
+		return Config{}, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return config, nil
+}
+
+// Validate reports every missing or invalid setting at once.
+func (config Config) Validate() error {
+
+	problems := []string{}
+
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"MONGODB_URL", config.MongoDb.Url},
+		{"MONGODB_DATABASE", config.MongoDb.Database},
+		{"JWT_SECRET", config.Auth.JwtSecret},
+		{"BASE_UI_HOST", config.BaseUiHost},
+	}
+
+	for _, setting := range required {
+		if strings.TrimSpace(setting.value) == "" {
+			problems = append(problems, setting.name+" is required")
+		}
+	}
+
+	if config.MongoDb.Url != "" && !strings.HasPrefix(config.MongoDb.Url, "mongodb://") && !strings.HasPrefix(config.MongoDb.Url, "mongodb+srv://") {
+		problems = append(problems, "MONGODB_URL must start with mongodb:// or mongodb+srv://")
+	}
+
+	if config.MongoDb.MinPoolSize > config.MongoDb.MaxPoolSize && config.MongoDb.MaxPoolSize != 0 {
+		problems = append(problems, "MONGODB_MIN_POOL_SIZE must not be greater than MONGODB_MAX_POOL_SIZE")
+	}
+
+	var level slog.Level
+
+	if err := level.UnmarshalText([]byte(config.Log.Level)); err != nil {
+		problems = append(problems, "LOG_LEVEL must be debug, info, warn or error")
+	}
+
+	if config.Log.Format != "json" && config.Log.Format != "text" {
+		problems = append(problems, "LOG_FORMAT must be json or text")
+	}
+
+	if config.Tracing.Exporter != "otlp" && config.Tracing.Exporter != "stdout" && config.Tracing.Exporter != "none" {
+		problems = append(problems, "TRACING_EXPORTER must be otlp, stdout or none")
+	}
+
+	if config.Tracing.SampleRatio < 0 || config.Tracing.SampleRatio > 1 {
+		problems = append(problems, "TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
+
+	for _, proxy := range config.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			problems = append(problems, "TRUSTED_PROXIES must be IP addresses or CIDR ranges")
+			break
+		}
+	}
+
+	if config.Cache.MemorySize <= 0 {
+		problems = append(problems, "CACHE_MEMORY_SIZE must be greater than 0")
+	}
+
+	if config.HttpCache.JobMaxAge < 0 || config.HttpCache.ProfileMaxAge < 0 || config.HttpCache.AdReferencesMaxAge < 0 {
+		problems = append(problems, "HTTP_CACHE_JOB_MAX_AGE, HTTP_CACHE_PROFILE_MAX_AGE and HTTP_CACHE_AD_REFERENCES_MAX_AGE must not be negative")
+	}
+
+	if config.Login.MaxFailedAttempts <= 0 || config.Login.IpMaxFailedAttempts <= 0 {
+		problems = append(problems, "LOGIN_MAX_FAILED_ATTEMPTS and LOGIN_IP_MAX_FAILED_ATTEMPTS must be greater than 0")
+	}
+
+	if config.Jobs.DefaultLifetime <= 0 {
+		problems = append(problems, "JOBS_DEFAULT_LIFETIME must be greater than 0")
+	}
+
+	if _, err := config.Jobs.providerLifetimes(); err != nil {
+		problems = append(problems, "JOBS_PROVIDER_LIFETIMES must be provider=duration items with positive durations")
+	}
+
+	if config.Jobs.ExpiryReminder < 0 || config.Jobs.ExpiryCheckInterval < 0 {
+		problems = append(problems, "JOBS_EXPIRY_REMINDER and JOBS_EXPIRY_CHECK_INTERVAL must not be negative")
+	}
+
+	for _, setting := range []struct {
+		name  string
+		value string
+	}{
+		{"API_DEPRECATED_SINCE", config.Api.DeprecatedSince},
+		{"API_SUNSET", config.Api.Sunset},
+	} {
+		if _, err := time.Parse(DATE_FORMAT, setting.value); setting.value != "" && err != nil {
+			problems = append(problems, setting.name+" must be a date as "+DATE_FORMAT)
+		}
+	}
+
+	if config.Port == "" {
+		problems = append(problems, "PORT is required")
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration: " + strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// lookupFile returns the file named by the variable, which is then required,
+// or the default file, which is optional.
+func lookupFile(variable string, defaultFile string) (string, bool) {
+	if file, ok := os.LookupEnv(variable); ok && file != "" {
+		return file, true
+	}
+	return defaultFile, false
+}
+
+func loadYaml(config *Config, file string, required bool) error {
+
+	data, err := os.ReadFile(file)
+
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			return nil
+		}
+		return fmt.Errorf("invalid configuration: reading %s: %w", file, err)
+	}
+
+	if err = yaml.Unmarshal(data, config); err != nil {
+		return fmt.Errorf("invalid configuration: parsing %s: %w", file, err)
+	}
+
+	return nil
+}
+
+func loadEnvFile(file string, required bool) error {
+
+	if _, err := os.Stat(file); err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			return nil
+		}
+		return fmt.Errorf("invalid configuration: reading %s: %w", file, err)
+	}
+
+	if err := godotenv.Load(file); err != nil {
+		return fmt.Errorf("invalid configuration: parsing %s: %w", file, err)
+	}
+
+	return nil
+}
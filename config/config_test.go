@@ -0,0 +1,127 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setRequiredEnv(t *testing.T) {
+	t.Setenv("MONGODB_URL", "mongodb://localhost:27017")
+	t.Setenv("MONGODB_DATABASE", "vagasprajrdb")
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("BASE_UI_HOST", "http://localhost:3000")
+}
+
+// chdir moves the test to a directory without config.yaml or .env files.
+func chdir(t *testing.T, dir string) {
+	current, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(current) })
+}
+
+func writeFile(t *testing.T, name string, content string) string {
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoadWithoutFiles(t *testing.T) {
+	chdir(t, t.TempDir())
+	setRequiredEnv(t)
+	t.Setenv("MONGODB_MAX_CONN_IDLE_TIME", "1m")
+	t.Setenv("COOKIE_SECURE", "true")
+
+	settings, err := Load()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "3001", settings.Port)
+	assert.Equal(t, "vagasprajrdb", settings.MongoDb.Database)
+	assert.Equal(t, time.Minute, settings.MongoDb.MaxConnIdleTime)
+	assert.Equal(t, uint64(100), settings.MongoDb.MaxPoolSize)
+	assert.True(t, settings.Auth.CookieSecure)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	chdir(t, t.TempDir())
+	setRequiredEnv(t)
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", "port: \"4000\"\nversion: yaml\nmongodb:\n  max_conn_idle_time: 2m\n  max_pool_size: 50\n"))
+	t.Setenv("ENV_FILE", writeFile(t, ".env", "VERSION=dotenv\nMONGODB_MAX_POOL_SIZE=20\n"))
+	t.Setenv("MONGODB_MAX_POOL_SIZE", "10")
+	os.Unsetenv("VERSION")
+	t.Cleanup(func() { os.Unsetenv("VERSION") })
+
+	settings, err := Load()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "4000", settings.Port)
+	assert.Equal(t, "dotenv", settings.Version)
+	assert.Equal(t, 2*time.Minute, settings.MongoDb.MaxConnIdleTime)
+	assert.Equal(t, uint64(10), settings.MongoDb.MaxPoolSize)
+}
+
+func TestLoadFailsFast(t *testing.T) {
+	chdir(t, t.TempDir())
+	t.Setenv("MONGODB_URL", "localhost:27017")
+	t.Setenv("MONGODB_DATABASE", "")
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("BASE_UI_HOST", "")
+	t.Setenv("API_SUNSET", "30/06/2027")
+
+	_, err := Load()
+
+	assert.ErrorContains(t, err, "MONGODB_URL must start with mongodb://")
+	assert.ErrorContains(t, err, "MONGODB_DATABASE is required")
+	assert.ErrorContains(t, err, "JWT_SECRET is required")
+	assert.ErrorContains(t, err, "BASE_UI_HOST is required")
+	assert.ErrorContains(t, err, "API_SUNSET must be a date")
+}
+
+func TestLoadInvalidValue(t *testing.T) {
+	chdir(t, t.TempDir())
+	setRequiredEnv(t)
+	t.Setenv("MONGODB_CONNECT_TIMEOUT", "ten seconds")
+
+	_, err := Load()
+
+	assert.ErrorContains(t, err, "MONGODB_CONNECT_TIMEOUT")
+}
+
+func TestLoadMissingRequiredFile(t *testing.T) {
+	chdir(t, t.TempDir())
+	setRequiredEnv(t)
+	t.Setenv("ENV_FILE", filepath.Join(t.TempDir(), "missing.env"))
+
+	_, err := Load()
+
+	assert.ErrorContains(t, err, "missing.env")
+}
+
+func TestJobLifetimes(t *testing.T) {
+	chdir(t, t.TempDir())
+	setRequiredEnv(t)
+	t.Setenv("JOBS_PROVIDER_LIFETIMES", "LinkedIn=336h, gupy=240h")
+
+	settings, err := Load()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 336*time.Hour, settings.Jobs.Lifetime("linkedin"))
+	assert.Equal(t, 240*time.Hour, settings.Jobs.Lifetime("Gupy"))
+	assert.Equal(t, 720*time.Hour, settings.Jobs.Lifetime("vagasprajr"))
+
+	t.Setenv("JOBS_PROVIDER_LIFETIMES", "linkedin")
+
+	_, err = Load()
+
+	assert.ErrorContains(t, err, "JOBS_PROVIDER_LIFETIMES must be provider=duration items")
+}
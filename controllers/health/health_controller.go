@@ -0,0 +1,41 @@
+package health
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/health"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	READY_CHECK_TIMEOUT = 3 * time.Second
+)
+
+type Controller struct {
+	checks []health.Check
+}
+
+func NewController(checks []health.Check) *Controller {
+	return &Controller{checks: checks}
+}
+
+// Live reports that the process is up; it checks no dependency, so a
+// dependency outage does not restart the container.
+func (controller *Controller) Live(context *gin.Context) {
+	context.JSON(http.StatusOK, gin.H{"status": health.STATUS_UP})
+}
+
+// Ready reports the status and latency of every dependency, with 503 when a
+// critical one is down, so the traffic is routed elsewhere.
+func (controller *Controller) Ready(context *gin.Context) {
+
+	report := health.Run(context.Request.Context(), controller.checks, READY_CHECK_TIMEOUT)
+
+	if report.Status != health.STATUS_UP {
+		context.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	context.JSON(http.StatusOK, report)
+}
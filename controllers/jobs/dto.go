@@ -14,4 +14,10 @@ type JobDetailView struct {
 	Created_at 	time.Time 			`json:"created_at" bson:"created_at"`	
 	Code 		string 				`json:"code" bson:"code"`	
 	Description string              `json:"description" bson:"description"`
-}
\ No newline at end of file
+}
+
+// RenewedJobView is the new expiry date of a renewed job.
+type RenewedJobView struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -2,148 +2,257 @@ package jobs
 
 import (
 	"net/http"
+	"strings"
 
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/controllers"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares/httpcache"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/jobs"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/emails"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/validation"
 
 	"github.com/gin-gonic/gin"
 )
 
-func DeleteJob(context *gin.Context) {
+var errJobNotFound = apperrors.NotFound("Vaga não encontrada")
+
+type Controller struct {
+	settings       config.Config
+	jobRepository  jobs.JobRepository
+	userRepository users.UserRepository
+	emailSender    emails.Sender
+}
+
+func NewController(settings config.Config, jobRepository jobs.JobRepository, userRepository users.UserRepository, emailSender emails.Sender) *Controller {
+	return &Controller{settings: settings, jobRepository: jobRepository, userRepository: userRepository, emailSender: emailSender}
+}
+
+func (controller *Controller) DeleteJob(context *gin.Context) {
 	userRole := context.MustGet("userRole").(string)
 
 	if userRole != "admin" {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		context.Error(apperrors.Forbidden("Usuário não autorizado"))
 		return
 	}
 
 	code := context.Param("code")
 
 	if code == "" {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "code is required"})
+		context.Error(apperrors.BadRequest("Código da vaga não informado"))
 		return
 	}
 
-	err := jobs.DeleteJob(code)
+	err := controller.jobRepository.DeleteJob(context.Request.Context(), code)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	context.JSON(http.StatusOK, gin.H{"message": "Job deleted successfully"})
 }
 
-func UpdateJob(context *gin.Context) {
+func (controller *Controller) UpdateJob(context *gin.Context) {
 	userRole := context.MustGet("userRole").(string)
 
 	if userRole != "admin" {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		context.Error(apperrors.Forbidden("Usuário não autorizado"))
 		return
 	}
 	
 	code := context.Param("code")	
 
 	if code == "" {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "code is required"})
+		context.Error(apperrors.BadRequest("Código da vaga não informado"))
 		return
 	}
 
 	var body jobs.UpdateJobBody
-	context.BindJSON(&body)
+	if err := validation.Bind(context, &body); err != nil {
+		context.Error(err)
+		return
+	}
 
-	job, err := jobs.GetJob(code)
+	job, err := controller.jobRepository.GetJob(context.Request.Context(), code)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
-	job.IsApproved = body.IsApproved
-	job.IsClosed = body.IsClosed
+	if job.Code == "" {
+		context.Error(errJobNotFound)
+		return
+	}
 
-	result, err := jobs.UpdateJob(job)
+	// The flags are mapped to the status they stand for
+	status := jobs.JOB_STATUS_PENDING_REVIEW
+
+	if body.IsClosed {
+		status = jobs.JOB_STATUS_CLOSED
+	} else if body.IsApproved {
+		status = jobs.JOB_STATUS_APPROVED
+	}
+
+	if status == job.CurrentStatus() {
+		context.JSON(http.StatusOK, job)
+		return
+	}
+
+	userInfo, err := currentUser(context)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
+		return
+	}
+
+	result, err := controller.transition(context, job, status, "", userInfo.Id)
+
+	if err != nil {
+		context.Error(err)
 		return
 	}
 
 	context.JSON(http.StatusOK, result)
 }
 
-func GetJobAsAdmin(context *gin.Context) {
+func (controller *Controller) GetJobAsAdmin(context *gin.Context) {
 	userRole := context.MustGet("userRole").(string)
 
 	if userRole != "admin" {
-		context.Writer.WriteHeaderNow()
-		context.Status(http.StatusUnauthorized)  
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		context.Error(apperrors.Forbidden("Usuário não autorizado"))
 		return
 	}
 
 	code := context.Param("code")
 
-	result, err := jobs.GetJob(code)
+	result, err := controller.jobRepository.GetJob(context.Request.Context(), code)
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	if result.Code == "" {
+		context.Error(errJobNotFound)
+		return
+	}
+
+	context.JSON(http.StatusOK, result)
+}
+
+func (controller *Controller) GetJobsAsAdmin(context *gin.Context) {
+
+	result, err := controller.getJobsAsAdmin(context)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	context.JSON(http.StatusOK, result)
+
+}
+
+// GetJobsAsAdminV2 answers GetJobsAsAdmin in the envelope of the v2 API.
+func (controller *Controller) GetJobsAsAdminV2(context *gin.Context) {
+
+	result, err := controller.getJobsAsAdmin(context)
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	context.JSON(http.StatusOK, result.ToPage())
 }
 
-func GetJobsAsAdmin(context *gin.Context) {
+func (controller *Controller) getJobsAsAdmin(context *gin.Context) (jobs.JobsPaginatedResult, error) {
 	userRole := context.MustGet("userRole").(string)
 
 	if userRole != "admin" {
-		context.Writer.WriteHeaderNow()
-		context.Status(http.StatusUnauthorized)  
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
+		return jobs.JobsPaginatedResult{}, apperrors.Forbidden("Usuário não autorizado")
 	}
 
 	var body commons.FilterRequest
-	context.BindJSON(&body)
+	if err := validation.Bind(context, &body); err != nil {
+		return jobs.JobsPaginatedResult{}, err
+	}
+
+	return controller.jobRepository.GetJobsAsAdmin(context.Request.Context(), body)
+}
 
-	result, err := jobs.GetJobsAsAdmin(body)
+func (controller *Controller) GetJobs(context *gin.Context) {
+
+	result, err := controller.getJobs(context)
 
 	if err != nil {
-		context.Writer.WriteHeaderNow()
-		context.Status(http.StatusInternalServerError)  
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	context.JSON(http.StatusOK, result)
-
 }
 
-func GetJobs(context *gin.Context) {
-	var body jobs.JobFilter
-	context.BindJSON(&body)
+// GetJobsV2 answers GetJobs in the envelope of the v2 API.
+func (controller *Controller) GetJobsV2(context *gin.Context) {
 
-	result, err := jobs.GetJobs(body)
+	result, err := controller.getJobs(context)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
-	context.JSON(http.StatusOK, result)
+	context.JSON(http.StatusOK, result.ToPage())
 }
 
+func (controller *Controller) getJobs(context *gin.Context) (jobs.PaginatedResult, error) {
+	var body jobs.JobFilter
+	if err := validation.Bind(context, &body); err != nil {
+		return jobs.PaginatedResult{}, err
+	}
 
-func GetJob(context *gin.Context) {
+	// The jobs asked by id are not searched, so they have no relevance
+	if len(body.Ids) > 0 && strings.TrimSpace(body.Search) != "" {
+		return jobs.PaginatedResult{}, apperrors.InvalidField("search", "A busca por texto não pode ser combinada com os ids")
+	}
+
+	// A text search is ordered by relevance unless another order is asked
+	if strings.TrimSpace(body.Search) == "" {
+		if body.Sort == commons.SORT_RELEVANCE {
+			return jobs.PaginatedResult{}, apperrors.InvalidField("sort", "A ordenação por relevância exige o texto da busca")
+		}
+	} else if body.Sort == "" {
+		body.Sort = commons.SORT_RELEVANCE
+	}
+
+	return controller.jobRepository.GetJobs(context.Request.Context(), body)
+}
+
+
+func (controller *Controller) GetJob(context *gin.Context) {
 	code := context.Param("code")
 
-	result, err := jobs.GetJob(code)
+	result, err := controller.jobRepository.GetJob(context.Request.Context(), code)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
+		return
+	}
+
+	// Only the approved jobs are public
+	if result.Code == "" || result.CurrentStatus() != jobs.JOB_STATUS_APPROVED {
+		context.Error(errJobNotFound)
+		return
+	}
+
+	if httpcache.NotModified(context, httpcache.NewVersion(httpcache.NewStamp(result.Id, result.LastUpdate, result.CreatedAt))) {
 		return
 	}
 
@@ -162,59 +271,219 @@ func GetJob(context *gin.Context) {
 	context.JSON(http.StatusOK, jobView)
 }
 
-func CreateJob(context *gin.Context) {
+func (controller *Controller) CreateJob(context *gin.Context) {
 
 	currentUser, context_error := context.Get(middlewares.USER_TOKEN_INFO)	
 
 	if !context_error {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
 	if (currentUser == nil) {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
 	userInfo := currentUser.(users.UserTokenInfo)
 
 	if userInfo.Id.IsZero() {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
-	user, err := users.GetUserById(userInfo.Id)
+	user, err := controller.userRepository.GetUserById(context.Request.Context(), userInfo.Id)
 
 	if err != nil {	
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	var body jobs.CreateJobBody
-	context.BindJSON(&body)
+	if err := validation.Bind(context, &body); err != nil {
+		context.Error(err)
+		return
+	}
 
 	body.Creator = user.Id
 
-	result, err := jobs.CreateJob(body)
+	result, err := controller.jobRepository.CreateJob(context.Request.Context(), body)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
+	metrics.JobsCreated.Inc()
+
 	context.JSON(http.StatusOK, result)
 }
 
-func GetAggregatedJobsValues(context *gin.Context) {
-	var body jobs.JobFilter
-	context.BindJSON(&body)
+// GetAggregatedJobsValues answers the values of the filters matching the
+// words of the title, without their counts.
+func (controller *Controller) GetAggregatedJobsValues(context *gin.Context) {
+
+	result, err := controller.getAggregatedJobsValues(context, func(body jobs.JobFilter) jobs.JobFilter {
+		return jobs.JobFilter{Title: body.Title}
+	})
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	context.JSON(http.StatusOK, result.ToOptions())
+}
+
+// GetAggregatedJobsValuesV2 answers the values of the filters with the number
+// of jobs matching the whole filter for each one.
+func (controller *Controller) GetAggregatedJobsValuesV2(context *gin.Context) {
 
-	result, err := jobs.GetAggregatedJobsValues(body)
+	result, err := controller.getAggregatedJobsValues(context, func(body jobs.JobFilter) jobs.JobFilter {
+		return body
+	})
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	context.JSON(http.StatusOK, result)
-}
\ No newline at end of file
+}
+
+func (controller *Controller) getAggregatedJobsValues(context *gin.Context, filter func(jobs.JobFilter) jobs.JobFilter) (jobs.JobFacets, error) {
+	var body jobs.JobFilter
+	if err := validation.Bind(context, &body); err != nil {
+		return jobs.JobFacets{}, err
+	}
+
+	return controller.jobRepository.GetAggregatedJobsValues(context.Request.Context(), filter(body))
+}
+
+// RenewJob moves the expiry date of the approved job to a whole lifetime from
+// now. The creator of the job or an admin can renew it; an expired job is
+// approved again, which gives it the new date in the same update.
+func (controller *Controller) RenewJob(context *gin.Context) {
+
+	userInfo, err := currentUser(context)
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	job, err := controller.jobRepository.GetJob(context.Request.Context(), context.Param("code"))
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	if job.Code == "" {
+		context.Error(errJobNotFound)
+		return
+	}
+
+	if err = controller.checkCanManage(context, job, userInfo); err != nil {
+		context.Error(err)
+		return
+	}
+
+	switch job.CurrentStatus() {
+	case jobs.JOB_STATUS_EXPIRED:
+
+		if job, err = controller.transition(context, job, jobs.JOB_STATUS_APPROVED, "", userInfo.Id); err != nil {
+			context.Error(err)
+			return
+		}
+
+	case jobs.JOB_STATUS_APPROVED:
+
+		expiresAt := commons.GetBrasiliaTime().Add(controller.settings.Jobs.Lifetime(job.Provider))
+
+		renewed, err := controller.jobRepository.RenewJob(context.Request.Context(), job.Id, expiresAt, userInfo.Id)
+
+		if err != nil {
+			context.Error(err)
+			return
+		}
+
+		if !renewed {
+			context.Error(apperrors.Conflict("A vaga foi alterada enquanto isso; tente novamente"))
+			return
+		}
+
+		job.ExpiresAt = expiresAt
+
+	case jobs.JOB_STATUS_CLOSED:
+		context.Error(apperrors.Conflict("A vaga foi fechada e não pode ser renovada"))
+		return
+
+	default:
+		context.Error(apperrors.Conflict("A vaga ainda não foi aprovada e não pode ser renovada"))
+		return
+	}
+
+	context.JSON(http.StatusOK, RenewedJobView{Code: job.Code, ExpiresAt: job.ExpiresAt})
+}
+
+// currentUser returns the user of the token set by the authentication
+// middleware.
+func currentUser(context *gin.Context) (users.UserTokenInfo, error) {
+
+	currentUser, ok := context.Get(middlewares.USER_TOKEN_INFO)
+
+	if !ok || currentUser == nil {
+		return users.UserTokenInfo{}, apperrors.Unauthorized("Usuário não autenticado")
+	}
+
+	userInfo := currentUser.(users.UserTokenInfo)
+
+	if userInfo.Id.IsZero() {
+		return users.UserTokenInfo{}, apperrors.Unauthorized("Usuário não autenticado")
+	}
+
+	return userInfo, nil
+}
+
+// checkCanManage lets only the creator of the job or an admin change it.
+func (controller *Controller) checkCanManage(context *gin.Context, job jobs.Job, userInfo users.UserTokenInfo) error {
+
+	if job.Creator == userInfo.Id {
+		return nil
+	}
+
+	return controller.checkIsAdmin(context, userInfo)
+}
+
+func (controller *Controller) checkIsAdmin(context *gin.Context, userInfo users.UserTokenInfo) error {
+
+	isAdmin, err := controller.isAdmin(context, userInfo)
+
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		return apperrors.Forbidden("Usuário não autorizado")
+	}
+
+	return nil
+}
+
+func (controller *Controller) isAdmin(context *gin.Context, userInfo users.UserTokenInfo) (bool, error) {
+
+	roles, err := controller.userRepository.GetUserRoles(context.Request.Context(), userInfo.Id)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, role := range roles {
+		if role == controllers.ADMIN {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,135 @@
+package jobs
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/jobs"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/emails"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/validation"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChangeJobStatus moves the job along the moderation workflow. Only the
+// admins approve or reject a job, giving the reason of a rejection; the
+// creator can also send a draft or a rejected job to review and close it.
+func (controller *Controller) ChangeJobStatus(context *gin.Context) {
+
+	userInfo, err := currentUser(context)
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	var body jobs.ChangeJobStatusBody
+	if err := validation.Bind(context, &body); err != nil {
+		context.Error(err)
+		return
+	}
+
+	body.Reason = strings.TrimSpace(body.Reason)
+
+	if body.Status == jobs.JOB_STATUS_REJECTED && body.Reason == "" {
+		context.Error(apperrors.InvalidField("reason", "Informe o motivo da recusa"))
+		return
+	}
+
+	job, err := controller.jobRepository.GetJob(context.Request.Context(), context.Param("code"))
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	if job.Code == "" {
+		context.Error(errJobNotFound)
+		return
+	}
+
+	if body.Status == jobs.JOB_STATUS_APPROVED || body.Status == jobs.JOB_STATUS_REJECTED {
+		err = controller.checkIsAdmin(context, userInfo)
+	} else {
+		err = controller.checkCanManage(context, job, userInfo)
+	}
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	result, err := controller.transition(context, job, body.Status, body.Reason, userInfo.Id)
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	context.JSON(http.StatusOK, result)
+}
+
+// transition moves the job to the status, if the workflow allows it, and
+// tells the creator when the review approved or rejected the job. An approved
+// job expires a whole lifetime of its provider later.
+func (controller *Controller) transition(context *gin.Context, job jobs.Job, status string, reason string, by primitive.ObjectID) (jobs.Job, error) {
+
+	from := job.CurrentStatus()
+
+	if !jobs.CanTransition(from, status) {
+		return jobs.Job{}, apperrors.Conflict("A vaga não pode passar de " + from + " para " + status)
+	}
+
+	transition := jobs.JobTransition{From: from, To: status, Reason: reason, By: by, At: commons.GetBrasiliaTime()}
+
+	// The lifetime of the job starts when it is approved
+	if status == jobs.JOB_STATUS_APPROVED {
+		transition.ExpiresAt = transition.At.Add(controller.settings.Jobs.Lifetime(job.Provider))
+	}
+
+	changed, err := controller.jobRepository.TransitionJob(context.Request.Context(), job.Id, transition)
+
+	if err != nil {
+		return jobs.Job{}, err
+	}
+
+	if !changed {
+		return jobs.Job{}, apperrors.Conflict("A vaga foi alterada enquanto isso; tente novamente")
+	}
+
+	if from == jobs.JOB_STATUS_PENDING_REVIEW {
+		controller.notifyCreator(context, job, transition)
+	}
+
+	return controller.jobRepository.GetJob(context.Request.Context(), job.Code)
+}
+
+func (controller *Controller) notifyCreator(context *gin.Context, job jobs.Job, transition jobs.JobTransition) {
+
+	var subject, body string
+
+	switch transition.To {
+	case jobs.JOB_STATUS_APPROVED:
+		subject, body = "Vaga aprovada", emails.GetJobApprovedEmail(controller.settings.BaseUiHost, job.Title, job.Code)
+	case jobs.JOB_STATUS_REJECTED:
+		subject, body = "Vaga não aprovada", emails.GetJobRejectedEmail(job.Title, transition.Reason)
+	default:
+		return
+	}
+
+	if job.Creator.IsZero() {
+		return
+	}
+
+	creator, err := controller.userRepository.GetUserById(context.Request.Context(), job.Creator)
+
+	if err != nil || creator.Email == "" {
+		slog.WarnContext(context.Request.Context(), "Creator of the job not found to be notified", "job", job.Code, "error", err)
+		return
+	}
+
+	controller.emailSender.Send(context.Request.Context(), "", []string{creator.Email}, subject, body)
+}
@@ -0,0 +1,121 @@
+package jobs
+
+import (
+	"net/http"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/jobs"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// GetMyJobs lists the jobs posted by the current user, in any status, with
+// their clicks, in the envelope of the v2 API.
+func (controller *Controller) GetMyJobs(context *gin.Context) {
+
+	result, err := controller.getMyJobs(context)
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	context.JSON(http.StatusOK, result.ToPage())
+}
+
+func (controller *Controller) getMyJobs(context *gin.Context) (jobs.JobsPaginatedResult, error) {
+
+	userInfo, err := currentUser(context)
+
+	if err != nil {
+		return jobs.JobsPaginatedResult{}, err
+	}
+
+	var body commons.FilterRequest
+	if err := validation.Bind(context, &body); err != nil {
+		return jobs.JobsPaginatedResult{}, err
+	}
+
+	return controller.jobRepository.GetJobsByCreator(context.Request.Context(), userInfo.Id, body)
+}
+
+// EditJob changes the details of a job. The creator of the job or an admin
+// can edit it until it is closed. An approved job edited by anyone but an
+// admin goes back to review in the same update, so the changes are not public
+// before they are approved; otherwise the status of the job is kept.
+func (controller *Controller) EditJob(context *gin.Context) {
+
+	userInfo, err := currentUser(context)
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	var body jobs.EditJobBody
+	if err := validation.Bind(context, &body); err != nil {
+		context.Error(err)
+		return
+	}
+
+	job, err := controller.jobRepository.GetJob(context.Request.Context(), context.Param("code"))
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	if job.Code == "" {
+		context.Error(errJobNotFound)
+		return
+	}
+
+	if err = controller.checkCanManage(context, job, userInfo); err != nil {
+		context.Error(err)
+		return
+	}
+
+	// The edit applies only while the job is in the status read here
+	transition := jobs.JobTransition{From: job.CurrentStatus()}
+
+	if transition.From == jobs.JOB_STATUS_CLOSED {
+		context.Error(apperrors.Conflict("A vaga foi fechada e não pode ser alterada"))
+		return
+	}
+
+	if transition.From == jobs.JOB_STATUS_APPROVED {
+
+		isAdmin, err := controller.isAdmin(context, userInfo)
+
+		if err != nil {
+			context.Error(err)
+			return
+		}
+
+		if !isAdmin {
+			transition = jobs.JobTransition{From: transition.From, To: jobs.JOB_STATUS_PENDING_REVIEW, By: userInfo.Id, At: commons.GetBrasiliaTime()}
+		}
+	}
+
+	edited, err := controller.jobRepository.EditJob(context.Request.Context(), job.Id, body, userInfo.Id, transition)
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	if !edited {
+		context.Error(apperrors.Conflict("A vaga foi alterada enquanto isso; tente novamente"))
+		return
+	}
+
+	result, err := controller.jobRepository.GetJob(context.Request.Context(), job.Code)
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	context.JSON(http.StatusOK, result)
+}
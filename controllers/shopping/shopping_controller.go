@@ -3,129 +3,165 @@ package shopping
 import (
 	"net/http"
 
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares/httpcache"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/shopping"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/validation"
 	"github.com/gin-gonic/gin"
 )
 
-func GetAdReferences (context *gin.Context) {
+type Controller struct {
+	adReferenceRepository shopping.AdReferenceRepository
+}
+
+func NewController(adReferenceRepository shopping.AdReferenceRepository) *Controller {
+	return &Controller{adReferenceRepository: adReferenceRepository}
+}
 
-	adReferences, err := shopping.GetAdReferences()
+func (controller *Controller) GetAdReferences(context *gin.Context) {
+
+	adReferences, err := controller.adReferenceRepository.GetAdReferences(context.Request.Context())
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
+		return
+	}
+
+	stamps := make([]httpcache.Stamp, 0, len(adReferences))
+
+	for _, adReference := range adReferences {
+		stamps = append(stamps, httpcache.NewStamp(adReference.Id.Hex(), adReference.LastUpdate, adReference.CreatedAt))
+	}
+
+	if httpcache.NotModified(context, httpcache.NewVersion(stamps...)) {
 		return
 	}
 
 	context.JSON(http.StatusOK, adReferences)	
 }
 
-func GetFilteredAdReferences (context *gin.Context) {
+func (controller *Controller) GetFilteredAdReferences(context *gin.Context) {
 
-	userRole := context.MustGet("userRole").(string)
+	adReferences, err := controller.getFilteredAdReferences(context)
 
-	if userRole != "admin" {
-		context.Writer.WriteHeaderNow()
-		context.Status(http.StatusUnauthorized)  
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	if err != nil {
+		context.Error(err)
 		return
 	}
-	
-	var filter commons.FilterRequest
-	context.BindJSON(&filter)
 
-	adReferences, err := shopping.GetFilteredAdReferences(filter)
+	context.JSON(http.StatusOK, adReferences)	
+}
+
+// GetFilteredAdReferencesV2 answers GetFilteredAdReferences in the envelope of
+// the v2 API.
+func (controller *Controller) GetFilteredAdReferencesV2(context *gin.Context) {
+
+	adReferences, err := controller.getFilteredAdReferences(context)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
-	context.JSON(http.StatusOK, adReferences)	
+	context.JSON(http.StatusOK, adReferences.ToPage())
+}
+
+func (controller *Controller) getFilteredAdReferences(context *gin.Context) (shopping.AdReferencesPaginatedResult, error) {
+
+	userRole := context.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		return shopping.AdReferencesPaginatedResult{}, apperrors.Forbidden("Usuário não autorizado")
+	}
+	
+	var filter commons.FilterRequest
+	if err := validation.Bind(context, &filter); err != nil {
+		return shopping.AdReferencesPaginatedResult{}, err
+	}
+
+	return controller.adReferenceRepository.GetFilteredAdReferences(context.Request.Context(), filter)
 }
 
-func GetAdReference (context *gin.Context) {
+func (controller *Controller) GetAdReference(context *gin.Context) {
 	
 	id := context.Param("id")
 
-	adReference, err := shopping.GetAdReference(id)
+	adReference, err := controller.adReferenceRepository.GetAdReference(context.Request.Context(), id)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	context.JSON(http.StatusOK, adReference)	
 }
 
-func CreateAdReference (context *gin.Context) {
+func (controller *Controller) CreateAdReference(context *gin.Context) {
 	
 	userRole := context.MustGet("userRole").(string)
 
 	if userRole != "admin" {
-		context.Writer.WriteHeaderNow()
-		context.Status(http.StatusUnauthorized)  
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		context.Error(apperrors.Forbidden("Usuário não autorizado"))
 		return
 	}
 	
 	var adReference shopping.AdReference
-    if err := context.BindJSON(&adReference); err != nil {
-        context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    if err := validation.Bind(context, &adReference); err != nil {
+        context.Error(err)
         return
     }
 
-	err := shopping.CreateAdReference(adReference)
+	err := controller.adReferenceRepository.CreateAdReference(context.Request.Context(), adReference)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	context.JSON(http.StatusCreated, gin.H{"message": "Ad reference created successfully"})	
 }
 
-func DeleteAdReference (context *gin.Context) {
+func (controller *Controller) DeleteAdReference(context *gin.Context) {
 	
 	userRole := context.MustGet("userRole").(string)
 
 	if userRole != "admin" {
-		context.Writer.WriteHeaderNow()
-		context.Status(http.StatusUnauthorized)  
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		context.Error(apperrors.Forbidden("Usuário não autorizado"))
 		return
 	}
 	
 	id := context.Param("id")
 
-	err := shopping.DeleteAdReference(id)
+	err := controller.adReferenceRepository.DeleteAdReference(context.Request.Context(), id)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	context.JSON(http.StatusOK, gin.H{"message": "Ad reference deleted successfully"})	
 }
 
-func UpdateAdReference (context *gin.Context) {
+func (controller *Controller) UpdateAdReference(context *gin.Context) {
 	
 	userRole := context.MustGet("userRole").(string)
 
 	if userRole != "admin" {
-		context.Writer.WriteHeaderNow()
-		context.Status(http.StatusUnauthorized)  
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		context.Error(apperrors.Forbidden("Usuário não autorizado"))
 		return
 	}
 	
 	var adReference shopping.AdReference
-	context.BindJSON(&adReference)
+	if err := validation.Bind(context, &adReference); err != nil {
+		context.Error(err)
+		return
+	}
 
-	err := shopping.UpdateAdReference(adReference)
+	err := controller.adReferenceRepository.UpdateAdReference(context.Request.Context(), adReference)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
@@ -1,36 +1,48 @@
 package shorturls
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
 
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/jobs"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/promotions"
 	"github.com/gin-gonic/gin"
 )
 
-func GetOriginalURL(context *gin.Context) {
+type Controller struct {
+	settings      config.Config
+	jobRepository jobs.JobRepository
+	adRepository  promotions.AdRepository
+}
+
+func NewController(settings config.Config, jobRepository jobs.JobRepository, adRepository promotions.AdRepository) *Controller {
+	return &Controller{settings: settings, jobRepository: jobRepository, adRepository: adRepository}
+}
+
+func (controller *Controller) GetOriginalURL(context *gin.Context) {
 
 	code := context.Param("code")
 
 	if (code == "") {
-		context.JSON(400, gin.H{"error": "Code not found"})
+		context.Error(apperrors.BadRequest("Código não informado"))
 		return
 	}
 
-	shortUrl := os.Getenv("BASE_UI_HOST") + `/go/` + code
-	originalUrl, err := jobs.GetOriginalURL(shortUrl)
+	shortUrl := controller.settings.BaseUiHost + `/go/` + code
+	originalUrl, err := controller.jobRepository.GetOriginalURL(context.Request.Context(), shortUrl)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": "Problem getting the original URL"})
+		context.Error(err)
 		return
 	}
 
-	err = jobs.UpdateJobClicks(shortUrl)
+	err = controller.jobRepository.UpdateJobClicks(context.Request.Context(), shortUrl)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": "Problem updating the advertisement clicks"})
+		context.Error(err)
 		return
 	}
 
@@ -38,66 +50,72 @@ func GetOriginalURL(context *gin.Context) {
 	response := make(map[string]string)
 	response["originalUrl"] = originalUrl
 
-	log.Print("Fetched original URL: ", originalUrl)
+	slog.DebugContext(context.Request.Context(), "Fetched the original URL", "url", originalUrl)
+
+	metrics.ShortLinkRedirects.WithLabelValues("go").Inc()
 	
 	context.JSON(200, response)	
 }
 
-func RedirectToOriginalAdURL(context *gin.Context) {
+func (controller *Controller) RedirectToOriginalAdURL(context *gin.Context) {
 	code := context.Param("code")
 
 	if (code == "") {
-		context.JSON(400, gin.H{"error": "Code not found"})
+		context.Error(apperrors.BadRequest("Código não informado"))
 		return
 	}
 
-	shortUrl := os.Getenv("BASE_UI_HOST") + `/r/` + code	
-	originalUrl, err := promotions.GetAdOriginalURL(shortUrl)
+	shortUrl := controller.settings.BaseUiHost + `/r/` + code	
+	originalUrl, err := controller.adRepository.GetAdOriginalURL(context.Request.Context(), shortUrl)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": "Problem getting the original URL"})
+		context.Error(err)
 		return
 	}
 
-	err = promotions.UpdateAdvertisementClicks(shortUrl)
+	err = controller.adRepository.UpdateAdvertisementClicks(context.Request.Context(), shortUrl)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": "Problem updating the advertisement clicks"})
+		context.Error(err)
 		return
 	}
 
-	log.Print("redirecting to: ", originalUrl)
+	slog.DebugContext(context.Request.Context(), "Redirecting", "url", originalUrl)
 		
+	metrics.ShortLinkRedirects.WithLabelValues("r").Inc()
+
 	context.Redirect(http.StatusTemporaryRedirect, originalUrl)
 }
 
-func RedirectToOriginalJobUrl(context *gin.Context) {
+func (controller *Controller) RedirectToOriginalJobUrl(context *gin.Context) {
 	code := context.Param("code")
 
 	if (code == "") {
-		context.JSON(400, gin.H{"error": "Code not found"})
+		context.Error(apperrors.BadRequest("Código não informado"))
 		return
 	}
 
-	shortUrl := os.Getenv("BASE_UI_HOST") + `/go/` + code	
-	log.Print("looking for shortUrl: ", shortUrl)
+	shortUrl := controller.settings.BaseUiHost + `/go/` + code	
+	slog.DebugContext(context.Request.Context(), "Looking for the short URL", "short_url", shortUrl)
 
-	originalUrl, err := jobs.GetOriginalURL(shortUrl)
+	originalUrl, err := controller.jobRepository.GetOriginalURL(context.Request.Context(), shortUrl)
 
 	if err != nil {
-		log.Println("Error getting the original URL: ", err)
-		context.JSON(http.StatusInternalServerError, gin.H{"error": "Problem getting the original URL"})		
+		slog.WarnContext(context.Request.Context(), "Error getting the original URL", "short_url", shortUrl, "error", err)
+		context.Error(err)		
 		return
 	}
 
-	err = jobs.UpdateJobClicks(shortUrl)
+	err = controller.jobRepository.UpdateJobClicks(context.Request.Context(), shortUrl)
 	
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": "Problem updating the advertisement clicks"})
+		context.Error(err)
 		return
 	}
 
-	log.Print("redirecting to: ", originalUrl)
+	slog.DebugContext(context.Request.Context(), "Redirecting", "url", originalUrl)
+
+	metrics.ShortLinkRedirects.WithLabelValues("j").Inc()
 
 	context.Redirect(http.StatusTemporaryRedirect, originalUrl)
 
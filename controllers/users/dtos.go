@@ -6,33 +6,33 @@ import (
 )
 
 type AuthorizeRequest struct {
-	Roles []string `json:"roles"`
+	Roles []string `json:"roles" binding:"max=10"`
 }
 
 type UpdateUserNameRequest struct {	
-	UserName 	string `json:"user_name"`	
+	UserName 	string `json:"user_name" binding:"required,username"`	
 }
 
 type UpdateUserBookmarkRequest struct {
-	BookmarkedJobs []string `json:"bookmarked_jobs"`
+	BookmarkedJobs []string `json:"bookmarked_jobs" binding:"max=500"`
 }
 
 type UpdateUserRequest struct {
 	Id                   string                      `json:"id"`	
-	FirstName            string                      `json:"first_name"`
-	LastName             string                      `json:"last_name"`
-	City                 string                      `json:"city"`
-	State                string                      `json:"state"`
-	AboutMe              string               		 `json:"about_me"`
-	Links                []users.UserLink            `json:"links"`
-	TechExperiences      []users.UserTechExperience  `json:"tech_experiences"`
-	Experiences          []users.UserExperice        `json:"experiences"`
-	IdiomsInfo		 	 []users.UserIdiomInfo       `json:"idioms_info"`
-	Certifications	   	 []users.UserCertification   `json:"certifications"`
-	Educations		   	 []users.UserEducation       `json:"educations"`
+	FirstName            string                      `json:"first_name" binding:"required,max=100"`
+	LastName             string                      `json:"last_name" binding:"max=100"`
+	City                 string                      `json:"city" binding:"max=100"`
+	State                string                      `json:"state" binding:"max=50"`
+	AboutMe              string               		 `json:"about_me" binding:"max=5000"`
+	Links                []users.UserLink            `json:"links" binding:"max=20,dive"`
+	TechExperiences      []users.UserTechExperience  `json:"tech_experiences" binding:"max=100,dive"`
+	Experiences          []users.UserExperice        `json:"experiences" binding:"max=50,dive"`
+	IdiomsInfo		 	 []users.UserIdiomInfo       `json:"idioms_info" binding:"max=20,dive"`
+	Certifications	   	 []users.UserCertification   `json:"certifications" binding:"max=50,dive"`
+	Educations		   	 []users.UserEducation       `json:"educations" binding:"max=20,dive"`
 	IsPublic 		   	 bool                        `json:"is_public"`
-	ProfileImageUrl		 string					 	 `json:"profile_image_url"`
-	OAuthImageURL        string              		 `json:"oauth_image_url"`	
+	ProfileImageUrl		 string					 	 `json:"profile_image_url" binding:"max=2048"`
+	OAuthImageURL        string              		 `json:"oauth_image_url" binding:"max=2048"`	
 }
 
 type UserProfileResponse struct {
@@ -64,18 +64,24 @@ type UserProfileResponse struct {
 }
 
 type ResetPasswordRequest struct {
-	Email string `json:"email"`
+	Email string `json:"email" binding:"required,email,max=254"`
 }
 
 type VerifyResestTokenRequest struct {
-	Token string `json:"token"`
+	Token string `json:"token" binding:"required,max=200"`
 }
 
 type ResetPasswordRequestBody struct {
-	Token    string `json:"token"`
-	Password string `json:"password"`
+	Token    string `json:"token" binding:"required,max=200"`
+	Password string `json:"password" binding:"required,password"`
 }
 
 type GravatarRequest struct {
-	Email string `json:"email"`
-}
\ No newline at end of file
+	Email string `json:"email" binding:"required,email,max=254"`
+}
+type SignUpRequest struct {
+	FirstName string `json:"first_name" binding:"required,max=100"`
+	LastName  string `json:"last_name" binding:"max=100"`
+	Email     string `json:"email" binding:"required,email,max=254"`
+	Password  string `json:"password" binding:"required,password"`
+}
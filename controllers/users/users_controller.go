@@ -1,128 +1,271 @@
 package users
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
 	"net/http"
-	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares/httpcache"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/gravatar"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users/tokens"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/services/emails"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/lockout"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/validation"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func SignUp (context *gin.Context) {
+type Controller struct {
+	settings       config.Config
+	userRepository users.UserRepository
+	tokenStore     tokens.TokenStore
+	emailSender    emails.Sender
+	loginGuard     *lockout.Guard
+}
+
+func NewController(settings config.Config, userRepository users.UserRepository, tokenStore tokens.TokenStore, emailSender emails.Sender, loginGuard *lockout.Guard) *Controller {
+	return &Controller{settings: settings, userRepository: userRepository, tokenStore: tokenStore, emailSender: emailSender, loginGuard: loginGuard}
+}
+
+func (controller *Controller) SignUp(context *gin.Context) {
 	
-	var body users.User
-	err := context.BindJSON(&body)
+	var body SignUpRequest
+	err := validation.Bind(context, &body)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
-	err = users.SignUp(body)
+	user := users.User{
+		FirstName: body.FirstName,
+		LastName: body.LastName,
+		Email: body.Email,
+		Password: body.Password,
+	}
+
+	err = users.SignUp(context.Request.Context(), controller.userRepository, controller.emailSender, controller.settings.BaseUiHost, user)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "isRegistered": false})
+		context.Error(err)
 		return
 	}
 
+	metrics.Signups.Inc()
+
 	context.JSON(http.StatusCreated, gin.H{"isRegistered": true})	
 }
 
-func ConfirmEmail(context *gin.Context) {
+func (controller *Controller) ConfirmEmail(context *gin.Context) {
 	
 	token := context.Param("token")
 
 	if token == "" {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "Token não informado"})
+		context.Error(apperrors.BadRequest("Token não informado"))
 		return
 	}
 
-	user, err := users.GetUserByValidationToken(token)
+	user, err := controller.userRepository.GetUserByValidationToken(context.Request.Context(), token)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	if user.Email == "" {
-		context.JSON(http.StatusNotFound, gin.H{"error": "Token inválido"})
+		context.Error(apperrors.NotFound("Token inválido"))
 		return
 	}
 	
-	err = user.ConfirmEmail()
+	err = controller.userRepository.ConfirmEmail(context.Request.Context(), &user)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	context.JSON(http.StatusOK, gin.H{"isEmailConfirmed": true})
 }
 
-func CreateUser(context *gin.Context) {
+func (controller *Controller) UnlockAccount(context *gin.Context) {
+
+	token := context.Param("token")
+
+	user, err := controller.userRepository.GetUserByUnlockToken(context.Request.Context(), token)
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	if user.Email == "" {
+		context.Error(apperrors.NotFound("Token inválido"))
+		return
+	}
+
+	err = controller.loginGuard.Unlock(context.Request.Context(), user)
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"isUnlocked": true})
+}
+
+func (controller *Controller) GetLockouts(context *gin.Context) {
+
+	result, err := controller.userRepository.GetLockedUsers(context.Request.Context())
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	context.JSON(http.StatusOK, result)
+}
+
+func (controller *Controller) ClearLockout(context *gin.Context) {
+
+	objectId, err := primitive.ObjectIDFromHex(context.Param("id"))
+
+	if err != nil {
+		context.Error(apperrors.BadRequest("Id do usuário inválido"))
+		return
+	}
+
+	user, err := controller.userRepository.GetUserById(context.Request.Context(), objectId)
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	if user.Email == "" {
+		context.Error(apperrors.NotFound("Usuário não encontrado"))
+		return
+	}
+
+	err = controller.loginGuard.Unlock(context.Request.Context(), user)
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	slog.InfoContext(context.Request.Context(), "Lockout cleared by an admin", "user_id", objectId.Hex())
+
+	context.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (controller *Controller) CreateUser(context *gin.Context) {
 
 	var body users.User
-	context.BindJSON(&body)
+	if err := validation.Bind(context, &body); err != nil {
+		context.Error(err)
+		return
+	}
 
-	err := users.CreateUser(body)
+	err := controller.userRepository.CreateUser(context.Request.Context(), body)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "isRegistered": false})
+		context.Error(err)
 		return
 	}
 
 	context.JSON(http.StatusCreated, gin.H{"isRegistered": true})
 }
 
-func Login(context *gin.Context) {	
+func (controller *Controller) Login(context *gin.Context) {	
+
+	defer func() {
+		if len(context.Errors) == 0 && context.Writer.Status() == http.StatusOK {
+			metrics.Logins.WithLabelValues(metrics.RESULT_SUCCESS).Inc()
+		} else {
+			metrics.Logins.WithLabelValues(metrics.RESULT_FAILURE).Inc()
+		}
+	}()
 
 	var body users.AuthRequestBody
-	context.BindJSON(&body)
+	if err := validation.Bind(context, &body); err != nil {
+		context.Error(err)
+		return
+	}
 
 	var user users.User
 
 	user.Email = body.Email
 	user.Password = body.Password
 
-	isAuthenticated, err := user.IsAuthenticated()
+	ip := context.ClientIP()
+
+	if err := controller.loginGuard.Check(context.Request.Context(), user.Email, ip); err != nil {
+		var blocked *lockout.BlockedError
+
+		if errors.As(err, &blocked) {
+			retryAfter := int(math.Ceil(blocked.RetryAfter.Seconds()))
+			context.Header("Retry-After", strconv.Itoa(retryAfter))
+			context.Error(apperrors.TooManyRequests(blocked.Message).WithDetails(gin.H{"retry_after": retryAfter, "locked": blocked.Locked}))
+			return
+		}
+
+		context.Error(err)
+		return
+	}
+
+	isAuthenticated, err := controller.userRepository.IsAuthenticated(context.Request.Context(), &user)
+
+	if !isAuthenticated && (err == nil || errors.Is(err, users.ErrInvalidCredentials)) {
+		if err := controller.loginGuard.Failed(context.Request.Context(), user.Email, ip); err != nil {
+			slog.ErrorContext(context.Request.Context(), "Error recording the failed login", "error", err)
+		}
+	}
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	if !isAuthenticated {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Não foi possível autenticar o usuário"})		
+		context.Error(users.ErrInvalidCredentials)
 		return
 	}
 	
-	currentUser, err := users.GetUserByEmail(user.Email)
+	currentUser, err := controller.userRepository.GetUserByEmail(context.Request.Context(), user.Email)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	if currentUser.Email == "" {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não encontrado"})
+		context.Error(users.ErrInvalidCredentials)
 		return
 	}
 
 	if !currentUser.IsEmailConfirmed {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não confirmou o e-mail"})
+		context.Error(apperrors.Forbidden("Usuário não confirmou o e-mail"))
 		return
 	}
 
-	currentUser.UpdateLastLogin()
+	if err = controller.loginGuard.Succeeded(context.Request.Context(), currentUser); err != nil {
+		slog.ErrorContext(context.Request.Context(), "Error clearing the failed logins", "error", err)
+	}
+
+	controller.userRepository.UpdateLastLogin(context.Request.Context(), &currentUser)
 
 	userInfo := users.UserTokenInfo{
 		Email: strings.ToLower(currentUser.Email),
@@ -138,18 +281,18 @@ func Login(context *gin.Context) {
 		Id: currentUser.Id,
 	}
 
-	err = userToken.SetToken(userInfo)
+	err = userToken.SetToken(controller.settings.Auth, userInfo)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
-	userToken.SetTokenCookie(context)		
-	err = tokens.SaveRefreshToken(userInfo)
+	userToken.SetTokenCookie(controller.settings.Auth, context)		
+	err = controller.tokenStore.SaveRefreshToken(context.Request.Context(), userInfo)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
@@ -165,42 +308,38 @@ func Login(context *gin.Context) {
 	context.JSON(http.StatusOK, result)
 }
 
-func RefreshToken(context *gin.Context) {
+func (controller *Controller) RefreshToken(context *gin.Context) {
 	
-	userInfo, err := tokens.ExtractUserInfoForTokenRefresh(context)
+	userInfo, err := tokens.ExtractUserInfoForTokenRefresh(controller.settings.Auth, context)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	if userInfo.Id.IsZero() {
-		context.JSON(http.StatusUnauthorized, users.AuthResponse{})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
-	user_refresh_token := tokens.UserToken{
-		UserId: userInfo.Id,
-	}	
-
-	err = user_refresh_token.SetRefreshToken()
+	user_refresh_token, err := controller.tokenStore.GetRefreshToken(context.Request.Context(), userInfo.Id)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 	
 	if user_refresh_token.Token == "" {
-		context.JSON(http.StatusUnauthorized, users.AuthResponse{})
+		context.Error(apperrors.Unauthorized("Sessão não encontrada"))
 		return
 	}
 
-	fmt.Println("Refresh token expiration date (UTC):", user_refresh_token.ExpirationDate.Time())
+	slog.DebugContext(context.Request.Context(), "Refresh token found", "expiration_date", user_refresh_token.ExpirationDate.Time().UTC())
 
 	// Checks if the user_refresh_token ExpirationDate is in the past
 	if user_refresh_token.ExpirationDate.Time().UTC().Before(time.Now().UTC()) {
-		tokens.DeleteTokenCookie(context)
-		context.JSON(http.StatusUnauthorized, users.AuthResponse{})
+		tokens.DeleteTokenCookie(controller.settings.Auth, context)
+		context.Error(apperrors.Unauthorized("Sessão expirada"))
 		return
 	}
 
@@ -209,14 +348,14 @@ func RefreshToken(context *gin.Context) {
 		UserId: user_refresh_token.UserId,
 	}
 	
-	err = userToken.SetToken(userInfo)	
+	err = userToken.SetToken(controller.settings.Auth, userInfo)	
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
-	userToken.SetTokenCookie(context)
+	userToken.SetTokenCookie(controller.settings.Auth, context)
 	tokenExpirationDate := userToken.ExpirationDate.Time().UTC()
 
 	result := users.AuthResponse {
@@ -229,90 +368,83 @@ func RefreshToken(context *gin.Context) {
 	context.JSON(http.StatusOK, result)
 }
 
-func LogOut(context *gin.Context) {			
-	tokens.DeleteTokenCookie(context)
+func (controller *Controller) LogOut(context *gin.Context) {			
+	tokens.DeleteTokenCookie(controller.settings.Auth, context)
 	context.JSON(http.StatusOK, gin.H{"message": "Logout realizado com sucesso"})
 }
 
-func ResetPassword(context *gin.Context) {
+func (controller *Controller) ResetPassword(context *gin.Context) {
 	var request ResetPasswordRequestBody
-	context.BindJSON(&request)
-
-	if request.Token == "" {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "Token não informado"})
-	}
-
-	if request.Password == "" {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "Senha não informada"})
+	if err := validation.Bind(context, &request); err != nil {
+		context.Error(err)
+		return
 	}
 
-	user, err := users.GetUserByValidationToken(request.Token)
+	user, err := controller.userRepository.GetUserByValidationToken(context.Request.Context(), request.Token)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	if user.Email == "" {
-		context.JSON(http.StatusNotFound, gin.H{"error": "Token inválido"})
+		context.Error(apperrors.NotFound("Token inválido"))
 		return
 	}
 
-	err = user.UpdatePassword(request.Password)
+	err = controller.userRepository.UpdatePassword(context.Request.Context(), &user, request.Password)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
-	err = user.ResetValidationToken()
+	err = user.ResetValidationToken(context.Request.Context(), controller.userRepository)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	context.JSON(http.StatusOK, gin.H{"success": true})
 }
 
-func VerifyRessetToken(context *gin.Context) {
+func (controller *Controller) VerifyRessetToken(context *gin.Context) {
 	
 	var request VerifyResestTokenRequest
-	context.BindJSON(&request)
-
-	if request.Token == "" {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "Token não informado"})
+	if err := validation.Bind(context, &request); err != nil {
+		context.Error(err)
+		return
 	}
 
-	user, err := users.GetUserByValidationToken(request.Token)
+	user, err := controller.userRepository.GetUserByValidationToken(context.Request.Context(), request.Token)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	if user.Email == "" {
-		context.JSON(http.StatusNotFound, gin.H{"error": "Token inválido"})
+		context.Error(apperrors.NotFound("Token inválido"))
 		return
 	}
 
 	context.JSON(http.StatusOK, gin.H{"success": true})
 }
 
-func RequestPasswordReset(context *gin.Context) {
+func (controller *Controller) RequestPasswordReset(context *gin.Context) {
 	
 	var request ResetPasswordRequest
 
-	context.BindJSON(&request)
-
-	if request.Email == "" {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "E-mail não informado"})
+	if err := validation.Bind(context, &request); err != nil {
+		context.Error(err)
+		return
 	}
 
-	user, err := users.GetUserByEmail(request.Email)
+	user, err := controller.userRepository.GetUserByEmail(context.Request.Context(), request.Email)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
@@ -322,47 +454,49 @@ func RequestPasswordReset(context *gin.Context) {
 	}
 
 	user.ValidationToken = tokens.GenerateValidationToken()	
-	err = users.UpdateValidationToken(user)
+	err = controller.userRepository.UpdateValidationToken(context.Request.Context(), user)
 
 	if (err != nil) {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
-	SendRecoveryEmail(user)
+	controller.SendRecoveryEmail(context.Request.Context(), user)
+
+	context.JSON(http.StatusOK, gin.H{"success": true})
 }
 
-func SendRecoveryEmail(user users.User) {
-	go emails.SendEmail("", []string{user.Email}, "Alteração de senha", "Olá, "+user.FirstName+" "+user.LastName+".\n\n"+"Para alterar sua senha, acesse o link abaixo:\n\n"+os.Getenv("BASE_UI_HOST")+"/alterar-senha?token="+user.ValidationToken+"\n\n"+"Atenciosamente,\n\n"+"Equipe @vagasprajr")
+func (controller *Controller) SendRecoveryEmail(ctx context.Context, user users.User) {
+	controller.emailSender.Send(ctx, "", []string{user.Email}, "Alteração de senha", "Olá, "+user.FirstName+" "+user.LastName+".\n\n"+"Para alterar sua senha, acesse o link abaixo:\n\n"+controller.settings.BaseUiHost+"/alterar-senha?token="+user.ValidationToken+"\n\n"+"Atenciosamente,\n\n"+"Equipe @vagasprajr")
 }
 
-func GetUser(context *gin.Context) {
+func (controller *Controller) GetUser(context *gin.Context) {
 	
 	userRole := context.MustGet("userRole").(string)
 
 	if userRole != "admin" {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autorizado"})
+		context.Error(apperrors.Forbidden("Usuário não autorizado"))
 		return
 	}
 
 	userId := context.Param("id")
 
 	if userId == "" {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "Id do usuário não informado"})
+		context.Error(apperrors.BadRequest("Id do usuário não informado"))
 		return
 	}
 
 	objectId, err := primitive.ObjectIDFromHex(userId)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "Id do usuário inválido"})
+		context.Error(apperrors.BadRequest("Id do usuário inválido"))
 		return
 	}
 
-	user, err := users.GetUserById(objectId)
+	user, err := controller.userRepository.GetUserById(context.Request.Context(), objectId)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
@@ -397,28 +531,32 @@ func GetUser(context *gin.Context) {
 	context.JSON(http.StatusOK, response)
 }
 
-func GetPublicUserProfile(context *gin.Context) {
+func (controller *Controller) GetPublicUserProfile(context *gin.Context) {
 	
 	userName := context.Param("username")
 
 	if userName == "" {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "Nome do usuário não informado"})
+		context.Error(apperrors.BadRequest("Nome do usuário não informado"))
 		return
 	}
 	
-	user, err := users.GetUserByUserName(userName)
+	user, err := controller.userRepository.GetUserByUserName(context.Request.Context(), userName)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	if !user.IsPublic {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autorizado"})
+		context.Error(apperrors.Forbidden("Usuário não autorizado"))
 		return
 	}
 
-	user.IncrementProfileViews()
+	controller.userRepository.IncrementProfileViews(context.Request.Context(), &user)
+
+	if httpcache.NotModified(context, httpcache.NewVersion(httpcache.NewStamp(user.Id.Hex(), user.LastUpdate.Time(), user.CreatedAt.Time()))) {
+		return
+	}
 
 	response := UserProfileResponse {
 		Id: user.Id.Hex(),
@@ -442,92 +580,92 @@ func GetPublicUserProfile(context *gin.Context) {
 	context.JSON(http.StatusOK, response)
 }
 
-func DeleteUserAsAdmin(context *gin.Context) {
+func (controller *Controller) DeleteUserAsAdmin(context *gin.Context) {
 	
 	userRole := context.MustGet("userRole").(string)
 
 	if userRole != "admin" {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autorizado"})
+		context.Error(apperrors.Forbidden("Usuário não autorizado"))
 		return
 	}
 
 	userId := context.Param("id")
 
 	if userId == "" {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "Id do usuário não informado"})
+		context.Error(apperrors.BadRequest("Id do usuário não informado"))
 		return
 	}
 
 	objectId, err := primitive.ObjectIDFromHex(userId)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "Id do usuário inválido"})
+		context.Error(apperrors.BadRequest("Id do usuário inválido"))
 		return
 	}
 
-	err = users.DeleteUser(objectId)
+	err = controller.userRepository.DeleteUser(context.Request.Context(), objectId)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	context.JSON(http.StatusOK, gin.H{"success": true})
 }
 
-func DeleteUser(context *gin.Context) {
+func (controller *Controller) DeleteUser(context *gin.Context) {
 	
 	currentUser, context_error := context.Get(middlewares.USER_TOKEN_INFO)
 
 	if !context_error {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao recuperar informações do usuário conectado"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
 	userInfo := currentUser.(users.UserTokenInfo)
 
 	if userInfo.Id.IsZero() {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
-	user, err := users.GetUserById(userInfo.Id)
+	user, err := controller.userRepository.GetUserById(context.Request.Context(), userInfo.Id)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
-	err = users.DeleteUser(user.Id)
+	err = controller.userRepository.DeleteUser(context.Request.Context(), user.Id)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	context.JSON(http.StatusOK, gin.H{"success": true})
 }
 
-func GetUserProfile(context *gin.Context) {
+func (controller *Controller) GetUserProfile(context *gin.Context) {
 	
 	currentUser, context_error := context.Get(middlewares.USER_TOKEN_INFO)	
 
 	if !context_error {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao recuperar informações do usuário conectado"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
 	userInfo := currentUser.(users.UserTokenInfo)
 	
 	if userInfo.Id.IsZero() {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
-	user, err := users.GetUserById(userInfo.Id)
+	user, err := controller.userRepository.GetUserById(context.Request.Context(), userInfo.Id)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
@@ -561,40 +699,43 @@ func GetUserProfile(context *gin.Context) {
 	context.JSON(http.StatusOK, response)
 }
 
-func IsAuthorized(context *gin.Context) {
+func (controller *Controller) IsAuthorized(context *gin.Context) {
 	currentUser, context_error := context.Get(middlewares.USER_TOKEN_INFO)
 
 	if !context_error {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao recuperar informações do usuário conectado"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
 	userInfo := currentUser.(users.UserTokenInfo)
 
 	if userInfo.Id.IsZero() {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
-	user, err := users.GetUserById(userInfo.Id)
+	user, err := controller.userRepository.GetUserById(context.Request.Context(), userInfo.Id)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	if user.Email == "" {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não encontrado"})
+		context.Error(apperrors.Unauthorized("Usuário não encontrado"))
 		return
 	}
 
 	if userInfo.Id != user.Id {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autorizado"})
+		context.Error(apperrors.Forbidden("Usuário não autorizado"))
 		return
 	}
 	
 	var request AuthorizeRequest
-	context.BindJSON(&request)
+	if err := validation.Bind(context, &request); err != nil {
+		context.Error(err)
+		return
+	}
 
 	isAuthorized := false
 
@@ -608,106 +749,100 @@ func IsAuthorized(context *gin.Context) {
 	context.JSON(http.StatusOK, gin.H{"isAuthorized": isAuthorized})
 }
 
-func UpdateUserName(context *gin.Context) {
+func (controller *Controller) UpdateUserName(context *gin.Context) {
 	
 	currentUser, context_error := context.Get(middlewares.USER_TOKEN_INFO)	
 
 	if !context_error {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao recuperar informações do usuário conectado"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
 	userInfo := currentUser.(users.UserTokenInfo)
 
 	if userInfo.Id.IsZero() {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
-	user, err := users.GetUserById(userInfo.Id)
+	user, err := controller.userRepository.GetUserById(context.Request.Context(), userInfo.Id)
 
 	if err != nil {	
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	var request UpdateUserNameRequest	
-	context.BindJSON(&request)
-
-	if request.UserName == "" {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "Nome de usuário não informado"})
+	if err := validation.Bind(context, &request); err != nil {
+		context.Error(err)
 		return
 	}
 
-	is_valid := users.IsUserNameValid(request.UserName)
-
-	if !is_valid {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "Nome de usuário inválido. Somete use letras e números; não use espaço e deve ter no mínimo 3 caracteres. Por favor, escolha outro."})
-		return
-	}
-
-	already_exists, err := users.UserNameAlreadyExists(request.UserName)
+	already_exists, err := controller.userRepository.UserNameAlreadyExists(context.Request.Context(), request.UserName)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	if already_exists {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "Nome de usuário já em uso. Por favor, escolha outro."})
+		context.Error(apperrors.Conflict("Nome de usuário já em uso. Por favor, escolha outro."))
 		return
 	}
 
 	user.Id = userInfo.Id
 	user.UserName = request.UserName
 
-	err = user.UpdateUserName()
+	err = controller.userRepository.UpdateUserName(context.Request.Context(), &user)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
-	user, err = users.GetUserById(userInfo.Id)
+	user, err = controller.userRepository.GetUserById(context.Request.Context(), userInfo.Id)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	context.JSON(http.StatusOK, gin.H{"success": true, "user_name": user.UserName, "message": "Nome de usuário atualizado com sucesso"})	
 }
 
-func UpdateUser(context *gin.Context) {
+func (controller *Controller) UpdateUser(context *gin.Context) {
 	
 	currentUser, context_error := context.Get(middlewares.USER_TOKEN_INFO)	
 
 	if !context_error {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao recuperar informações do usuário conectado"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
 	userInfo := currentUser.(users.UserTokenInfo)
 	
 	if userInfo.Id.IsZero() {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
-	user, err := users.GetUserById(userInfo.Id)
+	user, err := controller.userRepository.GetUserById(context.Request.Context(), userInfo.Id)
 	
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	if userInfo.Id != user.Id {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autorizado"})
+		context.Error(apperrors.Forbidden("Usuário não autorizado"))
 		return
 	}
 
 	var request UpdateUserRequest
-	context.BindJSON(&request)
+	if err := validation.Bind(context, &request); err != nil {
+		context.Error(err)
+		return
+	}
 
 	user.Id = userInfo.Id
 	user.FirstName = request.FirstName
@@ -725,17 +860,17 @@ func UpdateUser(context *gin.Context) {
 	user.ProfileImageUrl = request.ProfileImageUrl
 	user.OAuthImageURL = request.OAuthImageURL
 		
-	err = user.Update()
+	err = controller.userRepository.UpdateProfile(context.Request.Context(), &user)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
-	user, err = users.GetUserById(userInfo.Id)
+	user, err = controller.userRepository.GetUserById(context.Request.Context(), userInfo.Id)
 	
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
@@ -766,38 +901,38 @@ func UpdateUser(context *gin.Context) {
 	context.JSON(http.StatusOK, response)
 }
 
-func UploadProfilePicture(context *gin.Context) {
+func (controller *Controller) UploadProfilePicture(context *gin.Context) {
 	
 	currentUser, context_error := context.Get(middlewares.USER_TOKEN_INFO)	
 
 	if !context_error {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao recuperar informações do usuário conectado"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
 	userInfo := currentUser.(users.UserTokenInfo)
 	
 	if userInfo.Id.IsZero() {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
-	user, err := users.GetUserById(userInfo.Id)
+	user, err := controller.userRepository.GetUserById(context.Request.Context(), userInfo.Id)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	if userInfo.Id != user.Id {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autorizado"})
+		context.Error(apperrors.Forbidden("Usuário não autorizado"))
 		return
 	}
 
 	file, err := context.FormFile("file")
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		context.Error(apperrors.BadRequest("Arquivo não informado").Wrap(err))
 		return
 	}
 
@@ -805,10 +940,10 @@ func UploadProfilePicture(context *gin.Context) {
 
 	userIdString := userInfo.Id.Hex()
 
-	err = context.SaveUploadedFile(file, "./uploads/"+ userIdString + "/" + fileName)
+	err = context.SaveUploadedFile(file, filepath.Join(controller.settings.UploadsDir, userIdString, fileName))
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
@@ -816,110 +951,150 @@ func UploadProfilePicture(context *gin.Context) {
 
 	user.ProfileImageUrl = fileName
 
-	err = user.UpdateProfilePicture()
+	err = controller.userRepository.UpdateProfilePicture(context.Request.Context(), &user)
 
 	// if err != nil {
-	// 	context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	// 	context.Error(err)
 	// 	return
 	// }
 
-	// user, err = users.GetUserById(userInfo.Id)
+	// user, err = controller.userRepository.GetUserById(context.Request.Context(), userInfo.Id)
 
 	// if err != nil {
-	// 	context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	// 	context.Error(err)
 	// 	return
 	// }
 
 	// context.JSON(http.StatusOK, gin.H{"success": true, "profile_picture": user.ProfilePicture, "message": "Foto de perfil atualizada com sucesso"})
 }
 
-func UpdateUserBookmarkedJobs(context *gin.Context) {
+func (controller *Controller) UpdateUserBookmarkedJobs(context *gin.Context) {
 	currentUser, context_error := context.Get(middlewares.USER_TOKEN_INFO)	
 
 	if !context_error {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao recuperar informações do usuário conectado"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
 	userInfo := currentUser.(users.UserTokenInfo)
 	
 	if userInfo.Id.IsZero() {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
-	user, err := users.GetUserById(userInfo.Id)
+	user, err := controller.userRepository.GetUserById(context.Request.Context(), userInfo.Id)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	if user.Id.IsZero() {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		context.Error(apperrors.Unauthorized("Usuário não autenticado"))
 		return
 	}
 
 	var request UpdateUserBookmarkRequest
-	context.BindJSON(&request)
+	if err := validation.Bind(context, &request); err != nil {
+		context.Error(err)
+		return
+	}
 
 	user.BookmarkedJobs = request.BookmarkedJobs
-	user.UpdateUserBookmarkedJobs()
+	controller.userRepository.UpdateUserBookmarkedJobs(context.Request.Context(), &user)
 
-	user, err = users.GetUserById(userInfo.Id)
+	user, err = controller.userRepository.GetUserById(context.Request.Context(), userInfo.Id)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	context.JSON(http.StatusOK, user.BookmarkedJobs)	
 }
 
-func GetUsers(context *gin.Context) {
+func (controller *Controller) GetUsers(context *gin.Context) {
 
-	userRole := context.MustGet("userRole").(string)
+	result, err := controller.getUsers(context)
 
-	if userRole != "admin" {
-		context.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autorizado"})
+	if err != nil {
+		context.Error(err)
 		return
 	}
+		
+	context.JSON(http.StatusOK, result)	
+}
 
-	var request commons.FilterRequest
-	context.BindJSON(&request)
+// GetUsersV2 answers GetUsers in the envelope of the v2 API.
+func (controller *Controller) GetUsersV2(context *gin.Context) {
 
-	result, err := users.GetUsers(request)
+	result, err := controller.getUsers(context)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
-		
-	context.JSON(http.StatusOK, result)	
+
+	context.JSON(http.StatusOK, result.ToPage())
 }
 
-func GetTalents(context *gin.Context) {
-	
+func (controller *Controller) getUsers(context *gin.Context) (users.UsersPaginatedResult, error) {
+
+	userRole := context.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		return users.UsersPaginatedResult{}, apperrors.Forbidden("Usuário não autorizado")
+	}
+
 	var request commons.FilterRequest
-	context.BindJSON(&request)
+	if err := validation.Bind(context, &request); err != nil {
+		return users.UsersPaginatedResult{}, err
+	}
 
-	result, err := users.GetTalents(request, false, false)
+	return controller.userRepository.GetUsers(context.Request.Context(), request)
+}
+
+func (controller *Controller) GetTalents(context *gin.Context) {
+
+	result, err := controller.getTalents(context)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 		
 	context.JSON(http.StatusOK, result)
 }
 
-func GetGravatarUrl(context *gin.Context) {
+// GetTalentsV2 answers GetTalents in the envelope of the v2 API.
+func (controller *Controller) GetTalentsV2(context *gin.Context) {
+
+	result, err := controller.getTalents(context)
+
+	if err != nil {
+		context.Error(err)
+		return
+	}
+
+	context.JSON(http.StatusOK, result.ToPage())
+}
+
+func (controller *Controller) getTalents(context *gin.Context) (users.UserTalentsPaginatedResult, error) {
 	
-	var request GravatarRequest
-	context.BindJSON(&request)
+	var request commons.FilterRequest
+	if err := validation.Bind(context, &request); err != nil {
+		return users.UserTalentsPaginatedResult{}, err
+	}
 
-	if request.Email == "" {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "E-mail não informado"})
+	return controller.userRepository.GetTalents(context.Request.Context(), request, false, false)
+}
+
+func (controller *Controller) GetGravatarUrl(context *gin.Context) {
+	
+	var request GravatarRequest
+	if err := validation.Bind(context, &request); err != nil {
+		context.Error(err)
 		return
 	}
 
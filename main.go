@@ -1,43 +1,195 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
 	"os"
-	"regexp"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/logging"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/indexes"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/migrations"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/routes"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/emails"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/expiry"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/tracing"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 )
 
 func main() {
 
-	err := godotenv.Load()
+	settings, err := config.Load()
 
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		fatal("Error loading the configuration", err)
 	}
-	
-	// print the environment variables
-	log.Println("DEBUG_MODE:", os.Getenv("DEBUG_MODE"))
-	log.Println("VERSION:", os.Getenv("VERSION"))
-	log.Println("BASE_UI_HOST:", os.Getenv("BASE_UI_HOST"))
 
-	mongoDbUrl := os.Getenv("MONGODB_URL")
+	logger, err := logging.New(settings.Log, os.Stdout)
 
-	if mongoDbUrl == "" {
-		log.Fatal("MONGODB_URL is required")
+	if err != nil {
+		fatal("Error creating the logger", err)
+	}
+
+	// The log package and gin's debug messages also go through the logger.
+	slog.SetDefault(logger)
+
+	// The MongoDB URL password is redacted by the logger.
+	slog.Info("Starting",
+		"debug_mode", settings.DebugMode,
+		"version", settings.Version,
+		"base_ui_host", settings.BaseUiHost,
+		"mongodb_url", settings.MongoDb.Url,
+		"mongodb_database", settings.MongoDb.Database,
+	)
+
+	if !settings.DebugMode {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	gin.DefaultWriter = slog.NewLogLogger(logger.Handler(), slog.LevelDebug).Writer()
+	gin.DefaultErrorWriter = slog.NewLogLogger(logger.Handler(), slog.LevelError).Writer()
+
+	shutdownTracing, err := tracing.Setup(context.Background(), settings.Tracing, settings.Version)
+
+	if err != nil {
+		fatal("Error setting up the tracing", err)
+	}
+
+	db, err := models.Connect(settings.MongoDb)
+
+	if err != nil {
+		fatal("Error connecting to MongoDB", err)
+	}
+
+	defer db.Disconnect(context.Background())
+
+	// go run main.go indexes
+	if len(os.Args) > 1 && os.Args[1] == "indexes" {
+		report, err := indexes.Reconcile(context.Background(), db, indexes.All(), false)
+		if err != nil {
+			fatal("Error reading the indexes", err)
+		}
+		slog.Info("Indexes", "report", report.String())
+		return
+	}
+
+	// go run main.go migrate [status]
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err = migrate(db, settings, os.Args[2:]); err != nil {
+			fatal("Error applying the migrations", err)
+		}
+		return
+	}
+
+	if settings.MigrateOnStartup {
+		if _, err = migrations.Run(context.Background(), db, settings); err != nil {
+			fatal("Error applying the migrations", err)
+		}
+	}
+
+	if settings.EnsureIndexes {
+		report, err := indexes.Reconcile(context.Background(), db, indexes.All(), true)
+		if err != nil {
+			slog.Error("Error reconciling the indexes", "error", err)
+		} else {
+			slog.Info("Indexes", "report", report.String())
+		}
+	}
+
+	server := gin.New()
+	server.Use(gin.Recovery())
+
+	repositories := routes.NewMongoRepositories(db, settings)
+
+	routes.RegisterRoutes(server, settings, repositories)
+
+	scheduler := expiry.NewScheduler(settings, repositories.Jobs, repositories.Users, emails.Synchronous(repositories.EmailSender))
+	scheduler.Start()
+
+	httpServer := &http.Server{
+		Addr:    ":" + settings.Port,
+		Handler: server,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("Error starting the server", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+
+	slog.Info("Shutting down the server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), settings.ShutdownTimeout)
+	defer cancel()
+
+	// Stop accepting new requests and wait for the in-flight ones, then for
+	// the scheduler and the emails still being sent in background.
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Error shutting down the server", "error", err)
+	}
+
+	if err := scheduler.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Error stopping the job expiry scheduler", "error", err)
+	}
+
+	if err := repositories.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Error waiting for the background emails", "error", err)
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Error flushing the traces", "error", err)
+	}
+
+	slog.Info("Server stopped")
+}
+
+// migrate applies the pending migrations, or lists the applied ones with the
+// status argument.
+func migrate(db *models.DbContext, settings config.Config, args []string) error {
+
+	if len(args) > 0 && args[0] == "status" {
+		applied, err := migrations.GetAppliedMigrations(context.Background(), db)
+
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range applied {
+			slog.Info("Applied migration", "version", migration.Version, "description", migration.Description, "applied_at", migration.AppliedAt.Format(time.RFC3339))
+		}
+
+		pending, err := migrations.Pending(migrations.All(settings), applied)
+
+		if err != nil {
+			return err
+		}
+
+		slog.Info("Migrations", "applied", len(applied), "pending", len(pending))
+
+		return nil
 	}
 
-	re := regexp.MustCompile(`(mongodb://.*?:)(.*?)(@.*)`)
-	modifiedURL := re.ReplaceAllString(mongoDbUrl, `$1*.*.*$3`)
+	applied, err := migrations.Run(context.Background(), db, settings)
 
-	log.Println("MONGODB_URL:", modifiedURL)
-	log.Println("MONGODB_DATABASE:", os.Getenv("MONGODB_DATABASE"))	
+	slog.Info("Migrations applied", "count", len(applied))
 
-	server := gin.Default()
+	return err
+}
 
-	routes.RegisterRoutes(server)
-	
-	server.Run(":3001")
-}
\ No newline at end of file
+// fatal logs the error and exits; the deferred calls do not run.
+func fatal(message string, err error) {
+	slog.Error(message, "error", err)
+	os.Exit(1)
+}
@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	CACHE_HIT  = "hit"
+	CACHE_MISS = "miss"
+
+	CACHE_ROLES = "roles"
+	CACHE_JOBS  = "jobs"
+
+	RESULT_SUCCESS = "success"
+	RESULT_FAILURE = "failure"
+)
+
+var (
+	HttpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HttpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method and route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	MongoOperationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mongodb_operation_duration_seconds",
+		Help:    "MongoDB operation latency by repository and function.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"repository", "function"})
+
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_requests_total",
+		Help: "Redis cache lookups by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+
+	EmailsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "emails_sent_total",
+		Help: "Emails sent by result (success or failure).",
+	}, []string{"result"})
+
+	JobsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "jobs_created_total",
+		Help: "Jobs created.",
+	})
+
+	JobsExpired = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "jobs_expired_total",
+		Help: "Jobs closed by the scheduler after their expiry date.",
+	})
+
+	ShortLinkRedirects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "short_link_redirects_total",
+		Help: "Short link redirects by prefix (go, r or j).",
+	}, []string{"prefix"})
+
+	Signups = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "signups_total",
+		Help: "Users signed up with email and password.",
+	})
+
+	Logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "logins_total",
+		Help: "Logins with email and password by result (success or failure).",
+	}, []string{"result"})
+
+	OAuthLogins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "oauth_logins_total",
+		Help: "OAuth logins by provider.",
+	}, []string{"provider"})
+
+	LoginLockouts = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "login_lockouts_total",
+		Help: "Accounts locked after too many failed logins.",
+	})
+
+	RateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rate_limited_requests_total",
+		Help: "Requests rejected by the rate limiter by policy.",
+	}, []string{"policy"})
+
+	DeprecatedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "deprecated_requests_total",
+		Help: "Requests to deprecated routes by method and route, to know when they can be removed.",
+	}, []string{"method", "route"})
+)
+
+func init() {
+	// Start the counters at zero, so the ratios exist before the first event.
+	for _, cache := range []string{CACHE_ROLES, CACHE_JOBS} {
+		CacheRequests.WithLabelValues(cache, CACHE_HIT)
+		CacheRequests.WithLabelValues(cache, CACHE_MISS)
+	}
+
+	for _, result := range []string{RESULT_SUCCESS, RESULT_FAILURE} {
+		EmailsSent.WithLabelValues(result)
+		Logins.WithLabelValues(result)
+	}
+}
+
+// ObserveMongoOperation starts timing a repository function; call the
+// returned function when it is done:
+//
+//	defer metrics.ObserveMongoOperation("jobs", "GetJobs")()
+func ObserveMongoOperation(repository string, function string) func() {
+	start := time.Now()
+	return func() {
+		MongoOperationDuration.WithLabelValues(repository, function).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveCache counts a cache lookup as a hit or a miss.
+func ObserveCache(cache string, hit bool) {
+	result := CACHE_MISS
+	if hit {
+		result = CACHE_HIT
+	}
+	CacheRequests.WithLabelValues(cache, result).Inc()
+}
+
+// Result returns the result label of an operation.
+func Result(err error) string {
+	if err != nil {
+		return RESULT_FAILURE
+	}
+	return RESULT_SUCCESS
+}
@@ -1,11 +1,12 @@
 package authentication
 
 import (
-	"net/http"
-	"os"
 	"strings"
 	"time"
 
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares/errorhandler"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users/tokens"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -15,7 +16,7 @@ const (
 	TOKEN_NAME = "vagasprajr_token"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(auth config.AuthConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token, err := c.Cookie(TOKEN_NAME)
 
@@ -24,20 +25,20 @@ func AuthMiddleware() gin.HandlerFunc {
 			token = c.GetHeader("Authorization")
 
 			if token == "" || len(token) < 7 {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token not found"})								
+				errorhandler.Abort(c, apperrors.Unauthorized("Token não encontrado"))								
 				return
 			}
 
 			token = token[7:]
 			
 			if token == "" {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token not found"})
+				errorhandler.Abort(c, apperrors.Unauthorized("Token não encontrado"))
 				return
 			}
 		}
 
 		if token == "" && err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token not found"})
+			errorhandler.Abort(c, apperrors.Unauthorized("Token não encontrado"))
 			return
 		}		
 		
@@ -47,12 +48,12 @@ func AuthMiddleware() gin.HandlerFunc {
 			claim jwt.MapClaims
 		)
 
-		key = []byte(os.Getenv("JWT_SECRET"))
+		key = []byte(auth.JwtSecret)
 
         // Check if the token is malformed
         parts := strings.Split(token, ".")
         if len(parts) != 3 {
-            c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Malformed token"})
+            errorhandler.Abort(c, apperrors.Unauthorized("Token inválido"))
             return
         }		
 
@@ -61,7 +62,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		})
 
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Error parsing the token. " + err.Error() + " Token:" + token}) 
+			errorhandler.Abort(c, apperrors.Unauthorized("Token inválido").Wrap(err))
 			return
 		}
 
@@ -70,12 +71,12 @@ func AuthMiddleware() gin.HandlerFunc {
 		userTokenInfo, err := tokens.GetUserInfo(claim)
 
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			errorhandler.Abort(c, apperrors.Unauthorized("Token inválido").Wrap(err))
 			return
 		}
 		
 		if time.Now().UTC().Unix() > int64(claim["exp"].(float64)) {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token expired"})	
+			errorhandler.Abort(c, apperrors.Unauthorized("Token expirado"))
 			return
 		}
 
@@ -1,11 +1,13 @@
 package authorization
 
 import (
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares/errorhandler"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users"
 	"github.com/gin-gonic/gin"
 )
 
-func AuthorizationMiddleware(roles []string) gin.HandlerFunc {
+func AuthorizationMiddleware(userRepository users.UserRepository, roles []string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		if len(roles) == 0 {			
@@ -16,13 +18,15 @@ func AuthorizationMiddleware(roles []string) gin.HandlerFunc {
 		userTokenInfo := c.MustGet("userTokenInfo").(users.UserTokenInfo)
 
 		if userTokenInfo.Id.IsZero() {
-			c.AbortWithStatusJSON(401, gin.H{"error": "Unable to get user information"})
+			errorhandler.Abort(c, apperrors.Unauthorized("Usuário não autenticado"))
+			return
 		}
 
-		userRoles, err := users.GetUserRoles(userTokenInfo.Id)
+		userRoles, err := userRepository.GetUserRoles(c.Request.Context(), userTokenInfo.Id)
 
 		if err != nil {
-			c.AbortWithStatusJSON(401, gin.H{"error": "Unable to get user roles"})
+			errorhandler.Abort(c, apperrors.From(err))
+			return
 		}
 
 		for _, role := range roles {
@@ -35,7 +39,7 @@ func AuthorizationMiddleware(roles []string) gin.HandlerFunc {
 			}
 		}
 
-		c.AbortWithStatusJSON(401, gin.H{"error": "User does not have the necessary permissions"})	
+		errorhandler.Abort(c, apperrors.Forbidden("Usuário sem permissão para este recurso"))	
 		
 	}
 }
\ No newline at end of file
@@ -2,4 +2,5 @@ package middlewares
 
 const (
 	USER_TOKEN_INFO = "userTokenInfo"
-)
\ No newline at end of file
+	REQUEST_ID      = "requestId"
+)
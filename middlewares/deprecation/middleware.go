@@ -0,0 +1,55 @@
+package deprecation
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DEPRECATION_HEADER = "Deprecation"
+	SUNSET_HEADER      = "Sunset"
+	LINK_HEADER        = "Link"
+)
+
+// Headers are the response headers set by the middleware, to be exposed to
+// the browsers by CORS.
+var Headers = []string{DEPRECATION_HEADER, SUNSET_HEADER, LINK_HEADER}
+
+// Policy describes the deprecation of a group of routes. Since and Sunset are
+// optional; Successor, when set, returns the path replacing the requested one.
+type Policy struct {
+	Since     time.Time
+	Sunset    time.Time
+	Successor func(path string) string
+}
+
+// DeprecationMiddleware marks the responses of deprecated routes with the
+// Deprecation header (RFC 9745), the Sunset header (RFC 8594) with the date
+// the routes will be removed, and a Link to the successor route.
+func DeprecationMiddleware(policy Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		deprecation := "true"
+		if !policy.Since.IsZero() {
+			deprecation = "@" + strconv.FormatInt(policy.Since.Unix(), 10)
+		}
+		c.Header(DEPRECATION_HEADER, deprecation)
+
+		if !policy.Sunset.IsZero() {
+			c.Header(SUNSET_HEADER, policy.Sunset.UTC().Format(http.TimeFormat))
+		}
+
+		if policy.Successor != nil {
+			c.Header(LINK_HEADER, fmt.Sprintf(`<%s>; rel="successor-version"`, policy.Successor(c.Request.URL.Path)))
+		}
+
+		metrics.DeprecatedRequests.WithLabelValues(c.Request.Method, c.FullPath()).Inc()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,33 @@
+package errorhandler
+
+import (
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHandlerMiddleware answers the last error added to the context with
+// c.Error, unless a response was already written. The errors are rendered as
+// {code, message, details, request_id}; the unknown ones as an internal error,
+// so their cause only reaches the request log.
+func ErrorHandlerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := apperrors.From(c.Errors.Last().Err)
+
+		c.JSON(err.Status, err.Response(c.GetString(middlewares.REQUEST_ID)))
+	}
+}
+
+// Abort stops the chain of handlers with the error, to be answered by
+// ErrorHandlerMiddleware.
+func Abort(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
@@ -0,0 +1,133 @@
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	CACHE_CONTROL_HEADER     = "Cache-Control"
+	ETAG_HEADER              = "ETag"
+	LAST_MODIFIED_HEADER     = "Last-Modified"
+	IF_NONE_MATCH_HEADER     = "If-None-Match"
+	IF_MODIFIED_SINCE_HEADER = "If-Modified-Since"
+)
+
+// Headers are the validators set in the responses, to be exposed to the
+// browsers by CORS.
+var Headers = []string{ETAG_HEADER, LAST_MODIFIED_HEADER}
+
+// Stamp identifies a document by its id and the time it last changed.
+type Stamp struct {
+	Id       string
+	Modified time.Time
+}
+
+// NewStamp stamps the document with its last update or, when it was never
+// updated, its creation.
+func NewStamp(id string, lastUpdate time.Time, createdAt time.Time) Stamp {
+	if lastUpdate.After(createdAt) {
+		return Stamp{Id: id, Modified: lastUpdate}
+	}
+	return Stamp{Id: id, Modified: createdAt}
+}
+
+// Version identifies a representation by the documents it is built from. The
+// ETag is weak, since it is computed from the stamps of the documents and not
+// from the bytes of the response.
+type Version struct {
+	ETag         string
+	LastModified time.Time
+}
+
+func NewVersion(stamps ...Stamp) Version {
+	hash := sha256.New()
+	lastModified := time.Time{}
+
+	for _, stamp := range stamps {
+		fmt.Fprintf(hash, "%s:%d;", stamp.Id, stamp.Modified.UnixNano())
+		if stamp.Modified.After(lastModified) {
+			lastModified = stamp.Modified
+		}
+	}
+
+	return Version{
+		ETag:         `W/"` + hex.EncodeToString(hash.Sum(nil))[:32] + `"`,
+		LastModified: lastModified.UTC().Truncate(time.Second),
+	}
+}
+
+// CacheControlMiddleware lets the browsers and the CDN reuse the responses of
+// a public route for maxAge; with a zero maxAge they revalidate them on every
+// use. The errors are not cached.
+func CacheControlMiddleware(maxAge time.Duration) gin.HandlerFunc {
+
+	policy := "public, no-cache"
+	if maxAge > 0 {
+		policy = "public, max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+	}
+
+	return func(c *gin.Context) {
+		c.Header(CACHE_CONTROL_HEADER, policy)
+
+		c.Next()
+
+		// The error is answered afterwards by the error handler
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			c.Writer.Header().Del(CACHE_CONTROL_HEADER)
+		}
+	}
+}
+
+// NotModified sets the validators of the version in the response and answers
+// 304 Not Modified when the request is conditional on a version the client
+// already has. If-None-Match takes precedence over If-Modified-Since, as in
+// RFC 9110.
+func NotModified(c *gin.Context, version Version) bool {
+
+	c.Header(ETAG_HEADER, version.ETag)
+
+	if !version.LastModified.IsZero() {
+		c.Header(LAST_MODIFIED_HEADER, version.LastModified.Format(http.TimeFormat))
+	}
+
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+
+	if match := c.GetHeader(IF_NONE_MATCH_HEADER); match != "" {
+		if !matchesETag(match, version.ETag) {
+			return false
+		}
+	} else if since := c.GetHeader(IF_MODIFIED_SINCE_HEADER); since != "" && !version.LastModified.IsZero() {
+		date, err := http.ParseTime(since)
+		if err != nil || version.LastModified.After(date) {
+			return false
+		}
+	} else {
+		return false
+	}
+
+	c.AbortWithStatus(http.StatusNotModified)
+
+	return true
+}
+
+// matchesETag compares the ETags of an If-None-Match header weakly, ignoring
+// the W/ prefix.
+func matchesETag(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
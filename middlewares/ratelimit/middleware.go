@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares/errorhandler"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	API_KEY_HEADER = "X-API-Key"
+
+	RATE_LIMIT_LIMIT_HEADER     = "RateLimit-Limit"
+	RATE_LIMIT_REMAINING_HEADER = "RateLimit-Remaining"
+	RATE_LIMIT_RESET_HEADER     = "RateLimit-Reset"
+	RATE_LIMIT_POLICY_HEADER    = "RateLimit-Policy"
+	RETRY_AFTER_HEADER          = "Retry-After"
+)
+
+// Headers are the response headers of the middleware, to be exposed by CORS.
+var Headers = []string{RATE_LIMIT_LIMIT_HEADER, RATE_LIMIT_REMAINING_HEADER, RATE_LIMIT_RESET_HEADER, RATE_LIMIT_POLICY_HEADER, RETRY_AFTER_HEADER}
+
+// KeyFunc identifies the client of a request.
+type KeyFunc func(c *gin.Context) string
+
+// ByIp identifies the clients by their IP address.
+func ByIp(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUser identifies the clients by the id of the authenticated user, or by
+// their IP address when the request is anonymous.
+func ByUser(c *gin.Context) string {
+	if info, ok := c.Get(middlewares.USER_TOKEN_INFO); ok {
+		if user, ok := info.(users.UserTokenInfo); ok && !user.Id.IsZero() {
+			return "user:" + user.Id.Hex()
+		}
+	}
+	return ByIp(c)
+}
+
+// ByApiKey identifies the clients by the API key header when it is one of the
+// issued keys, or by their IP address otherwise, so a made up key does not get
+// a counter of its own. Only a hash of the key is stored.
+func ByApiKey(keys []string) KeyFunc {
+
+	issued := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		issued[hashApiKey(key)] = true
+	}
+
+	return func(c *gin.Context) string {
+		key := c.GetHeader(API_KEY_HEADER)
+		if key == "" {
+			return ByIp(c)
+		}
+		hash := hashApiKey(key)
+		if !issued[hash] {
+			return ByIp(c)
+		}
+		return "apikey:" + hash
+	}
+}
+
+func hashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
+
+// RateLimitMiddleware limits the requests of each client under the policy,
+// answering 429 once the limit is reached. When the limiter fails the request
+// is let through.
+func RateLimitMiddleware(limiter ratelimit.Limiter, policy ratelimit.Policy, key KeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		result, err := limiter.Allow(c.Request.Context(), policy, key(c))
+
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Error checking the rate limit", "policy", policy.Name, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header(RATE_LIMIT_LIMIT_HEADER, strconv.Itoa(result.Limit))
+		c.Header(RATE_LIMIT_REMAINING_HEADER, strconv.Itoa(result.Remaining))
+		c.Header(RATE_LIMIT_RESET_HEADER, strconv.Itoa(seconds(result.Reset)))
+		c.Header(RATE_LIMIT_POLICY_HEADER, fmt.Sprintf("%d;w=%d", policy.Limit, seconds(policy.Window)))
+
+		if !result.Allowed {
+			retryAfter := seconds(result.RetryAfter)
+
+			metrics.RateLimited.WithLabelValues(policy.Name).Inc()
+
+			c.Header(RETRY_AFTER_HEADER, strconv.Itoa(retryAfter))
+			errorhandler.Abort(c, apperrors.TooManyRequests("Muitas requisições, tente novamente mais tarde").WithDetails(gin.H{"retry_after": retryAfter}))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// seconds rounds the duration up to whole seconds.
+func seconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,38 @@
+package requestid
+
+import (
+	"regexp"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/logging"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	REQUEST_ID_HEADER = "X-Request-ID"
+)
+
+// validRequestId keeps the IDs sent by the clients or proxies short and safe
+// to log; other values are replaced by a new ID.
+var validRequestId = regexp.MustCompile(`^[\w.:-]{1,128}$`)
+
+// RequestIdMiddleware reuses the X-Request-ID header of the request or creates
+// a new ID, returns it in the response header and adds it to the request
+// context, so every log line of the request carries it.
+func RequestIdMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		requestId := c.GetHeader(REQUEST_ID_HEADER)
+
+		if !validRequestId.MatchString(requestId) {
+			requestId = uuid.New().String()
+		}
+
+		c.Set(middlewares.REQUEST_ID, requestId)
+		c.Header(REQUEST_ID_HEADER, requestId)
+		c.Request = c.Request.WithContext(logging.WithRequestId(c.Request.Context(), requestId))
+
+		c.Next()
+	}
+}
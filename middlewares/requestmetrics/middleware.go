@@ -0,0 +1,30 @@
+package requestmetrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestMetricsMiddleware counts and times the requests by route. The
+// requests that match no route share the "unmatched" label, so unknown paths
+// do not create new series.
+func RequestMetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+
+		if route == "" {
+			route = "unmatched"
+		}
+
+		metrics.HttpRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HttpRequestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
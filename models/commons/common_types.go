@@ -1,9 +1,6 @@
 package commons
 
 import (
-	"encoding/json"
-	"fmt"
-	"log"
 	"strconv"
 	"strings"
 	"time"
@@ -13,24 +10,26 @@ import (
 )
 
 type FilterRequest struct {
-    Sort     	string  			`bson:"sort" json:"sort"`
+    Sort     	string  			`bson:"sort" json:"sort" binding:"max=100,excludesall=$"`
 	IsAscending bool 				`bson:"is_ascending" json:"is_ascending"`
-    Page     	int      			`bson:"page" json:"page"`
-    PageSize 	int      			`bson:"page_size" json:"page_size"`
-    Filters  	[]Filter 			`bson:"filters" json:"filters"`
+    Page     	int      			`bson:"page" json:"page" binding:"min=0"`
+    PageSize 	int      			`bson:"page_size" json:"page_size" binding:"min=0,max=100"`
+    Filters  	[]Filter 			`bson:"filters" json:"filters" binding:"max=20,dive"`
+    Cursor   	string  			`bson:"cursor" json:"cursor" binding:"max=1024"`
+    SkipTotal 	bool    			`bson:"skip_total" json:"skip_total"`
 }
 
 type Filter struct {
-	Operator string `bson:"operator" json:"operator"`
-	Fields []Field `bson:"fields" json:"fields"`
+	Operator string `bson:"operator" json:"operator" binding:"oneof=and or nor"`
+	Fields []Field `bson:"fields" json:"fields" binding:"max=20,dive"`
 }
 
 type Field struct {
-	Name 		string `bson:"name" json:"name"`
-	Value 		string `bson:"value" json:"value"`
-	Type 		string `bson:"type" json:"type"`
-	MinValue 	string `bson:"min_value" json:"min_value"`
-	MaxValue 	string `bson:"max_value" json:"max_value"`
+	Name 		string `bson:"name" json:"name" binding:"required,max=100,excludesall=$"`
+	Value 		string `bson:"value" json:"value" binding:"max=500"`
+	Type 		string `bson:"type" json:"type" binding:"omitempty,oneof=string date array_object array_string boolean checkbox number number_range"`
+	MinValue 	string `bson:"min_value" json:"min_value" binding:"max=50"`
+	MaxValue 	string `bson:"max_value" json:"max_value" binding:"max=50"`
 }
 
 func (filter *FilterRequest) GetFilter() bson.M {
@@ -105,13 +104,5 @@ func (filter *FilterRequest) GetFilter() bson.M {
 		returnFilter["$and"] = andConditions
 	}
 
-	filterJSON, err := json.MarshalIndent(returnFilter, "", "  ")
-    if err != nil {
-        log.Fatalf("Error converting filter to JSON: %v", err)
-    }
-
-    // Print the JSON string
-    fmt.Println("MongoDB Query Filter:", string(filterJSON))
-	
 	return returnFilter
 }
\ No newline at end of file
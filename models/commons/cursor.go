@@ -0,0 +1,314 @@
+package commons
+
+import (
+	"encoding/base64"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NO_TOTAL is the total of the paginated results whose documents were not
+// counted, as asked by skip_total.
+const NO_TOTAL = -1
+
+const DEFAULT_SORT = "created_at"
+
+// PageQuery is the page asked of a listing. Without a cursor the page is
+// selected by its number, skipping the documents of the previous pages. With
+// a cursor, the page continues after (or before) the document the cursor was
+// taken from, ordered by the sort field and then by _id, so that documents
+// inserted in the meantime neither repeat nor shift the pages.
+type PageQuery struct {
+	Sort        string
+	IsAscending bool
+	Page        int
+	PageSize    int
+
+	cursor *pageCursor
+}
+
+// Cursors are the opaque tokens of the pages around the returned one; they
+// are empty when there is no such page.
+type Cursors struct {
+	Next string
+	Prev string
+}
+
+type pageCursor struct {
+	Sort        string      `bson:"s"`
+	IsAscending bool        `bson:"a"`
+	Value       interface{} `bson:"v"`
+	Id          interface{} `bson:"i"`
+	Backward    bool        `bson:"b"`
+}
+
+// NewPageQuery decodes the cursor, if any, checking it was taken from a
+// listing with the same order.
+func NewPageQuery(sort string, isAscending bool, page int, pageSize int, cursor string) (PageQuery, error) {
+
+	if sort == "" {
+		sort = DEFAULT_SORT
+	}
+
+	if page < 1 {
+		page = 1
+	}
+
+	query := PageQuery{Sort: sort, IsAscending: isAscending, Page: page, PageSize: pageSize}
+
+	if cursor == "" {
+		return query, nil
+	}
+
+	// The score of a text search cannot be compared in a filter
+	if sort == SORT_RELEVANCE {
+		return PageQuery{}, apperrors.InvalidField("cursor", "A ordenação por relevância é paginada pelo número da página")
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+
+	if err != nil {
+		return PageQuery{}, apperrors.InvalidField("cursor", "Cursor inválido")
+	}
+
+	var decoded pageCursor
+
+	if err = bson.Unmarshal(data, &decoded); err != nil || !isCursorValue(decoded.Value) || !isCursorValue(decoded.Id) {
+		return PageQuery{}, apperrors.InvalidField("cursor", "Cursor inválido")
+	}
+
+	if decoded.Sort != sort || decoded.IsAscending != isAscending {
+		return PageQuery{}, apperrors.InvalidField("cursor", "O cursor pertence a uma listagem com outra ordenação")
+	}
+
+	query.cursor = &decoded
+
+	return query, nil
+}
+
+// Filter adds to the filter of the listing the condition selecting the
+// documents after the cursor.
+func (query PageQuery) Filter(filter bson.M) bson.M {
+
+	if query.cursor == nil {
+		return filter
+	}
+
+	return bson.M{"$and": []bson.M{filter, query.keyset()}}
+}
+
+// FindOptions sorts, skips and limits the Find of the page. One document more
+// than the page size is read to know whether there is a next page.
+func (query PageQuery) FindOptions() *options.FindOptions {
+
+	skip, limit := query.bounds()
+
+	options := options.Find().SetSort(query.sortKeys()).SetSkip(int64(skip)).SetLimit(int64(limit))
+
+	if query.Sort == SORT_RELEVANCE {
+		options = options.SetProjection(bson.M{TEXT_SCORE: bson.M{"$meta": "textScore"}})
+	}
+
+	return options
+}
+
+// DecodePage decodes the documents read with the query and builds the cursors
+// of the next and previous pages.
+func DecodePage[T any](query PageQuery, documents []bson.M) ([]T, Cursors, error) {
+
+	backward := query.cursor != nil && query.cursor.Backward
+	more := query.PageSize > 0 && len(documents) > query.PageSize
+
+	if more {
+		documents = documents[:query.PageSize]
+	}
+
+	// The pages before a cursor are read in the reverse order
+	if backward {
+		for i, j := 0, len(documents)-1; i < j; i, j = i+1, j-1 {
+			documents[i], documents[j] = documents[j], documents[i]
+		}
+	}
+
+	var cursors Cursors
+
+	if len(documents) > 0 && query.Sort != SORT_RELEVANCE {
+
+		hasNext, hasPrev := more, query.cursor != nil || query.Page > 1
+
+		if backward {
+			hasNext, hasPrev = true, more
+		}
+
+		var err error
+
+		if hasNext {
+			if cursors.Next, err = query.encode(documents[len(documents)-1], false); err != nil {
+				return nil, Cursors{}, err
+			}
+		}
+
+		if hasPrev {
+			if cursors.Prev, err = query.encode(documents[0], true); err != nil {
+				return nil, Cursors{}, err
+			}
+		}
+	}
+
+	var items []T
+
+	for _, document := range documents {
+		var item T
+		if err := FromDocument(document, &item); err != nil {
+			return nil, Cursors{}, err
+		}
+		items = append(items, item)
+	}
+
+	return items, cursors, nil
+}
+
+// FindPage reads the page of the items the way the query would read it from
+// MongoDB, returning also the number of items matching the filter.
+func FindPage[T any](items []T, filter bson.M, query PageQuery) ([]bson.M, int, error) {
+	return FindTextPage(items, filter, query, TextIndex{})
+}
+
+// FindTextPage is FindPage for the collections with a text index, evaluating
+// the $text condition of the filter with the scores of TextIndex.Score.
+func FindTextPage[T any](items []T, filter bson.M, query PageQuery, index TextIndex) ([]bson.M, int, error) {
+
+	documents, err := FindTextDocuments(items, filter, index)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(documents)
+
+	if query.cursor != nil {
+		selected := []bson.M{}
+		for _, document := range documents {
+			if MatchFilter(document, query.keyset()) {
+				selected = append(selected, document)
+			}
+		}
+		documents = selected
+	}
+
+	isAscending := query.direction() > 0
+
+	if query.Sort == SORT_RELEVANCE {
+		SortDocuments(documents, "_id", true)
+		SortDocuments(documents, TEXT_SCORE, false)
+	} else {
+		SortDocuments(documents, "_id", isAscending)
+		SortDocuments(documents, query.Sort, isAscending)
+	}
+
+	skip, limit := query.bounds()
+
+	if skip > len(documents) {
+		skip = len(documents)
+	}
+
+	documents = documents[skip:]
+
+	if limit > 0 && limit < len(documents) {
+		documents = documents[:limit]
+	}
+
+	return documents, total, nil
+}
+
+func (query PageQuery) direction() int {
+
+	isAscending := query.IsAscending
+
+	if query.cursor != nil && query.cursor.Backward {
+		isAscending = !isAscending
+	}
+
+	if isAscending {
+		return 1
+	}
+
+	return -1
+}
+
+func (query PageQuery) sortKeys() bson.D {
+
+	if query.Sort == SORT_RELEVANCE {
+		return bson.D{{Key: TEXT_SCORE, Value: bson.M{"$meta": "textScore"}}, {Key: "_id", Value: 1}}
+	}
+
+	if query.Sort == "_id" {
+		return bson.D{{Key: "_id", Value: query.direction()}}
+	}
+
+	return bson.D{{Key: query.Sort, Value: query.direction()}, {Key: "_id", Value: query.direction()}}
+}
+
+func (query PageQuery) bounds() (int, int) {
+
+	skip, limit := 0, 0
+
+	if query.cursor == nil {
+		skip = (query.Page - 1) * query.PageSize
+	}
+
+	if query.PageSize > 0 {
+		limit = query.PageSize + 1
+	}
+
+	return skip, limit
+}
+
+func (query PageQuery) keyset() bson.M {
+
+	operator := "$lt"
+
+	if query.direction() > 0 {
+		operator = "$gt"
+	}
+
+	if query.Sort == "_id" {
+		return bson.M{"_id": bson.M{operator: query.cursor.Id}}
+	}
+
+	return bson.M{"$or": []bson.M{
+		{query.Sort: bson.M{operator: query.cursor.Value}},
+		{query.Sort: query.cursor.Value, "_id": bson.M{operator: query.cursor.Id}},
+	}}
+}
+
+func (query PageQuery) encode(document bson.M, backward bool) (string, error) {
+
+	values, _ := lookupValues(document, query.Sort)
+
+	data, err := bson.Marshal(pageCursor{
+		Sort:        query.Sort,
+		IsAscending: query.IsAscending,
+		Value:       firstValue(values),
+		Id:          document["_id"],
+		Backward:    backward,
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// isCursorValue accepts only the scalar values a cursor is built from, so that
+// a forged cursor cannot add operators to the filter.
+func isCursorValue(value interface{}) bool {
+	switch value.(type) {
+	case nil, string, bool, int32, int64, float64, primitive.DateTime, primitive.ObjectID:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,523 @@
+package commons
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ToDocument converts a value to the bson.M shape it has once stored in MongoDB,
+// so the in-memory repositories can evaluate the same filters the Mongo ones send.
+func ToDocument(value interface{}) (bson.M, error) {
+	data, err := bson.Marshal(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var document bson.M
+
+	if err = bson.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// FromDocument decodes a document into the given struct, applying the same
+// projection a Find with a typed result would apply.
+func FromDocument(document bson.M, result interface{}) error {
+	data, err := bson.Marshal(document)
+
+	if err != nil {
+		return err
+	}
+
+	return bson.Unmarshal(data, result)
+}
+
+// FindDocuments returns the items matching the filter as documents, sorted by
+// the given field when it is not empty, as a Find on their collection would.
+func FindDocuments[T any](items []T, filter bson.M, sortField string, isAscending bool) ([]bson.M, error) {
+	documents := []bson.M{}
+
+	for _, item := range items {
+		document, err := ToDocument(item)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if MatchFilter(document, filter) {
+			documents = append(documents, document)
+		}
+	}
+
+	if sortField != "" {
+		SortDocuments(documents, sortField, isAscending)
+	}
+
+	return documents, nil
+}
+
+// MatchFilter reports whether the document satisfies a MongoDB query filter.
+// It supports the operators the repositories build: $and, $or, $regex, $in,
+// $eq, $ne, $gt, $gte, $lt, $lte and $exists, including dotted paths and arrays,
+// and $expr with the expressions of evaluateExpression.
+func MatchFilter(document bson.M, filter bson.M) bool {
+	for key, condition := range filter {
+		switch key {
+		case "$and":
+			for _, subFilter := range toFilterList(condition) {
+				if !MatchFilter(document, subFilter) {
+					return false
+				}
+			}
+		case "$or":
+			subFilters := toFilterList(condition)
+			matched := len(subFilters) == 0
+			for _, subFilter := range subFilters {
+				if MatchFilter(document, subFilter) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false
+			}
+		case "$expr":
+			if !isTruthy(evaluateExpression(document, condition)) {
+				return false
+			}
+		default:
+			values, exists := lookupValues(document, key)
+			if !matchCondition(values, exists, condition) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// SortDocuments sorts the documents by the given field, keeping the insertion
+// order for equal values.
+func SortDocuments(documents []bson.M, field string, isAscending bool) {
+	sort.SliceStable(documents, func(i, j int) bool {
+		left, _ := lookupValues(documents[i], field)
+		right, _ := lookupValues(documents[j], field)
+
+		result, ok := compareSortValues(firstValue(left), firstValue(right))
+
+		if !ok {
+			return false
+		}
+
+		if isAscending {
+			return result < 0
+		}
+
+		return result > 0
+	})
+}
+
+// GetPageBounds returns the slice bounds a skip/limit query with the given page
+// and page size would return. A page size of zero means no limit.
+func GetPageBounds(length int, page int, perPage int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+
+	start := (page - 1) * perPage
+
+	if start > length {
+		start = length
+	}
+
+	end := length
+
+	if perPage > 0 && start+perPage < length {
+		end = start + perPage
+	}
+
+	return start, end
+}
+
+func toFilterList(condition interface{}) []bson.M {
+	switch filters := condition.(type) {
+	case []bson.M:
+		return filters
+	case bson.A:
+		return toFilterList([]interface{}(filters))
+	case []interface{}:
+		result := make([]bson.M, 0, len(filters))
+		for _, item := range filters {
+			if filter, ok := item.(bson.M); ok {
+				result = append(result, filter)
+			}
+		}
+		return result
+	}
+
+	return []bson.M{}
+}
+
+func lookupValues(document bson.M, path string) ([]interface{}, bool) {
+	current := []interface{}{document}
+
+	for _, part := range strings.Split(path, ".") {
+		next := []interface{}{}
+		for _, value := range current {
+			for _, item := range expandArray(value) {
+				if nested, ok := toMap(item); ok {
+					if fieldValue, found := nested[part]; found {
+						next = append(next, fieldValue)
+					}
+				}
+			}
+		}
+		current = next
+	}
+
+	if len(current) == 0 {
+		return []interface{}{nil}, false
+	}
+
+	values := []interface{}{}
+
+	for _, value := range current {
+		values = append(values, value)
+		if isArray(value) {
+			values = append(values, expandArray(value)...)
+		}
+	}
+
+	return values, true
+}
+
+func matchCondition(values []interface{}, exists bool, condition interface{}) bool {
+	operators, ok := toMap(condition)
+
+	if !ok || !isOperatorMap(operators) {
+		return anyValue(values, func(value interface{}) bool { return valuesEqual(value, condition) })
+	}
+
+	for operator, operand := range operators {
+		switch operator {
+		case "$regex":
+			options, _ := operators["$options"].(string)
+			pattern, _ := operand.(string)
+			if strings.Contains(options, "i") {
+				pattern = "(?i)" + pattern
+			}
+			expression, err := regexp.Compile(pattern)
+			if err != nil {
+				return false
+			}
+			if !anyValue(values, func(value interface{}) bool {
+				text, isString := value.(string)
+				return isString && expression.MatchString(text)
+			}) {
+				return false
+			}
+		case "$options":
+			continue
+		case "$in":
+			items := expandArray(operand)
+			if !anyValue(values, func(value interface{}) bool {
+				for _, item := range items {
+					if valuesEqual(value, item) {
+						return true
+					}
+				}
+				return false
+			}) {
+				return false
+			}
+		case "$eq":
+			if !anyValue(values, func(value interface{}) bool { return valuesEqual(value, operand) }) {
+				return false
+			}
+		case "$ne":
+			if anyValue(values, func(value interface{}) bool { return valuesEqual(value, operand) }) {
+				return false
+			}
+		case "$exists":
+			expected, _ := operand.(bool)
+			if exists != expected {
+				return false
+			}
+		case "$gt", "$gte", "$lt", "$lte":
+			if !anyValue(values, func(value interface{}) bool {
+				result, comparable := compareValues(value, operand)
+				if !comparable {
+					return false
+				}
+				switch operator {
+				case "$gt":
+					return result > 0
+				case "$gte":
+					return result >= 0
+				case "$lt":
+					return result < 0
+				default:
+					return result <= 0
+				}
+			}) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// evaluateExpression evaluates an aggregation expression on the document:
+// "$field" paths, literals and the $and, $or, $not, $eq, $ne, $gt, $gte, $lt
+// and $lte operators. Missing and null values compare lower than any other,
+// as in MongoDB.
+func evaluateExpression(document bson.M, expression interface{}) interface{} {
+
+	if path, ok := expression.(string); ok && strings.HasPrefix(path, "$") {
+		values, exists := lookupValues(document, strings.TrimPrefix(path, "$"))
+		if !exists {
+			return nil
+		}
+		return values[0]
+	}
+
+	operators, ok := toMap(expression)
+
+	if !ok || len(operators) != 1 || !isOperatorMap(operators) {
+		return expression
+	}
+
+	for operator, operand := range operators {
+
+		arguments := expandArray(operand)
+
+		switch operator {
+		case "$and":
+			for _, argument := range arguments {
+				if !isTruthy(evaluateExpression(document, argument)) {
+					return false
+				}
+			}
+			return true
+		case "$or":
+			for _, argument := range arguments {
+				if isTruthy(evaluateExpression(document, argument)) {
+					return true
+				}
+			}
+			return false
+		case "$not":
+			return len(arguments) == 1 && !isTruthy(evaluateExpression(document, arguments[0]))
+		case "$eq", "$ne", "$gt", "$gte", "$lt", "$lte":
+			if len(arguments) != 2 {
+				return false
+			}
+			result, comparable := compareSortValues(evaluateExpression(document, arguments[0]), evaluateExpression(document, arguments[1]))
+			if !comparable {
+				return operator == "$ne"
+			}
+			switch operator {
+			case "$eq":
+				return result == 0
+			case "$ne":
+				return result != 0
+			case "$gt":
+				return result > 0
+			case "$gte":
+				return result >= 0
+			case "$lt":
+				return result < 0
+			default:
+				return result <= 0
+			}
+		}
+	}
+
+	return false
+}
+
+func isTruthy(value interface{}) bool {
+	switch typed := normalizeValue(value).(type) {
+	case nil:
+		return false
+	case bool:
+		return typed
+	case float64:
+		return typed != 0
+	}
+	return true
+}
+
+func anyValue(values []interface{}, predicate func(value interface{}) bool) bool {
+	for _, value := range values {
+		if predicate(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstValue(values []interface{}) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
+
+func isOperatorMap(value bson.M) bool {
+	if len(value) == 0 {
+		return false
+	}
+	for key := range value {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+func toMap(value interface{}) (bson.M, bool) {
+	switch typed := value.(type) {
+	case bson.M:
+		return typed, true
+	case map[string]interface{}:
+		return bson.M(typed), true
+	case bson.D:
+		return typed.Map(), true
+	}
+	return nil, false
+}
+
+func isArray(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	kind := reflect.TypeOf(value).Kind()
+	if kind == reflect.Slice {
+		// []byte is stored as binary, not as an array
+		_, isBytes := value.([]byte)
+		return !isBytes
+	}
+	return kind == reflect.Array && !isObjectId(value)
+}
+
+func isObjectId(value interface{}) bool {
+	_, ok := value.(primitive.ObjectID)
+	return ok
+}
+
+func expandArray(value interface{}) []interface{} {
+	if !isArray(value) {
+		return []interface{}{value}
+	}
+
+	reflected := reflect.ValueOf(value)
+	items := make([]interface{}, 0, reflected.Len())
+
+	for i := 0; i < reflected.Len(); i++ {
+		items = append(items, reflected.Index(i).Interface())
+	}
+
+	return items
+}
+
+func normalizeValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case int:
+		return float64(typed)
+	case int32:
+		return float64(typed)
+	case int64:
+		return float64(typed)
+	case float32:
+		return float64(typed)
+	case time.Time:
+		return int64(primitive.NewDateTimeFromTime(typed))
+	case primitive.DateTime:
+		return int64(typed)
+	}
+	return value
+}
+
+func valuesEqual(left interface{}, right interface{}) bool {
+	left = normalizeValue(left)
+	right = normalizeValue(right)
+
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+
+	return reflect.DeepEqual(left, right)
+}
+
+func compareValues(left interface{}, right interface{}) (int, bool) {
+	left = normalizeValue(left)
+	right = normalizeValue(right)
+
+	switch typedLeft := left.(type) {
+	case float64:
+		if typedRight, ok := right.(float64); ok {
+			return compareOrdered(typedLeft, typedRight), true
+		}
+	case int64:
+		if typedRight, ok := right.(int64); ok {
+			return compareOrdered(typedLeft, typedRight), true
+		}
+	case string:
+		if typedRight, ok := right.(string); ok {
+			return strings.Compare(typedLeft, typedRight), true
+		}
+	case bool:
+		if typedRight, ok := right.(bool); ok {
+			if typedLeft == typedRight {
+				return 0, true
+			}
+			if !typedLeft {
+				return -1, true
+			}
+			return 1, true
+		}
+	case primitive.ObjectID:
+		if typedRight, ok := right.(primitive.ObjectID); ok {
+			return strings.Compare(typedLeft.Hex(), typedRight.Hex()), true
+		}
+	}
+
+	return 0, false
+}
+
+// compareSortValues orders missing and null values first, as MongoDB does.
+func compareSortValues(left interface{}, right interface{}) (int, bool) {
+	if left == nil || right == nil {
+		if left == nil && right == nil {
+			return 0, true
+		}
+		if left == nil {
+			return -1, true
+		}
+		return 1, true
+	}
+	return compareValues(left, right)
+}
+
+func compareOrdered[T float64 | int64](left T, right T) int {
+	if left < right {
+		return -1
+	}
+	if left > right {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,46 @@
+package commons
+
+// Page is the envelope of the paginated results of the v2 API.
+type Page[T any] struct {
+	Data       []T        `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// Pagination describes the page. Total and TotalPages are left out when the
+// documents were not counted; NextCursor and PrevCursor when there is no such
+// page.
+type Pagination struct {
+	Total      *int64 `json:"total,omitempty"`
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+	TotalPages *int   `json:"total_pages,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// NewPage builds the page of the data; a page without data is answered with
+// an empty list instead of null. A total of NO_TOTAL is not answered.
+func NewPage[T any](data []T, total int64, page int, pageSize int) Page[T] {
+	if data == nil {
+		data = []T{}
+	}
+
+	pagination := Pagination{Page: page, PageSize: pageSize}
+
+	if total != NO_TOTAL {
+		totalPages := 0
+		if pageSize > 0 {
+			totalPages = int((total + int64(pageSize) - 1) / int64(pageSize))
+		}
+		pagination.Total, pagination.TotalPages = &total, &totalPages
+	}
+
+	return Page[T]{Data: data, Pagination: pagination}
+}
+
+// WithCursors adds the cursors of the pages around this one.
+func (page Page[T]) WithCursors(cursors Cursors) Page[T] {
+	page.Pagination.NextCursor = cursors.Next
+	page.Pagination.PrevCursor = cursors.Prev
+	return page
+}
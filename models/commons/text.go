@@ -0,0 +1,144 @@
+package commons
+
+import (
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// SORT_RELEVANCE orders a text search by the score of its matches, the best
+// first.
+const SORT_RELEVANCE = "relevance"
+
+// TEXT_SCORE is the field of the documents holding the score of the text
+// search.
+const TEXT_SCORE = "score"
+
+// TextIndex describes a text index: the indexed fields with their weights and
+// the language of its stemming and stop words.
+type TextIndex struct {
+	Fields   []TextField
+	Language string
+}
+
+type TextField struct {
+	Name   string
+	Weight int
+}
+
+// Search returns the $text condition searching the words in the language of
+// the index.
+func (index TextIndex) Search(words string) bson.M {
+	return bson.M{"$search": words, "$language": index.Language}
+}
+
+// Score approximates, for the in-memory repositories, the textScore MongoDB
+// gives the document: each word of the search found in a field adds the weight
+// of the field. The words are compared without case, accents and plural
+// endings, and the Portuguese stop words are ignored. Zero means no match.
+func (index TextIndex) Score(document bson.M, search string) float64 {
+
+	terms := textTerms(search)
+	score := 0.0
+
+	for _, field := range index.Fields {
+		value, _ := document[field.Name].(string)
+		words := textTerms(value)
+
+		for _, term := range terms {
+			for _, word := range words {
+				if word == term {
+					score += float64(field.Weight)
+				}
+			}
+		}
+	}
+
+	return score
+}
+
+// FindTextDocuments is FindDocuments for the collections with a text index,
+// evaluating the $text condition of the filter with the scores of Score, kept
+// in the TEXT_SCORE field of the documents.
+func FindTextDocuments[T any](items []T, filter bson.M, index TextIndex) ([]bson.M, error) {
+
+	text, isText := filter["$text"].(bson.M)
+
+	if isText {
+		filter = copyWithout(filter, "$text")
+	}
+
+	documents, err := FindDocuments(items, filter, "", true)
+
+	if err != nil || !isText {
+		return documents, err
+	}
+
+	search, _ := text["$search"].(string)
+	matches := []bson.M{}
+
+	for _, document := range documents {
+		if score := index.Score(document, search); score > 0 {
+			document[TEXT_SCORE] = score
+			matches = append(matches, document)
+		}
+	}
+
+	return matches, nil
+}
+
+func copyWithout(filter bson.M, key string) bson.M {
+	result := bson.M{}
+	for name, value := range filter {
+		if name != key {
+			result[name] = value
+		}
+	}
+	return result
+}
+
+var portugueseStopWords = map[string]bool{
+	"a": true, "o": true, "as": true, "os": true, "um": true, "uma": true, "uns": true, "umas": true,
+	"de": true, "da": true, "do": true, "das": true, "dos": true, "em": true, "na": true, "no": true,
+	"nas": true, "nos": true, "ao": true, "aos": true, "e": true, "ou": true, "para": true, "por": true,
+	"com": true, "sem": true, "que": true, "se": true,
+}
+
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "í", "i", "ì", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ú", "u", "ü", "u", "ç", "c",
+)
+
+// textTerms splits the text in words, without the stop words, reduced to a
+// rough stem.
+func textTerms(text string) []string {
+
+	words := strings.FieldsFunc(accents.Replace(strings.ToLower(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+	})
+
+	terms := []string{}
+
+	for _, word := range words {
+		if portugueseStopWords[word] {
+			continue
+		}
+		terms = append(terms, stem(word))
+	}
+
+	return terms
+}
+
+func stem(word string) string {
+	switch {
+	case strings.HasSuffix(word, "coes"):
+		return strings.TrimSuffix(word, "coes") + "cao"
+	case strings.HasSuffix(word, "res"), strings.HasSuffix(word, "zes"), strings.HasSuffix(word, "les"):
+		return strings.TrimSuffix(word, "es")
+	case len(word) > 3 && strings.HasSuffix(word, "s"):
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
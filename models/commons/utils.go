@@ -2,9 +2,10 @@ package commons
 
 import (
 	"math/rand"
-	"errors"
 	"regexp"
 	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
 )
 
 func HandleValueForRegex(value string) string {
@@ -21,6 +22,8 @@ func GetBrasiliaTime() time.Time {
 	return gmt_time
 }
 
+const PASSWORD_POLICY_MESSAGE = "sua senha deve: conter no mínimo 10 caracteres, conter pelo menos uma letra maiúscula, uma letra minúscula, um número e um caractere especial"
+
 func ValidatePassword(password string) error {
 	var (
 		lowercaseLetter = regexp.MustCompile(`[a-z]`)
@@ -34,7 +37,7 @@ func ValidatePassword(password string) error {
 		!uppercaseLetter.MatchString(password) ||
 		!digit.MatchString(password) ||
 		!specialChar.MatchString(password) {
-		return errors.New("sua senha deve: conter no mínimo 10 caracteres, conter pelo menos uma letra maiúscula, uma letra minúscula, um número e um caractere especial")
+		return apperrors.InvalidField("password", PASSWORD_POLICY_MESSAGE)
 	}
 
 	return nil
@@ -2,34 +2,57 @@ package models
 
 import (
 	"context"
-	"log"
-	"os"
-	"time"
 
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 )
 
-func Connect() (*mongo.Client, error) {
+// DbContext wraps the long-lived MongoDB client shared by every repository.
+type DbContext struct {
+	Client   *mongo.Client
+	Database *mongo.Database
+}
 
-	mongo_url := os.Getenv("MONGODB_URL")
+// Connect creates the pooled client and checks the connection once. It must be
+// called a single time at startup and closed with Disconnect on shutdown.
+func Connect(settings config.MongoDbConfig) (*DbContext, error) {
 
-	// Set client options
-	clientOptions := options.Client().ApplyURI(mongo_url)
-	// Increase the timeout
-	clientOptions = clientOptions.SetConnectTimeout(60 * time.Second)
-	clientOptions = clientOptions.SetServerSelectionTimeout(60 * time.Second)
+	clientOptions := options.Client().ApplyURI(settings.Url)
+	clientOptions = clientOptions.SetMaxPoolSize(settings.MaxPoolSize)
+	clientOptions = clientOptions.SetMinPoolSize(settings.MinPoolSize)
+	clientOptions = clientOptions.SetMaxConnIdleTime(settings.MaxConnIdleTime)
+	clientOptions = clientOptions.SetConnectTimeout(settings.ConnectTimeout)
+	clientOptions = clientOptions.SetServerSelectionTimeout(settings.ServerSelectionTimeout)
+	// Child spans for every command, under the span of the request.
+	clientOptions = clientOptions.SetMonitor(otelmongo.NewMonitor())
 
-	// Connect to MongoDB
 	client, err := mongo.Connect(context.Background(), clientOptions)
-    if err != nil {
-        return nil, err
-    }
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), settings.ConnectTimeout)
+	defer cancel()
 
 	// Check the connection
-	err = client.Ping(context.Background(), nil)
+	err = client.Ping(ctx, nil)
 	if err != nil {
-		 log.Fatal(err)
+		client.Disconnect(context.Background())
+		return nil, err
 	}
-	return client, nil
-}
\ No newline at end of file
+
+	return &DbContext{
+		Client:   client,
+		Database: client.Database(settings.Database),
+	}, nil
+}
+
+func (db *DbContext) Collection(name string) *mongo.Collection {
+	return db.Database.Collection(name)
+}
+
+func (db *DbContext) Disconnect(ctx context.Context) error {
+	return db.Client.Disconnect(ctx)
+}
@@ -0,0 +1,60 @@
+package indexes
+
+import (
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/jobs"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// nonEmpty keeps the documents without the field, or with an empty value, out
+// of a unique index.
+func nonEmpty(field string) bson.M {
+	return bson.M{field: bson.M{"$gt": ""}}
+}
+
+// text declares the text index of the collection, named by MongoDB after its
+// fields.
+func text(collection string, index commons.TextIndex) Index {
+
+	keys, weights := bson.D{}, bson.D{}
+
+	for _, field := range index.Fields {
+		keys = append(keys, bson.E{Key: field.Name, Value: "text"})
+		weights = append(weights, bson.E{Key: field.Name, Value: field.Weight})
+	}
+
+	return Index{Collection: collection, Name: keysString(keys), Keys: keys, Weights: weights, DefaultLanguage: index.Language}
+}
+
+// All returns the indexes the hot query paths rely on.
+func All() []Index {
+	return []Index{
+		// Logins, sign up and the email lookups.
+		{Collection: "users", Name: "email_1", Keys: bson.D{{Key: "email", Value: 1}}, Unique: true, PartialFilter: nonEmpty("email")},
+		// Profile pages and the user name availability check.
+		{Collection: "users", Name: "user_name_1", Keys: bson.D{{Key: "user_name", Value: 1}}, Unique: true, PartialFilter: nonEmpty("user_name")},
+		// Email confirmation and password recovery.
+		{Collection: "users", Name: "validation_token_1", Keys: bson.D{{Key: "validation_token", Value: 1}}},
+		// Account unlock links and the lockouts listed to the admins.
+		{Collection: "users", Name: "unlock_token_1", Keys: bson.D{{Key: "unlock_token", Value: 1}}, PartialFilter: nonEmpty("unlock_token")},
+		{Collection: "users", Name: "locked_until_1", Keys: bson.D{{Key: "locked_until", Value: 1}}},
+		// The listings of users and talents, read by cursor in the order they were created.
+		{Collection: "users", Name: "created_at_-1__id_-1", Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Collection: "users_tokens", Name: "user_id_1", Keys: bson.D{{Key: "user_id", Value: 1}}},
+		// Job details and the short links.
+		{Collection: "jobs", Name: "code_1", Keys: bson.D{{Key: "code", Value: 1}}, Unique: true, PartialFilter: nonEmpty("code")},
+		{Collection: "jobs", Name: "job_short_url_1", Keys: bson.D{{Key: "job_short_url", Value: 1}}, Unique: true, PartialFilter: nonEmpty("job_short_url")},
+		// The moderation lists filter by approval and the lists sort by the most
+		// recent, with _id breaking the ties of the cursors.
+		{Collection: "jobs", Name: "is_approved_1_created_at_-1", Keys: bson.D{{Key: "is_approved", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Collection: "jobs", Name: "created_at_-1__id_-1", Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		// The jobs listed to their creators.
+		{Collection: "jobs", Name: "creator_1_created_at_-1__id_-1", Keys: bson.D{{Key: "creator", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		// The scheduler closing the expired jobs and reminding their creators.
+		{Collection: "jobs", Name: "status_1_expires_at_1", Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}}},
+		// The text search of the jobs, ranked by relevance.
+		text("jobs", jobs.TEXT_INDEX),
+		// Advertisement short links.
+		{Collection: "ads", Name: "short_url_1", Keys: bson.D{{Key: "short_url", Value: 1}}},
+	}
+}
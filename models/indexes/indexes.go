@@ -0,0 +1,274 @@
+package indexes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Index is an index the application relies on.
+type Index struct {
+	Collection string
+	Name       string
+	Keys       bson.D
+	Unique     bool
+	// PartialFilter limits the index to the matching documents, so unique
+	// indexes ignore the documents without the field.
+	PartialFilter bson.M
+	// Weights and DefaultLanguage describe a text index, whose Keys are the
+	// fields as "text".
+	Weights         bson.D
+	DefaultLanguage string
+}
+
+// ExistingIndex is an index found in the database.
+type ExistingIndex struct {
+	Collection string `bson:"-"`
+	Name       string `bson:"name"`
+	Keys       bson.D `bson:"key"`
+	Unique     bool   `bson:"unique"`
+
+	Weights         bson.M `bson:"weights"`
+	DefaultLanguage string `bson:"default_language"`
+}
+
+// Report compares the declared indexes with the ones in the database. Extra
+// and changed indexes are only reported, never dropped.
+type Report struct {
+	Missing []Index
+	Created []Index
+	Changed []Index
+	Extra   []ExistingIndex
+	Errors  []string
+}
+
+// Reconcile compares the declared indexes with the database and, when create
+// is true, creates the missing ones. A failure to create an index, e.g. because
+// of duplicated values, is recorded in the report instead of stopping the rest.
+func Reconcile(ctx context.Context, db *models.DbContext, declared []Index, create bool) (Report, error) {
+
+	existing := map[string][]ExistingIndex{}
+
+	for _, index := range declared {
+
+		if _, ok := existing[index.Collection]; ok {
+			continue
+		}
+
+		indexes, err := GetIndexes(ctx, db, index.Collection)
+
+		if err != nil {
+			return Report{}, err
+		}
+
+		existing[index.Collection] = indexes
+	}
+
+	report := Compare(declared, existing)
+
+	if !create {
+		return report, nil
+	}
+
+	for _, index := range report.Missing {
+
+		options := options.Index().SetName(index.Name).SetUnique(index.Unique)
+
+		if index.PartialFilter != nil {
+			options = options.SetPartialFilterExpression(index.PartialFilter)
+		}
+
+		if index.Weights != nil {
+			options = options.SetWeights(index.Weights).SetDefaultLanguage(index.DefaultLanguage)
+		}
+
+		_, err := db.Collection(index.Collection).Indexes().CreateOne(ctx, mongo.IndexModel{Keys: index.Keys, Options: options})
+
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s.%s: %v", index.Collection, index.Name, err))
+			continue
+		}
+
+		report.Created = append(report.Created, index)
+	}
+
+	return report, nil
+}
+
+// GetIndexes lists the indexes of the collection. A collection that does not
+// exist has no indexes.
+func GetIndexes(ctx context.Context, db *models.DbContext, collection string) ([]ExistingIndex, error) {
+
+	cursor, err := db.Collection(collection).Indexes().List(ctx)
+
+	if err != nil {
+		var commandError mongo.CommandError
+		if errors.As(err, &commandError) && commandError.Name == "NamespaceNotFound" {
+			return []ExistingIndex{}, nil
+		}
+		return nil, err
+	}
+
+	indexes := []ExistingIndex{}
+
+	if err = cursor.All(ctx, &indexes); err != nil {
+		return nil, err
+	}
+
+	for i := range indexes {
+		indexes[i].Collection = collection
+	}
+
+	return indexes, nil
+}
+
+// Compare matches the declared and existing indexes by collection and name.
+// The _id index is never reported as extra.
+func Compare(declared []Index, existing map[string][]ExistingIndex) Report {
+
+	report := Report{}
+	names := map[string]bool{}
+
+	for _, index := range declared {
+
+		names[index.Collection+"."+index.Name] = true
+
+		found := false
+
+		for _, current := range existing[index.Collection] {
+
+			if current.Name != index.Name {
+				continue
+			}
+
+			found = true
+
+			if !sameKeys(current, index) || current.Unique != index.Unique {
+				report.Changed = append(report.Changed, index)
+			}
+
+			break
+		}
+
+		if !found {
+			report.Missing = append(report.Missing, index)
+		}
+	}
+
+	collections := make([]string, 0, len(existing))
+
+	for collection := range existing {
+		collections = append(collections, collection)
+	}
+
+	sort.Strings(collections)
+
+	for _, collection := range collections {
+		for _, current := range existing[collection] {
+			if current.Name == "_id_" || names[collection+"."+current.Name] {
+				continue
+			}
+			report.Extra = append(report.Extra, current)
+		}
+	}
+
+	return report
+}
+
+// String summarises the report in a single line for the startup logs.
+func (report Report) String() string {
+
+	describe := func(collection string, name string) string {
+		return collection + "." + name
+	}
+
+	parts := []string{}
+
+	add := func(label string, values []string) {
+		if len(values) > 0 {
+			parts = append(parts, label+": "+strings.Join(values, ", "))
+		}
+	}
+
+	missing, created, changed, extra := []string{}, []string{}, []string{}, []string{}
+
+	for _, index := range report.Missing {
+		missing = append(missing, describe(index.Collection, index.Name))
+	}
+
+	for _, index := range report.Created {
+		created = append(created, describe(index.Collection, index.Name))
+	}
+
+	for _, index := range report.Changed {
+		changed = append(changed, describe(index.Collection, index.Name))
+	}
+
+	for _, index := range report.Extra {
+		extra = append(extra, describe(index.Collection, index.Name))
+	}
+
+	add("missing", missing)
+	add("created", created)
+	add("changed", changed)
+	add("extra", extra)
+	add("errors", report.Errors)
+
+	if len(parts) == 0 {
+		return "all indexes in place"
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// sameKeys compares the keys of the indexes. MongoDB lists the keys of a text
+// index as _fts and _ftsx, so these are compared by their weights and language.
+func sameKeys(current ExistingIndex, index Index) bool {
+
+	if index.Weights == nil {
+		return keysString(current.Keys) == keysString(index.Keys)
+	}
+
+	if current.DefaultLanguage != index.DefaultLanguage || len(current.Weights) != len(index.Weights) {
+		return false
+	}
+
+	for _, weight := range index.Weights {
+		if keysString(bson.D{{Key: weight.Key, Value: current.Weights[weight.Key]}}) != keysString(bson.D{weight}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// keysString formats the keys the way MongoDB names the indexes, e.g.
+// "is_approved_1_created_at_-1", so 1 and 1.0 compare equal.
+func keysString(keys bson.D) string {
+
+	parts := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		value := key.Value
+
+		switch number := value.(type) {
+		case int32:
+			value = int64(number)
+		case int:
+			value = int64(number)
+		case float64:
+			value = int64(number)
+		}
+
+		parts = append(parts, fmt.Sprintf("%s_%v", key.Key, value))
+	}
+
+	return strings.Join(parts, "_")
+}
@@ -0,0 +1,57 @@
+package indexes
+
+import (
+	"testing"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCompare(t *testing.T) {
+	declared := []Index{
+		{Collection: "users", Name: "email_1", Keys: bson.D{{Key: "email", Value: 1}}, Unique: true},
+		{Collection: "users", Name: "user_name_1", Keys: bson.D{{Key: "user_name", Value: 1}}, Unique: true},
+		{Collection: "jobs", Name: "is_approved_1_created_at_-1", Keys: bson.D{{Key: "is_approved", Value: 1}, {Key: "created_at", Value: -1}}},
+	}
+
+	existing := map[string][]ExistingIndex{
+		"users": {
+			{Collection: "users", Name: "_id_", Keys: bson.D{{Key: "_id", Value: int32(1)}}},
+			{Collection: "users", Name: "email_1", Keys: bson.D{{Key: "email", Value: int32(1)}}, Unique: true},
+			{Collection: "users", Name: "user_name_1", Keys: bson.D{{Key: "user_name", Value: int32(1)}}},
+			{Collection: "users", Name: "first_name_1", Keys: bson.D{{Key: "first_name", Value: float64(1)}}},
+		},
+		"jobs": {},
+	}
+
+	report := Compare(declared, existing)
+
+	assert.Len(t, report.Missing, 1)
+	assert.Equal(t, "is_approved_1_created_at_-1", report.Missing[0].Name)
+	assert.Len(t, report.Changed, 1)
+	assert.Equal(t, "user_name_1", report.Changed[0].Name)
+	assert.Len(t, report.Extra, 1)
+	assert.Equal(t, "first_name_1", report.Extra[0].Name)
+	assert.Equal(t, "missing: jobs.is_approved_1_created_at_-1; changed: users.user_name_1; extra: users.first_name_1", report.String())
+}
+
+func TestCompareTextIndexes(t *testing.T) {
+	declared := []Index{text("jobs", commons.TextIndex{Fields: []commons.TextField{{Name: "title", Weight: 10}, {Name: "description", Weight: 1}}, Language: "portuguese"})}
+
+	existing := map[string][]ExistingIndex{
+		"jobs": {{Collection: "jobs", Name: "title_text_description_text", Keys: bson.D{{Key: "_fts", Value: "text"}, {Key: "_ftsx", Value: int32(1)}}, Weights: bson.M{"title": int32(10), "description": int32(1)}, DefaultLanguage: "portuguese"}},
+	}
+
+	assert.Equal(t, "all indexes in place", Compare(declared, existing).String())
+
+	existing["jobs"][0].Weights["title"] = int32(5)
+
+	assert.Equal(t, "changed: jobs.title_text_description_text", Compare(declared, existing).String())
+}
+
+func TestDeclaredNamesMatchTheKeys(t *testing.T) {
+	for _, index := range All() {
+		assert.Equal(t, keysString(index.Keys), index.Name)
+	}
+}
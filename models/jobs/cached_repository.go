@@ -0,0 +1,358 @@
+package jobs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/cache"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	JOBS_CACHE_NAMESPACE = "jobs"
+	REVALIDATE_TIMEOUT   = 30 * time.Second
+
+	versionCacheKey = "version"
+)
+
+// CachedJobRepository caches the public job search and the aggregated values
+// of the filters, keyed by a hash of the filter. Creating, updating or
+// deleting a job changes the version of the cache: the searches of the
+// previous version are no longer read, while the aggregated values of the
+// previous version are still served, as stale, and refreshed in the
+// background.
+type CachedJobRepository struct {
+	JobRepository
+	store    cache.Store
+	settings config.CacheConfig
+	now      func() time.Time
+
+	refreshing sync.Map
+	wait       sync.WaitGroup
+}
+
+type aggregatedValuesEntry struct {
+	Version    string    `json:"version"`
+	FreshUntil time.Time `json:"fresh_until"`
+	Value      JobFacets `json:"value"`
+}
+
+func NewCachedJobRepository(repository JobRepository, store cache.Store, settings config.CacheConfig) *CachedJobRepository {
+	return &CachedJobRepository{
+		JobRepository: repository,
+		store:         cache.Namespace(store, JOBS_CACHE_NAMESPACE),
+		settings:      settings,
+		now:           time.Now,
+	}
+}
+
+func (repository *CachedJobRepository) GetJobs(ctx context.Context, body JobFilter) (PaginatedResult, error) {
+
+	version, err := repository.version(ctx)
+
+	if err != nil {
+		slog.WarnContext(ctx, "Error reading the jobs cache version", "error", err)
+		return repository.JobRepository.GetJobs(ctx, body)
+	}
+
+	key := "search:" + version + ":" + SearchFilterHash(body)
+
+	var result PaginatedResult
+
+	if repository.get(ctx, key, &result) {
+		return result, nil
+	}
+
+	result, err = repository.JobRepository.GetJobs(ctx, body)
+
+	if err != nil {
+		return PaginatedResult{}, err
+	}
+
+	repository.set(ctx, key, result, repository.settings.JobsSearchTtl)
+
+	return result, nil
+}
+
+func (repository *CachedJobRepository) GetAggregatedJobsValues(ctx context.Context, body JobFilter) (JobFacets, error) {
+
+	version, err := repository.version(ctx)
+
+	if err != nil {
+		slog.WarnContext(ctx, "Error reading the jobs cache version", "error", err)
+		return repository.JobRepository.GetAggregatedJobsValues(ctx, body)
+	}
+
+	key := "aggregated:" + AggregatedValuesFilterHash(body)
+
+	var entry aggregatedValuesEntry
+
+	if repository.get(ctx, key, &entry) {
+
+		if entry.Version != version || !repository.now().Before(entry.FreshUntil) {
+			repository.revalidate(ctx, key, version, body)
+		}
+
+		return entry.Value, nil
+	}
+
+	return repository.loadAggregatedValues(ctx, key, version, body)
+}
+
+func (repository *CachedJobRepository) CreateJob(ctx context.Context, body CreateJobBody) (Job, error) {
+
+	job, err := repository.JobRepository.CreateJob(ctx, body)
+
+	if err == nil {
+		repository.invalidate(ctx)
+	}
+
+	return job, err
+}
+
+func (repository *CachedJobRepository) DeleteJob(ctx context.Context, code string) error {
+
+	err := repository.JobRepository.DeleteJob(ctx, code)
+
+	if err == nil {
+		repository.invalidate(ctx)
+	}
+
+	return err
+}
+
+func (repository *CachedJobRepository) EditJob(ctx context.Context, id string, body EditJobBody, by primitive.ObjectID, transition JobTransition) (bool, error) {
+
+	edited, err := repository.JobRepository.EditJob(ctx, id, body, by, transition)
+
+	if err == nil && edited {
+		repository.invalidate(ctx)
+	}
+
+	return edited, err
+}
+
+func (repository *CachedJobRepository) RenewJob(ctx context.Context, id string, expiresAt time.Time, by primitive.ObjectID) (bool, error) {
+
+	renewed, err := repository.JobRepository.RenewJob(ctx, id, expiresAt, by)
+
+	if err == nil && renewed {
+		repository.invalidate(ctx)
+	}
+
+	return renewed, err
+}
+
+func (repository *CachedJobRepository) TransitionJob(ctx context.Context, id string, transition JobTransition) (bool, error) {
+
+	changed, err := repository.JobRepository.TransitionJob(ctx, id, transition)
+
+	if err == nil && changed {
+		repository.invalidate(ctx)
+	}
+
+	return changed, err
+}
+
+func (repository *CachedJobRepository) CloseExpiredJobs(ctx context.Context, now time.Time) (int64, error) {
+
+	closed, err := repository.JobRepository.CloseExpiredJobs(ctx, now)
+
+	if err == nil && closed > 0 {
+		repository.invalidate(ctx)
+	}
+
+	return closed, err
+}
+
+// Shutdown waits for the aggregated values being refreshed in the
+// background, or for the context to be done.
+func (repository *CachedJobRepository) Shutdown(ctx context.Context) error {
+
+	done := make(chan struct{})
+
+	go func() {
+		repository.wait.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (repository *CachedJobRepository) loadAggregatedValues(ctx context.Context, key string, version string, body JobFilter) (JobFacets, error) {
+
+	value, err := repository.JobRepository.GetAggregatedJobsValues(ctx, body)
+
+	if err != nil {
+		return JobFacets{}, err
+	}
+
+	entry := aggregatedValuesEntry{
+		Version:    version,
+		FreshUntil: repository.now().Add(repository.settings.JobsAggregatedTtl),
+		Value:      value,
+	}
+
+	repository.set(ctx, key, entry, repository.settings.JobsAggregatedTtl+repository.settings.JobsAggregatedStaleTtl)
+
+	return value, nil
+}
+
+// revalidate refreshes the aggregated values in the background, once per key
+// at a time.
+func (repository *CachedJobRepository) revalidate(ctx context.Context, key string, version string, body JobFilter) {
+
+	if _, refreshing := repository.refreshing.LoadOrStore(key, true); refreshing {
+		return
+	}
+
+	repository.wait.Add(1)
+
+	go func() {
+		defer repository.wait.Done()
+		defer repository.refreshing.Delete(key)
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), REVALIDATE_TIMEOUT)
+		defer cancel()
+
+		if _, err := repository.loadAggregatedValues(ctx, key, version, body); err != nil {
+			slog.WarnContext(ctx, "Error refreshing the aggregated jobs values", "error", err)
+		}
+	}()
+}
+
+// version returns the current version of the cache, starting a new one when
+// there is none, e.g. after the version was evicted.
+func (repository *CachedJobRepository) version(ctx context.Context) (string, error) {
+
+	version, err := repository.store.Get(ctx, versionCacheKey)
+
+	if errors.Is(err, cache.ErrNotFound) {
+		return repository.invalidate(ctx)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(version), nil
+}
+
+// invalidate starts a new version of the cache.
+func (repository *CachedJobRepository) invalidate(ctx context.Context) (string, error) {
+
+	version := uuid.NewString()
+
+	if err := repository.store.Set(ctx, versionCacheKey, []byte(version), 0); err != nil {
+		slog.WarnContext(ctx, "Error invalidating the jobs cache", "error", err)
+		return "", err
+	}
+
+	return version, nil
+}
+
+func (repository *CachedJobRepository) get(ctx context.Context, key string, value interface{}) bool {
+
+	data, err := repository.store.Get(ctx, key)
+
+	if err != nil && !errors.Is(err, cache.ErrNotFound) {
+		slog.WarnContext(ctx, "Error reading the jobs cache", "error", err)
+	}
+
+	hit := err == nil && json.Unmarshal(data, value) == nil
+
+	metrics.ObserveCache(metrics.CACHE_JOBS, hit)
+
+	return hit
+}
+
+func (repository *CachedJobRepository) set(ctx context.Context, key string, value interface{}, expiration time.Duration) {
+
+	data, err := json.Marshal(value)
+
+	if err == nil {
+		err = repository.store.Set(ctx, key, data, expiration)
+	}
+
+	if err != nil {
+		slog.WarnContext(ctx, "Error writing the jobs cache", "error", err)
+	}
+}
+
+// SearchFilterHash returns the same hash for the filters that select the same
+// page of jobs, whatever the order of the values of the lists or the spaces
+// around the words.
+func SearchFilterHash(body JobFilter) string {
+
+	if body.Page < 1 {
+		body.Page = 1
+	}
+
+	if body.Sort == "" {
+		body.Sort = "created_at"
+	}
+
+	body.Title = strings.Join(strings.Fields(body.Title), " ")
+	body.Search = strings.Join(strings.Fields(body.Search), " ")
+	body.Company = strings.TrimSpace(body.Company)
+	body.Location = strings.TrimSpace(body.Location)
+	body.Salary = strings.TrimSpace(body.Salary)
+	body.Provider = strings.TrimSpace(body.Provider)
+	body.Ids = sortedCopy(body.Ids)
+	body.JobFilterOptions = JobFilterOptions{
+		Companies:             sortedCopy(body.JobFilterOptions.Companies),
+		Locations:             sortedCopy(body.JobFilterOptions.Locations),
+		Providers:             sortedCopy(body.JobFilterOptions.Providers),
+		Salaries:              sortedCopy(body.JobFilterOptions.Salaries),
+		ContractTypes:         sortedCopy(body.JobFilterOptions.ContractTypes),
+		Modes:                 sortedCopy(body.JobFilterOptions.Modes),
+		AffirmativeParameters: sortedCopy(body.JobFilterOptions.AffirmativeParameters),
+	}
+
+	return hash(body)
+}
+
+// AggregatedValuesFilterHash returns the hash of the part of the filter the
+// aggregated values depend on: the filter without its pagination and order.
+func AggregatedValuesFilterHash(body JobFilter) string {
+	body.Page, body.PageSize, body.Sort, body.IsAscending, body.Cursor, body.SkipTotal = 0, 0, "", false, "", false
+	return SearchFilterHash(body)
+}
+
+func sortedCopy(values []string) []string {
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+func hash(value interface{}) string {
+
+	// The fields of the structs are encoded in their declaration order, so
+	// the encoding is canonical.
+	data, _ := json.Marshal(value)
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
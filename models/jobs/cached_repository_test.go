@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/cache"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingJobRepository counts the reads that reach the repository.
+type countingJobRepository struct {
+	*InMemoryJobRepository
+	searches   int
+	aggregated int
+}
+
+func (repository *countingJobRepository) GetJobs(ctx context.Context, body JobFilter) (PaginatedResult, error) {
+	repository.searches++
+	return repository.InMemoryJobRepository.GetJobs(ctx, body)
+}
+
+func (repository *countingJobRepository) GetAggregatedJobsValues(ctx context.Context, body JobFilter) (JobFacets, error) {
+	repository.aggregated++
+	return repository.InMemoryJobRepository.GetAggregatedJobsValues(ctx, body)
+}
+
+func newCachedTestRepository() (*CachedJobRepository, *countingJobRepository) {
+	jobs := &countingJobRepository{InMemoryJobRepository: NewInMemoryJobRepository("https://vagasprajr.test",
+		Job{Id: "1", Code: "AAAAA1", Title: "Desenvolvedor Go", Company: "Acme", IsApproved: true},
+	)}
+
+	return NewCachedJobRepository(jobs, cache.NewMemory(100), config.Default().Cache), jobs
+}
+
+func TestSearchFilterHash(t *testing.T) {
+	a := JobFilter{Title: " desenvolvedor   go ", JobFilterOptions: JobFilterOptions{Companies: []string{"B", "A"}}}
+	b := JobFilter{Page: 1, Title: "desenvolvedor go", Sort: "created_at", JobFilterOptions: JobFilterOptions{Companies: []string{"A", "B"}}}
+
+	assert.Equal(t, SearchFilterHash(a), SearchFilterHash(b))
+	assert.NotEqual(t, SearchFilterHash(a), SearchFilterHash(JobFilter{Title: "desenvolvedor go", Page: 2}))
+	assert.Equal(t, AggregatedValuesFilterHash(a), AggregatedValuesFilterHash(JobFilter{Title: "desenvolvedor go", Page: 3, JobFilterOptions: JobFilterOptions{Companies: []string{"A", "B"}}}))
+	assert.NotEqual(t, AggregatedValuesFilterHash(a), AggregatedValuesFilterHash(JobFilter{Title: "desenvolvedor go"}))
+}
+
+func TestCachedSearchIsInvalidatedWhenAJobChanges(t *testing.T) {
+	ctx := context.Background()
+	repository, jobs := newCachedTestRepository()
+	filter := JobFilter{PageSize: 10}
+
+	result, err := repository.GetJobs(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), result.Total)
+
+	repository.GetJobs(ctx, JobFilter{PageSize: 10, Page: 1})
+	assert.Equal(t, 1, jobs.searches)
+
+	job, err := repository.CreateJob(ctx, CreateJobBody{Title: "Desenvolvedor Java", Company: "Globex"})
+	assert.NoError(t, err)
+	_, err = repository.TransitionJob(ctx, job.Id, JobTransition{From: JOB_STATUS_PENDING_REVIEW, To: JOB_STATUS_APPROVED, At: time.Now()})
+	assert.NoError(t, err)
+
+	result, err = repository.GetJobs(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.Total)
+	assert.Equal(t, 2, jobs.searches)
+
+	assert.NoError(t, repository.DeleteJob(ctx, "AAAAA1"))
+
+	result, _ = repository.GetJobs(ctx, filter)
+	assert.Equal(t, int64(1), result.Total)
+	assert.Equal(t, 3, jobs.searches)
+}
+
+func TestCachedAggregatedValuesAreServedStaleWhileRevalidating(t *testing.T) {
+	ctx := context.Background()
+	repository, jobs := newCachedTestRepository()
+
+	values, err := repository.GetAggregatedJobsValues(ctx, JobFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Acme"}, values.ToOptions().Companies)
+
+	repository.GetAggregatedJobsValues(ctx, JobFilter{})
+	assert.Equal(t, 1, jobs.aggregated)
+
+	job, err := repository.CreateJob(ctx, CreateJobBody{Title: "Desenvolvedor Java", Company: "Globex"})
+	assert.NoError(t, err)
+	_, err = repository.TransitionJob(ctx, job.Id, JobTransition{From: JOB_STATUS_PENDING_REVIEW, To: JOB_STATUS_APPROVED, At: time.Now()})
+	assert.NoError(t, err)
+
+	// The stale values are returned at once and refreshed in the background.
+	values, err = repository.GetAggregatedJobsValues(ctx, JobFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Acme"}, values.ToOptions().Companies)
+
+	assert.NoError(t, repository.Shutdown(ctx))
+	assert.Equal(t, 2, jobs.aggregated)
+
+	values, _ = repository.GetAggregatedJobsValues(ctx, JobFilter{})
+	assert.Equal(t, []string{"Acme", "Globex"}, values.ToOptions().Companies)
+	assert.Equal(t, 2, jobs.aggregated)
+
+	// Past the fresh period the values are refreshed too.
+	now := time.Now().Add(config.Default().Cache.JobsAggregatedTtl)
+	repository.now = func() time.Time { return now }
+
+	repository.GetAggregatedJobsValues(ctx, JobFilter{})
+	assert.NoError(t, repository.Shutdown(ctx))
+	assert.Equal(t, 3, jobs.aggregated)
+}
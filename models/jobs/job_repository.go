@@ -3,8 +3,7 @@ package jobs
 import (
 	"context"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -17,117 +16,163 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func GetAggregatedJobsValues(body JobFilter) (JobFilterOptions, error) {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
+const (
+	FACET_COMPANIES              = "companies"
+	FACET_LOCATIONS              = "locations"
+	FACET_PROVIDERS              = "providers"
+	FACET_SALARIES               = "salaries"
+	FACET_CONTRACT_TYPES         = "contract_types"
+	FACET_MODES                  = "modes"
+	FACET_AFFIRMATIVE_PARAMETERS = "affirmative_parameters"
+)
 
-	if err != nil {
-		return JobFilterOptions{}, err
+// FACETS are the filters of the job search answered with their counts, in the
+// order of JobFacets.
+var FACETS = []string{FACET_COMPANIES, FACET_LOCATIONS, FACET_PROVIDERS, FACET_SALARIES, FACET_CONTRACT_TYPES, FACET_MODES, FACET_AFFIRMATIVE_PARAMETERS}
+
+// facetFields are the fields of the facets with a single value per job; the
+// affirmative parameters are one boolean field each.
+var facetFields = map[string]string{
+	FACET_COMPANIES:      "company_name",
+	FACET_LOCATIONS:      "location",
+	FACET_PROVIDERS:      "provider",
+	FACET_SALARIES:       "salary",
+	FACET_CONTRACT_TYPES: "contract_type",
+	FACET_MODES:          "home_office",
+}
+
+// GetAggregatedJobsValues counts the values of each facet in a single $facet
+// aggregation: the jobs matching the filter without its selected values are
+// matched once, then each facet applies the values selected in the others.
+func GetAggregatedJobsValues(ctx context.Context, db *models.DbContext, body JobFilter) (JobFacets, error) {
+
+	facets := bson.M{}
+
+	for _, facet := range FACETS {
+		facets[facet] = facetPipeline(facet, body.JobFilterOptions)
 	}
 
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
+	pipeline := []bson.M{
+		{"$match": getFacetsFilter(body)},
+		{"$facet": facets},
+	}
+
+	cursor, err := db.Collection("jobs").Aggregate(ctx, pipeline)
 
 	if err != nil {
-		return JobFilterOptions{}, err
-	}	
+		return JobFacets{}, err
+	}
 
-	db := client.Database(mongodb_database)
-	collection := db.Collection("jobs")
+	defer cursor.Close(ctx)
 
-	companies, err := GetJobsAggregatedValues(collection, body, "company_name")
-	locations, errLocation := GetJobsAggregatedValues(collection, body, "location")
-	salaries, errSalaries := GetJobsAggregatedValues(collection, body, "salary")
-	providers, errProviders := GetJobsAggregatedValues(collection, body, "provider")
+	var results []JobFacets
 
-	if err != nil || errLocation != nil || errSalaries != nil || errProviders != nil {
-		return JobFilterOptions{}, err
+	if err = cursor.All(ctx, &results); err != nil {
+		return JobFacets{}, err
 	}
 
-	return JobFilterOptions{
-		Companies: companies,
-		Locations: locations,
-		Salaries:  salaries,
-		Providers: providers,
-	}, nil
-}
+	if len(results) == 0 {
+		return JobFacets{}, nil
+	}
 
-func GetJobsAggregatedValues(collection *mongo.Collection, body JobFilter, field string) ([]string, error) {
-	filter := bson.M{}
-	andConditions := []bson.M{}
+	return results[0], nil
+}
 
-	if body.Title != "" {
-		// Split the title by spaces
-		words := strings.Fields(strings.TrimSpace(body.Title))
+func facetPipeline(facet string, options JobFilterOptions) []bson.M {
 
-		// Create a slice of bson.M to store the conditions
-		conditions := make([]bson.M, len(words))
+	pipeline := []bson.M{{"$match": matchAll(facetConditions(options, facet))}}
 
-		// Loop through the words and create a regex condition for each word
-		for i, word := range words {
-			word = commons.HandleValueForRegex(strings.TrimSpace(word))
-			conditions[i] = bson.M{"title": bson.M{"$regex": word, "$options": "i"}}			
+	if field, ok := facetFields[facet]; ok {
+		pipeline = append(pipeline,
+			bson.M{"$match": bson.M{field: bson.M{"$nin": bson.A{nil, ""}}}},
+			bson.M{"$group": bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}},
+		)
+	} else {
+		// One value per affirmative parameter the job has
+		values := bson.A{}
+		for _, parameter := range AFFIRMATIVE_PARAMETERS {
+			values = append(values, bson.M{"$cond": bson.A{"$affirmative_parameters." + parameter, parameter, nil}})
 		}
 
-		// Append the conditions to the andConditions slice
-		andConditions = append(andConditions, bson.M{"$and": conditions})
+		pipeline = append(pipeline,
+			bson.M{"$project": bson.M{"_id": 0, "values": values}},
+			bson.M{"$unwind": "$values"},
+			bson.M{"$match": bson.M{"values": bson.M{"$ne": nil}}},
+			bson.M{"$group": bson.M{"_id": "$values", "count": bson.M{"$sum": 1}}},
+		)
 	}
 
-	andConditions = append(andConditions, bson.M{field: bson.M{"$ne": nil}})
+	return append(pipeline, bson.M{"$sort": bson.M{"_id": 1}})
+}
 
-	filter["$and"] = andConditions
+// getFacetsFilter is the filter of the job search without the values selected
+// in the facets.
+func getFacetsFilter(body JobFilter) bson.M {
+	body.JobFilterOptions = JobFilterOptions{}
+	return getJobsFilter(body)
+}
 
+// facetConditions returns the conditions of the values selected in the
+// facets, except in the excluded one. The values of a facet are alternatives.
+func facetConditions(options JobFilterOptions, excluded string) []bson.M {
 
-	results, err := collection.Distinct(context.Background(), field, filter)
+	conditions := []bson.M{}
 
-	if err != nil {
-		return nil, err
-	}
+	for _, facet := range FACETS {
 
-	options := make([]string, 0, len(results))
+		values := options.selected(facet)
 
-	for _, result := range results {
-		if companyName, ok := result.(string); ok {
-			// check if companyName is null
-			if companyName == "" {
-				continue
-			}
-			options = append(options, companyName)
-		} else {
-			// Handle the case where the result is not a string
-			return nil, fmt.Errorf("unexpected type for company name: %T", result)
+		if facet == excluded || len(values) == 0 {
+			continue
+		}
+
+		if field, ok := facetFields[facet]; ok {
+			conditions = append(conditions, bson.M{field: bson.M{"$in": values}})
+			continue
+		}
+
+		alternatives := []bson.M{}
+		for _, parameter := range values {
+			alternatives = append(alternatives, bson.M{"affirmative_parameters." + parameter: true})
 		}
+		conditions = append(conditions, bson.M{"$or": alternatives})
 	}
 
-	return options, nil	
+	return conditions
 }
 
-func DeleteJob(code string) error {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return err
+func (options JobFilterOptions) selected(facet string) []string {
+	switch facet {
+	case FACET_COMPANIES:
+		return options.Companies
+	case FACET_LOCATIONS:
+		return options.Locations
+	case FACET_PROVIDERS:
+		return options.Providers
+	case FACET_SALARIES:
+		return options.Salaries
+	case FACET_CONTRACT_TYPES:
+		return options.ContractTypes
+	case FACET_MODES:
+		return options.Modes
+	case FACET_AFFIRMATIVE_PARAMETERS:
+		return options.AffirmativeParameters
 	}
+	return nil
+}
 
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
+func matchAll(conditions []bson.M) bson.M {
+	if len(conditions) == 0 {
+		return bson.M{}
+	}
+	return bson.M{"$and": conditions}
+}
 
+func DeleteJob(ctx context.Context, db *models.DbContext, code string) error {
 	filter := bson.M{"code": code}
-	_, err = db.Collection("jobs").DeleteOne(context.Background(), filter)
+	_, err := db.Collection("jobs").DeleteOne(ctx, filter)
 
 	if err != nil {
 		return err
@@ -136,56 +181,42 @@ func DeleteJob(code string) error {
 	return nil
 }
 
-func GetJob(code string) (Job, error) {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return Job{}, err
-	}
-
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
-
+func GetJob(ctx context.Context, db *models.DbContext, code string) (Job, error) {
 	filter := bson.D{{Key: "code", Value: code}}
 	var result Job
-	err = db.Collection("jobs").FindOne(context.Background(), filter).Decode(&result)
+	err := db.Collection("jobs").FindOne(ctx, filter).Decode(&result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return Job{}, nil
 		}
+		return Job{}, err
 	}
 
 	return result, nil	
 }
 
-func CreateJob(body CreateJobBody) (Job, error) {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
+func CreateJob(ctx context.Context, db *models.DbContext, baseUiHost string, body CreateJobBody) (Job, error) {
+	collection := db.Collection("jobs")
+
+	job, err := newJob(baseUiHost, body, func(shortUrl string) (bool, error) {
+		return IsShortUrlAvailable(ctx, db, shortUrl)
+	})
 
 	if err != nil {
 		return Job{}, err
 	}
 
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
+	_, err = collection.InsertOne(ctx, job)
 
 	if err != nil {
 		return Job{}, err
-	}	
+	}
 
-	db := client.Database(mongodb_database)
-	collection := db.Collection("jobs")
+	return job, nil
+}
+
+// newJob builds a job from the request body with a short URL not yet in use.
+func newJob(baseUiHost string, body CreateJobBody, isShortUrlAvailable func(shortUrl string) (bool, error)) (Job, error) {
 	
 	job := Job{
 		Id:          uuid.New().String(),
@@ -198,6 +229,8 @@ func CreateJob(body CreateJobBody) (Job, error) {
 		CreatedAt:   commons.GetBrasiliaTime(),
 		JobDate:     commons.GetBrasiliaTime().Format(time.DateTime),
 		Description: body.Description,
+		ContractType: body.ContractType,
+		Remote:      body.Remote,
 		Creator:     body.Creator,
 		IsApproved:  false,
 		IsClosed:    false,
@@ -209,113 +242,231 @@ func CreateJob(body CreateJobBody) (Job, error) {
 		PostedOnBlueSky:       false,		
 	}
 
-	shortUrl, detailUrl, code := CreateShortUrl()
+	shortUrl, detailUrl, code := CreateShortUrl(baseUiHost)
 
 	for {
-		isAvailable, err := IsShortUrlAvailable(shortUrl)
+		isAvailable, err := isShortUrlAvailable(shortUrl)
 		if err != nil {
-			// handle error
-			log.Fatalf("Error checking URL availability: %v", err)
+			return Job{}, fmt.Errorf("error checking URL availability: %v", err)
 		}
 		if isAvailable {
 			break
 		}
-		shortUrl, detailUrl, code = CreateShortUrl()
+		shortUrl, detailUrl, code = CreateShortUrl(baseUiHost)
 	}	
 
+	status := JOB_STATUS_PENDING_REVIEW
+
+	if body.IsDraft {
+		status = JOB_STATUS_DRAFT
+	}
+
+	job.Status = status
+	job.StatusHistory = []JobTransition{{To: status, By: body.Creator, At: job.CreatedAt}}
+
 	job.Code = code
 	job.JobShortUrl = shortUrl
 	job.JobDetailsUrl = detailUrl
 
 	if body.Provider == "" {
-		job.Provider = "vagasprajr"
+		job.Provider = DEFAULT_PROVIDER
 	}
 
 	if job.Url == "" {
 		job.Url = detailUrl
 	}
 
-	_, err = collection.InsertOne(context.Background(), job)
+	return job, nil
+}
+
+func IsShortUrlAvailable(ctx context.Context, db *models.DbContext, shortUrl string) (bool, error) {
+
+	collection := db.Collection("jobs")
+
+	var result JobItem
+
+	err := collection.FindOne(ctx, bson.M{"job_short_url": shortUrl}).Decode(&result)
 
 	if err != nil {
-		return Job{}, err
+		return true, nil
 	}
 
-	return job, nil
+	return false, nil
 }
 
-func IsShortUrlAvailable(shortUrl string) (bool, error) {
+// EditJob changes the details of the job, recording who changed them. When
+// the transition has a status to move to, the job moves to it in the same
+// update. It returns false when the job is no longer in the status the
+// transition starts from, e.g. when it was changed meanwhile.
+func EditJob(ctx context.Context, db *models.DbContext, id string, body EditJobBody, by primitive.ObjectID, transition JobTransition) (bool, error) {
+	collection := db.Collection("jobs")
 
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
+	filter := statusFilter(transition.From)
+	filter["_id"] = id
 
-	client, err := models.Connect()
+	fields := editFields(body, by)
+	update := bson.M{"$set": fields}
 
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
+	if transition.To != "" {
+		for field, value := range statusFields(transition) {
+			fields[field] = value
 		}
-	}()
+		update["$push"] = bson.M{"status_history": transition}
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
 
 	if err != nil {
 		return false, err
 	}
 
-	collection := client.Database(mongodb_database).Collection("jobs")
+	return result.MatchedCount == 1, nil
+}
+
+// editFields are the fields set on a job by the edit of its details.
+func editFields(body EditJobBody, by primitive.ObjectID) bson.M {
+	return bson.M{
+		"title":         body.Title,
+		"description":   body.Description,
+		"salary":        body.Salary,
+		"location":      body.Location,
+		"url":           body.Url,
+		"contract_type": body.ContractType,
+		"last_update":   commons.GetBrasiliaTime(),
+		"updated_by":    by,
+	}
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// RenewJob moves the expiry date of the approved job and lets the creator be
+// reminded again before the new date. It returns false when the job is no
+// longer approved, e.g. when it expired meanwhile.
+func RenewJob(ctx context.Context, db *models.DbContext, id string, expiresAt time.Time, by primitive.ObjectID) (bool, error) {
+	collection := db.Collection("jobs")
 
-	var result JobItem
+	filter := statusFilter(JOB_STATUS_APPROVED)
+	filter["_id"] = id
 
-	err = collection.FindOne(ctx, bson.M{"job_short_url": shortUrl}).Decode(&result)
+	result, err := collection.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{
+			"expires_at":              expiresAt,
+			"is_expiry_reminder_sent": false,
+			"last_update":             commons.GetBrasiliaTime(),
+			"updated_by":              by,
+		},
+	})
 
 	if err != nil {
-		return true, nil
+		return false, err
 	}
 
-	return false, nil
+	return result.MatchedCount == 1, nil
 }
 
-func UpdateJob(body Job) (Job, error) {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
+// TransitionJob moves the job to the status of the transition, recording it
+// in the history. It returns false when the job is no longer in the status
+// the transition starts from, e.g. when it was changed meanwhile.
+func TransitionJob(ctx context.Context, db *models.DbContext, id string, transition JobTransition) (bool, error) {
+	collection := db.Collection("jobs")
+
+	filter := statusFilter(transition.From)
+	filter["_id"] = id
+
+	result, err := collection.UpdateOne(ctx, filter, bson.M{
+		"$set":  statusFields(transition),
+		"$push": bson.M{"status_history": transition},
+	})
 
 	if err != nil {
-		return Job{}, err
+		return false, err
 	}
 
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
+	return result.ModifiedCount == 1, nil
+}
+
+// CloseExpiredJobs moves the approved jobs whose expiry date has passed to
+// expired, returning how many were. The jobs without an expiry date, created
+// before it existed, never expire.
+func CloseExpiredJobs(ctx context.Context, db *models.DbContext, now time.Time) (int64, error) {
+	collection := db.Collection("jobs")
+
+	transition := JobTransition{From: JOB_STATUS_APPROVED, To: JOB_STATUS_EXPIRED, At: now}
+
+	result, err := collection.UpdateMany(ctx, getExpiredJobsFilter(now), bson.M{
+		"$set":  statusFields(transition),
+		"$push": bson.M{"status_history": transition},
+	})
 
 	if err != nil {
-		return Job{}, err
-	}	
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
 
-	db := client.Database(mongodb_database)
+// GetJobsToRemind returns the approved open jobs expiring until the date
+// whose creators were not reminded yet.
+func GetJobsToRemind(ctx context.Context, db *models.DbContext, until time.Time) ([]Job, error) {
 	collection := db.Collection("jobs")
 
-	filter := bson.M{"_id": body.Id}
+	cursor, err := collection.Find(ctx, getJobsToRemindFilter(until))
 
-	update := bson.M{
-		"$set": bson.M{
-			"is_approved":       body.IsApproved,
-			"is_closed":         body.IsClosed,
-			"updated_at":  commons.GetBrasiliaTime(),
-		},
+	if err != nil {
+		return nil, err
 	}
-	
-	_, err = collection.UpdateOne(context.Background(), filter, update)
+
+	defer cursor.Close(ctx)
+
+	jobs := []Job{}
+
+	if err = cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// ClaimExpiryReminder marks the reminder of the job as sent, returning false
+// when it already was, e.g. by another instance of the API.
+func ClaimExpiryReminder(ctx context.Context, db *models.DbContext, id string) (bool, error) {
+	collection := db.Collection("jobs")
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "is_expiry_reminder_sent": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"is_expiry_reminder_sent": true}},
+	)
 
 	if err != nil {
-		return Job{}, err
+		return false, err
 	}
 
-	return body, nil
+	return result.ModifiedCount == 1, nil
+}
+
+// ReleaseExpiryReminder gives back the claim of a reminder that could not be
+// sent, so the next run sends it again.
+func ReleaseExpiryReminder(ctx context.Context, db *models.DbContext, id string) error {
+	collection := db.Collection("jobs")
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"is_expiry_reminder_sent": false}})
+
+	return err
+}
+
+func getExpiredJobsFilter(now time.Time) bson.M {
+
+	filter := statusFilter(JOB_STATUS_APPROVED)
+	filter["expires_at"] = bson.M{"$gt": time.Time{}, "$lte": now}
+
+	return filter
+}
+
+func getJobsToRemindFilter(until time.Time) bson.M {
+
+	filter := statusFilter(JOB_STATUS_APPROVED)
+	filter["is_expiry_reminder_sent"] = bson.M{"$ne": true}
+	filter["expires_at"] = bson.M{"$gt": time.Time{}, "$lte": until}
+
+	return filter
 }
 
 func GenerateCode() string {
@@ -337,11 +488,11 @@ func GenerateCode() string {
 	return code
 }
 
-func CreateShortUrl() (string, string, string) {
+func CreateShortUrl(baseUiHost string) (string, string, string) {
  	
 	code := GenerateCode()
 
-	return os.Getenv("BASE_UI_HOST")+`/go/` + code, os.Getenv("BASE_UI_HOST")+`/v/` + code, code
+	return baseUiHost+`/go/` + code, baseUiHost+`/v/` + code, code
 }
 
 func GetValidationToken() string {
@@ -361,97 +512,130 @@ func GetValidationToken() string {
 	return string(b)
 }
 
-func GetJobsAsAdmin(filter commons.FilterRequest) (JobsPaginatedResult, error) {
+func GetJobsAsAdmin(ctx context.Context, db *models.DbContext, filter commons.FilterRequest) (JobsPaginatedResult, error) {
+	return getJobsPage(ctx, db, filter.GetFilter(), filter)
+}
+
+// GetJobsByCreator lists the jobs posted by the user, whatever their status.
+func GetJobsByCreator(ctx context.Context, db *models.DbContext, creator primitive.ObjectID, filter commons.FilterRequest) (JobsPaginatedResult, error) {
+	return getJobsPage(ctx, db, getCreatorJobsFilter(creator, filter), filter)
+}
+
+func getCreatorJobsFilter(creator primitive.ObjectID, filter commons.FilterRequest) bson.M {
+	return bson.M{"$and": []bson.M{{"creator": creator}, filter.GetFilter()}}
+}
+
+func getJobsPage(ctx context.Context, db *models.DbContext, jobsFilter bson.M, filter commons.FilterRequest) (JobsPaginatedResult, error) {
 
 	collection:= "jobs"
 
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
+	query, err := commons.NewPageQuery(filter.Sort, filter.IsAscending, filter.Page, filter.PageSize, filter.Cursor)
 
 	if err != nil {
 		return JobsPaginatedResult{}, err
 	}
 
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
+	cursor, err := db.Collection(collection).Find(ctx, query.Filter(jobsFilter), query.FindOptions())
 
-	db := client.Database(mongodb_database)
-
-	page:= filter.Page
-	perPage:= filter.PageSize
-
-	if (page - 1) < 0 {
-		page = 1
+	if err != nil {
+		return JobsPaginatedResult{}, err
 	}
 
-	skip := (page - 1) * perPage
-
-	if filter.Sort == "" {
-		filter.Sort = "created_at"
-	}
+	defer cursor.Close(ctx)
 
-	orderDirection := -1
+	var documents []bson.M
 
-	if filter.IsAscending {
-		orderDirection = 1
+	if err = cursor.All(ctx, &documents); err != nil {
+		return JobsPaginatedResult{}, err
 	}
 
-	options := options.Find().SetSort(bson.M{filter.Sort: orderDirection}).SetSkip(int64(skip)).SetLimit(int64(perPage))
-
-	cursor, err := db.Collection(collection).Find(context.Background(), filter.GetFilter(), options)
+	jobs, cursors, err := commons.DecodePage[Job](query, documents)
 
 	if err != nil {
 		return JobsPaginatedResult{}, err
 	}
 
-	var jobs []Job
+	total := int64(commons.NO_TOTAL)
 
-	if err = cursor.All(context.Background(), &jobs); err != nil {
-		return JobsPaginatedResult{}, err
+	if !filter.SkipTotal {
+		if total, err = db.Collection(collection).CountDocuments(ctx, jobsFilter); err != nil {
+			return JobsPaginatedResult{}, err
+		}
 	}
 
-	total, err := db.Collection(collection).CountDocuments(context.Background(), filter.GetFilter())
-
 	return JobsPaginatedResult{
 		Total:   total,
-		Page:    page,
-		PerPage: perPage,
+		Page:    query.Page,
+		PerPage: query.PageSize,
 		Data:    jobs,
+		NextCursor: cursors.Next,
+		PrevCursor: cursors.Prev,
 	}, nil		
 }
 
 
-func GetJobs(body JobFilter) (PaginatedResult, error) {
+func GetJobs(ctx context.Context, db *models.DbContext, body JobFilter) (PaginatedResult, error) {
+
+	collection := db.Collection("jobs")
+
+	filter := getJobsFilter(body)
 
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
+	query, err := commons.NewPageQuery(body.Sort, body.IsAscending, body.Page, body.PageSize, body.Cursor)
 
 	if err != nil {
 		return PaginatedResult{}, err
 	}
 
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
+	cursor, err := collection.Find(ctx, query.Filter(filter), query.FindOptions())
 
 	if err != nil {
 		return PaginatedResult{}, err
-	}	
+	}
 
-	db := client.Database(mongodb_database)
-	collection := db.Collection("jobs")
+	defer cursor.Close(ctx)
+
+	var documents []bson.M
+
+	if err = cursor.All(ctx, &documents); err != nil {
+		return PaginatedResult{}, err
+	}
+
+	jobs, cursors, err := commons.DecodePage[JobViewPublic](query, documents)
+
+	if err != nil {
+		return PaginatedResult{}, err
+	}
+
+	total := int64(commons.NO_TOTAL)
+
+	// Counting is the most expensive part of the search; the infinite scroll
+	// of the UI only needs the cursors
+	if !body.SkipTotal {
+		if total, err = collection.CountDocuments(ctx, filter); err != nil {
+			return PaginatedResult{}, err
+		}
+	}
+
+	return PaginatedResult{
+		Total:   total,
+		Page:    query.Page,
+		PerPage: query.PageSize,
+		Data:    jobs,
+		NextCursor: cursors.Next,
+		PrevCursor: cursors.Prev,
+	}, nil
+}
+
+func getJobsFilter(body JobFilter) bson.M {
 
 	filter := bson.M{}
 	andConditions := []bson.M{}
 
 	if body.Ids != nil && len(body.Ids) > 0 {
+		// The jobs asked by id, such as the bookmarks, are still listed once
+		// closed or expired; only the unpublished ones are left out
 		filter["_id"] = bson.M{"$in": body.Ids}
+		filter["$or"] = bson.A{statusFilter(JOB_STATUS_APPROVED), statusFilter(JOB_STATUS_CLOSED), statusFilter(JOB_STATUS_EXPIRED)}
 	} else {
 		if body.Title != "" {
 				// Split the title by spaces
@@ -470,133 +654,52 @@ func GetJobs(body JobFilter) (PaginatedResult, error) {
 				andConditions = append(andConditions, bson.M{"$and": conditions})
 			}	
 
+			if strings.TrimSpace(body.Search) != "" {
+				filter["$text"] = TEXT_INDEX.Search(body.Search)
+			}
+
 			andConditions = appendCondition(andConditions, "company_name", body.Company)
 			andConditions = appendCondition(andConditions, "location", body.Location)
 			andConditions = appendCondition(andConditions, "salary", body.Salary)
 			andConditions = appendCondition(andConditions, "provider", body.Provider)
 
 			andConditions = appendInCondition(andConditions, "_id", body.Ids)
-			andConditions = appendInCondition(andConditions, "company_name", body.JobFilterOptions.Companies)
-			andConditions = appendInCondition(andConditions, "location", body.JobFilterOptions.Locations)
-			andConditions = appendInCondition(andConditions, "provider", body.JobFilterOptions.Providers)
-			andConditions = appendInCondition(andConditions, "salary", body.JobFilterOptions.Salaries)
+			andConditions = append(andConditions, facetConditions(body.JobFilterOptions, "")...)
 
 			if body.CreatorId != primitive.NilObjectID {
 				andConditions = append(andConditions, bson.M{"creator": body.CreatorId})
 			}	
 
-			if len(andConditions) > 0 {
-				filter["$and"] = andConditions
-			}
+			// The public search only sees the approved jobs
+			filter["$and"] = append(andConditions, statusFilter(JOB_STATUS_APPROVED))
 	}	
 
-	page := body.Page
-	perPage := body.PageSize
-
-	if (page - 1) < 0 {
-		page = 1
-	}
-
-	if body.Sort == "" {
-		body.Sort = "created_at"
-	}
-
-	orderDirection := -1
-
-	if body.IsAscending {
-		orderDirection = 1
-	}
-
-	skip := (page - 1) * perPage
-		
-	options := options.Find().SetSort(bson.M{body.Sort: orderDirection}).SetSkip(int64(skip)).SetLimit(int64(perPage))
-	cursor, err := collection.Find(context.Background(), filter, options)
-
-	if err != nil {
-		return PaginatedResult{}, err
-	}
-
-	var jobs []JobViewPublic
-
-	if err = cursor.All(context.Background(), &jobs); err != nil {
-		return PaginatedResult{}, err
-	}
-
-	total, err := collection.CountDocuments(context.Background(), filter)
-
-	if err != nil {
-		return PaginatedResult{}, err
-	}
-
-	defer cursor.Close(context.Background())
-
-	return PaginatedResult{
-		Total:   total,
-		Page:    page,
-		PerPage: perPage,
-		Data:    jobs,
-	}, nil
+	return filter
 }
 
-func GetOriginalURL(shortUrl string) (string, error) {
-
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-
-	client, err := models.Connect()
-
-	if err != nil {
-		return "", err
-	}
-
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	collection := client.Database(mongodb_database).Collection("jobs")
+func GetOriginalURL(ctx context.Context, db *models.DbContext, baseUiHost string, shortUrl string) (string, error) {
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	collection := db.Collection("jobs")
 
 	var result JobItem
 
-	log.Print("[MONGODB]: Searching for: ", shortUrl)
+	slog.DebugContext(ctx, "Searching for the short URL", "short_url", shortUrl)
 
-	err = collection.FindOne(ctx, bson.M{"job_short_url": shortUrl, "is_approved": true, "is_closed": false }).Decode(&result)
+	err := collection.FindOne(ctx, bson.M{"job_short_url": shortUrl, "is_approved": true, "is_closed": false }).Decode(&result)
 
 	if err != nil {
 		code := shortUrl[len(shortUrl)-6:]
-		return os.Getenv("BASE_UI_HOST")+`/vagas/` + code, err
+		return baseUiHost+`/vagas/` + code, err
 	}
 
 	return result.Url, nil
 }
 
-func UpdateJobClicks(shortUrl string) error {
-
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-
-	client, err := models.Connect()
-
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
+func UpdateJobClicks(ctx context.Context, db *models.DbContext, shortUrl string) error {
 
-	if err != nil {
-		return err
-	}
-
-	collection := client.Database(mongodb_database).Collection("jobs")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	collection := db.Collection("jobs")
 
-	_, err = collection.UpdateOne(ctx, bson.M{"job_short_url": shortUrl}, bson.M{"$inc": bson.M{"qty_clicks": 1}})
+	_, err := collection.UpdateOne(ctx, bson.M{"job_short_url": shortUrl}, bson.M{"$inc": bson.M{"qty_clicks": 1}})
 
 	if err != nil {
 		return err
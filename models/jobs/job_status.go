@@ -0,0 +1,168 @@
+package jobs
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	JOB_STATUS_DRAFT          = "draft"
+	JOB_STATUS_PENDING_REVIEW = "pending_review"
+	JOB_STATUS_APPROVED       = "approved"
+	JOB_STATUS_REJECTED       = "rejected"
+	JOB_STATUS_CLOSED         = "closed"
+	JOB_STATUS_EXPIRED        = "expired"
+)
+
+// JOB_TRANSITIONS are the statuses a job can move to from each status. A
+// rejected job goes back to review once fixed, as does an approved one edited
+// by its creator, and an expired one is approved again when renewed. Closed
+// is final.
+var JOB_TRANSITIONS = map[string][]string{
+	JOB_STATUS_DRAFT:          {JOB_STATUS_PENDING_REVIEW, JOB_STATUS_CLOSED},
+	JOB_STATUS_PENDING_REVIEW: {JOB_STATUS_APPROVED, JOB_STATUS_REJECTED, JOB_STATUS_CLOSED},
+	JOB_STATUS_APPROVED:       {JOB_STATUS_PENDING_REVIEW, JOB_STATUS_CLOSED, JOB_STATUS_EXPIRED},
+	JOB_STATUS_REJECTED:       {JOB_STATUS_PENDING_REVIEW, JOB_STATUS_CLOSED},
+	JOB_STATUS_EXPIRED:        {JOB_STATUS_APPROVED, JOB_STATUS_CLOSED},
+	JOB_STATUS_CLOSED:         {},
+}
+
+// JobTransition is an entry of the status history of a job. By is empty for
+// the transitions made by the API itself, such as the expiry. ExpiresAt, not
+// recorded in the history, is the expiry date an approval gives the job.
+type JobTransition struct {
+	From      string             `json:"from" bson:"from"`
+	To        string             `json:"to" bson:"to"`
+	Reason    string             `json:"reason,omitempty" bson:"reason,omitempty"`
+	By        primitive.ObjectID `json:"by" bson:"by"`
+	At        time.Time          `json:"at" bson:"at"`
+	ExpiresAt time.Time          `json:"-" bson:"-"`
+}
+
+// ChangeJobStatusBody moves a job to another status; rejecting it requires
+// the reason, which is sent to the creator.
+type ChangeJobStatusBody struct {
+	Status string `json:"status" binding:"required,oneof=pending_review approved rejected closed"`
+	Reason string `json:"reason" binding:"max=1000"`
+}
+
+// CanTransition tells whether a job in the status can move to the other.
+func CanTransition(from string, to string) bool {
+	for _, status := range JOB_TRANSITIONS[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CurrentStatus returns the status of the job, derived from is_approved and
+// is_closed for the jobs stored before the status existed.
+func (job Job) CurrentStatus() string {
+
+	switch {
+	case job.Status != "":
+		return job.Status
+	case job.IsClosed && !job.ExpiresAt.IsZero() && !job.ClosedAt.Before(job.ExpiresAt):
+		return JOB_STATUS_EXPIRED
+	case job.IsClosed:
+		return JOB_STATUS_CLOSED
+	case job.IsApproved:
+		return JOB_STATUS_APPROVED
+	}
+
+	return JOB_STATUS_PENDING_REVIEW
+}
+
+// statusFilter matches the jobs in the status, including the ones stored
+// before the status existed whose is_approved and is_closed give it, as
+// CurrentStatus derives it.
+func statusFilter(status string) bson.M {
+
+	expired := bson.M{"$and": bson.A{
+		bson.M{"$gt": bson.A{"$expires_at", time.Time{}}},
+		bson.M{"$gte": bson.A{"$closed_at", "$expires_at"}},
+	}}
+
+	legacy := bson.M{"status": bson.M{"$in": bson.A{nil, ""}}}
+
+	switch status {
+	case JOB_STATUS_EXPIRED:
+		legacy["is_closed"], legacy["$expr"] = true, expired
+	case JOB_STATUS_CLOSED:
+		legacy["is_closed"], legacy["$expr"] = true, bson.M{"$not": bson.A{expired}}
+	case JOB_STATUS_APPROVED:
+		legacy["is_closed"], legacy["is_approved"] = bson.M{"$ne": true}, true
+	case JOB_STATUS_PENDING_REVIEW:
+		legacy["is_closed"], legacy["is_approved"] = bson.M{"$ne": true}, bson.M{"$ne": true}
+	default:
+		return bson.M{"status": status}
+	}
+
+	return bson.M{"$or": bson.A{bson.M{"status": status}, legacy}}
+}
+
+// IsExpired tells whether the job was closed by reaching its expiry date,
+// rather than by its creator or an admin.
+func (job Job) IsExpired() bool {
+	return job.CurrentStatus() == JOB_STATUS_EXPIRED
+}
+
+// apply moves the job to the status of the transition, keeping is_approved
+// and is_closed, still read by the listings and the short links, in sync.
+func (job *Job) apply(transition JobTransition) {
+
+	for field, value := range statusFields(transition) {
+		switch field {
+		case "status":
+			job.Status = value.(string)
+		case "is_approved":
+			job.IsApproved = value.(bool)
+		case "is_closed":
+			job.IsClosed = value.(bool)
+		case "closed_at":
+			job.ClosedAt = value.(time.Time)
+		case "rejection_reason":
+			job.RejectionReason = value.(string)
+		case "last_update":
+			job.LastUpdate = value.(time.Time)
+		case "updated_by":
+			job.UdatedBy = value.(primitive.ObjectID)
+		case "expires_at":
+			job.ExpiresAt = value.(time.Time)
+		case "is_expiry_reminder_sent":
+			job.IsExpiryReminderSent = value.(bool)
+		}
+	}
+
+	job.StatusHistory = append(job.StatusHistory, transition)
+}
+
+// statusFields are the fields set on a job by the transition.
+func statusFields(transition JobTransition) bson.M {
+
+	fields := bson.M{
+		"status":           transition.To,
+		"rejection_reason": "",
+		"last_update":      transition.At,
+		"updated_by":       transition.By,
+	}
+
+	switch transition.To {
+	case JOB_STATUS_APPROVED:
+		fields["is_approved"], fields["is_closed"], fields["closed_at"] = true, false, time.Time{}
+		if !transition.ExpiresAt.IsZero() {
+			fields["expires_at"], fields["is_expiry_reminder_sent"] = transition.ExpiresAt, false
+		}
+	case JOB_STATUS_CLOSED, JOB_STATUS_EXPIRED:
+		fields["is_closed"], fields["closed_at"] = true, transition.At
+	case JOB_STATUS_REJECTED:
+		fields["is_approved"], fields["is_closed"], fields["rejection_reason"] = false, false, transition.Reason
+	default:
+		fields["is_approved"], fields["is_closed"] = false, false
+	}
+
+	return fields
+}
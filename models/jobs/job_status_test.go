@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusOfJobsWithoutStatus(t *testing.T) {
+	expiresAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		job      Job
+		expected string
+	}{
+		{"no flags", Job{}, JOB_STATUS_PENDING_REVIEW},
+		{"approved", Job{IsApproved: true}, JOB_STATUS_APPROVED},
+		{"approved with an expiry date", Job{IsApproved: true, ExpiresAt: expiresAt}, JOB_STATUS_APPROVED},
+		{"closed", Job{IsClosed: true}, JOB_STATUS_CLOSED},
+		{"approved and closed", Job{IsApproved: true, IsClosed: true, ClosedAt: expiresAt}, JOB_STATUS_CLOSED},
+		{"closed before the expiry date", Job{IsApproved: true, IsClosed: true, ExpiresAt: expiresAt, ClosedAt: expiresAt.Add(-time.Hour)}, JOB_STATUS_CLOSED},
+		{"closed on the expiry date", Job{IsApproved: true, IsClosed: true, ExpiresAt: expiresAt, ClosedAt: expiresAt}, JOB_STATUS_EXPIRED},
+		{"closed after the expiry date", Job{IsApproved: true, IsClosed: true, ExpiresAt: expiresAt, ClosedAt: expiresAt.Add(time.Hour)}, JOB_STATUS_EXPIRED},
+		{"closed without a date", Job{IsClosed: true, ExpiresAt: expiresAt}, JOB_STATUS_CLOSED},
+		{"with a status", Job{Status: JOB_STATUS_DRAFT, IsApproved: true}, JOB_STATUS_DRAFT},
+		{"rejected", Job{Status: JOB_STATUS_REJECTED}, JOB_STATUS_REJECTED},
+	}
+
+	statuses := []string{JOB_STATUS_DRAFT, JOB_STATUS_PENDING_REVIEW, JOB_STATUS_APPROVED, JOB_STATUS_REJECTED, JOB_STATUS_CLOSED, JOB_STATUS_EXPIRED}
+
+	for _, test := range cases {
+		assert.Equal(t, test.expected, test.job.CurrentStatus(), test.name)
+
+		document, err := commons.ToDocument(test.job)
+		assert.NoError(t, err)
+
+		// The filters of the repositories agree with CurrentStatus
+		for _, status := range statuses {
+			assert.Equal(t, status == test.expected, commons.MatchFilter(document, statusFilter(status)), "%s as %s", test.name, status)
+		}
+	}
+}
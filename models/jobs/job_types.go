@@ -3,14 +3,59 @@ package jobs
 import (
 	"time"
 
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	CONTRACT_TYPE_CLT        = "clt"
+	CONTRACT_TYPE_PJ         = "pj"
+	CONTRACT_TYPE_INTERNSHIP = "estagio"
+	CONTRACT_TYPE_TRAINEE    = "trainee"
+	CONTRACT_TYPE_TEMPORARY  = "temporario"
+	CONTRACT_TYPE_FREELANCER = "freelancer"
+
+	JOB_MODE_REMOTE = "remoto"
+	JOB_MODE_HYBRID = "hibrido"
+	JOB_MODE_ONSITE = "presencial"
+
+	// DEFAULT_PROVIDER is the provider of the jobs published on the site.
+	DEFAULT_PROVIDER = "vagasprajr"
+)
+
+// CONTRACT_TYPES and JOB_MODES are the values accepted for the contract type
+// and the mode (home_office) of a job.
+var CONTRACT_TYPES = []string{CONTRACT_TYPE_CLT, CONTRACT_TYPE_PJ, CONTRACT_TYPE_INTERNSHIP, CONTRACT_TYPE_TRAINEE, CONTRACT_TYPE_TEMPORARY, CONTRACT_TYPE_FREELANCER}
+var JOB_MODES = []string{JOB_MODE_REMOTE, JOB_MODE_HYBRID, JOB_MODE_ONSITE}
+
+// AFFIRMATIVE_PARAMETERS are the fields of the affirmative parameters of a job.
+var AFFIRMATIVE_PARAMETERS = []string{"is_black_person", "is_women", "is_lgbtqia", "is_indigenous", "is_person_with_disabilities"}
+
+// TEXT_INDEX is the text index of the job search, weighting a word found in the
+// title over one found in the company, the location or the description.
+var TEXT_INDEX = commons.TextIndex{
+	Fields: []commons.TextField{
+		{Name: "title", Weight: 10},
+		{Name: "company_name", Weight: 5},
+		{Name: "location", Weight: 3},
+		{Name: "description", Weight: 1},
+	},
+	Language: "portuguese",
+}
+
 type PaginatedResult struct {
 	Total   int64 // Total number of filtered documents
 	Page    int
 	PerPage int
 	Data    []JobViewPublic // Filtered documents
+	NextCursor string `json:",omitempty"` // Cursor of the next page, if any
+	PrevCursor string `json:",omitempty"` // Cursor of the previous page, if any
+}
+
+// ToPage returns the result in the envelope of the v2 API.
+func (result PaginatedResult) ToPage() commons.Page[JobViewPublic] {
+	return commons.NewPage(result.Data, result.Total, result.Page, result.PerPage).
+		WithCursors(commons.Cursors{Next: result.NextCursor, Prev: result.PrevCursor})
 }
 
 type UpdateJobBody struct {	
@@ -18,6 +63,16 @@ type UpdateJobBody struct {
 	IsClosed     bool   `json:"is_closed" bson:"is_closed"`
 }
 
+// EditJobBody is the details of a job its creator can change after posting it.
+type EditJobBody struct {
+	Title        string `json:"title" bson:"title" binding:"required,max=200"`
+	Description  string `json:"description" bson:"description" binding:"max=20000"`
+	Salary       string `json:"salary" bson:"salary" binding:"max=100"`
+	Location     string `json:"location" bson:"location" binding:"max=200"`
+	Url          string `json:"url" bson:"url" binding:"required,http_url,max=2048"`
+	ContractType string `json:"contract_type" bson:"contract_type" binding:"omitempty,contract_type"`
+}
+
 type JobViewPublic struct {
 	Id          	string    `json:"id" bson:"_id"`
 	Title       	string    `json:"title" bson:"title"`
@@ -36,20 +91,23 @@ type JobViewPublic struct {
 }
 
 type JobFilter struct {
-	Page      int                		`json:"page" bson:"page"`
-	PageSize  int                		`json:"pageSize" bson:"pageSize"`
-	Title     string             		`json:"title" bson:"title"`
-	Company   string             		`json:"company_name" bson:"company_name"`
-	Location  string             		`json:"location" bson:"location"`
-	Salary    string             		`json:"salary" bson:"salary"`
-	Provider  string             		`json:"provider" bson:"provider"`
-	Ids       []string           		`json:"ids" bson:"ids"`
+	Page      int                		`json:"page" bson:"page" binding:"min=0"`
+	PageSize  int                		`json:"pageSize" bson:"pageSize" binding:"min=0,max=100"`
+	Title     string             		`json:"title" bson:"title" binding:"max=200"`
+	Company   string             		`json:"company_name" bson:"company_name" binding:"max=200"`
+	Location  string             		`json:"location" bson:"location" binding:"max=200"`
+	Salary    string             		`json:"salary" bson:"salary" binding:"max=100"`
+	Provider  string             		`json:"provider" bson:"provider" binding:"max=100"`
+	Ids       []string           		`json:"ids" bson:"ids" binding:"max=100"`
 	CreatorId primitive.ObjectID 		`json:"creator_id" bson:"creator_id"`
 	JobFilterOptions JobFilterOptions 	`json:"job_filter_options" bson:"job_filter_options"`
 	IsClosed   bool				        `json:"is_closed" bson:"is_closed"`
 	IsApproved bool                     `json:"is_approved" bson:"is_approved"`
-	Sort 	   string             		`json:"sort" bson:"sort"`
+	Sort 	   string             		`json:"sort" bson:"sort" binding:"max=100,excludesall=$"`
 	IsAscending bool            		`json:"is_ascending" bson:"is_ascending"`
+	Cursor     string             		`json:"cursor" bson:"cursor" binding:"max=1024"`
+	SkipTotal  bool               		`json:"skip_total" bson:"skip_total"`
+	Search     string             		`json:"search" bson:"search" binding:"max=200"`
 }
 
 type CustomJobFilter struct {	
@@ -59,10 +117,57 @@ type CustomJobFilter struct {
 }
 
 type JobFilterOptions struct {
-	Companies []string           `json:"companies" bson:"companies"`
-	Locations []string           `json:"locations" bson:"locations"`
-	Providers []string           `json:"providers" bson:"providers"`
-	Salaries  []string           `json:"salaries" bson:"salaries"`
+	Companies             []string `json:"companies" bson:"companies"`
+	Locations             []string `json:"locations" bson:"locations"`
+	Providers             []string `json:"providers" bson:"providers"`
+	Salaries              []string `json:"salaries" bson:"salaries"`
+	ContractTypes         []string `json:"contract_types" bson:"contract_types" binding:"max=10,dive,contract_type"`
+	Modes                 []string `json:"modes" bson:"modes" binding:"max=10,dive,job_mode"`
+	AffirmativeParameters []string `json:"affirmative_parameters" bson:"affirmative_parameters" binding:"max=10,dive,oneof=is_black_person is_women is_lgbtqia is_indigenous is_person_with_disabilities"`
+}
+
+// FacetValue is a value of a filter of the job search with the number of jobs
+// having it.
+type FacetValue struct {
+	Value string `json:"value" bson:"_id"`
+	Count int64  `json:"count" bson:"count"`
+}
+
+// JobFacets are the values of the filters of the job search with their
+// counts. The counts of each facet take into account the values selected in
+// the other facets only, so selecting a value does not hide the others of the
+// same facet.
+type JobFacets struct {
+	Companies             []FacetValue `json:"companies" bson:"companies"`
+	Locations             []FacetValue `json:"locations" bson:"locations"`
+	Providers             []FacetValue `json:"providers" bson:"providers"`
+	Salaries              []FacetValue `json:"salaries" bson:"salaries"`
+	ContractTypes         []FacetValue `json:"contract_types" bson:"contract_types"`
+	Modes                 []FacetValue `json:"modes" bson:"modes"`
+	AffirmativeParameters []FacetValue `json:"affirmative_parameters" bson:"affirmative_parameters"`
+}
+
+// ToOptions returns the values of the facets without their counts, as
+// answered by v1.
+func (facets JobFacets) ToOptions() JobFilterOptions {
+
+	values := func(facet []FacetValue) []string {
+		result := make([]string, 0, len(facet))
+		for _, value := range facet {
+			result = append(result, value.Value)
+		}
+		return result
+	}
+
+	return JobFilterOptions{
+		Companies:             values(facets.Companies),
+		Locations:             values(facets.Locations),
+		Providers:             values(facets.Providers),
+		Salaries:              values(facets.Salaries),
+		ContractTypes:         values(facets.ContractTypes),
+		Modes:                 values(facets.Modes),
+		AffirmativeParameters: values(facets.AffirmativeParameters),
+	}
 }
 
 type JobItem struct {
@@ -72,16 +177,19 @@ type JobItem struct {
 
 type CreateJobBody struct {
 	Id 			string			 	`json:"id" bson:"_id"`
-	Title 		string 				`json:"title" bson:"title"`
-	Company 	string 				`json:"company_name" bson:"company_name"`
-	Location 	string 				`json:"location" bson:"location"`
-	Url 		string 				`json:"url" bson:"url"`
-	Salary 		string 				`json:"salary" bson:"salary"`
-	Provider 	string 				`json:"provider" bson:"provider"`
+	Title 		string 				`json:"title" bson:"title" binding:"required,max=200"`
+	Company 	string 				`json:"company_name" bson:"company_name" binding:"required,max=200"`
+	Location 	string 				`json:"location" bson:"location" binding:"max=200"`
+	Url 		string 				`json:"url" bson:"url" binding:"required,http_url,max=2048"`
+	Salary 		string 				`json:"salary" bson:"salary" binding:"max=100"`
+	Provider 	string 				`json:"provider" bson:"provider" binding:"max=100"`
 	Created_at 	time.Time 			`json:"created_at" bson:"created_at"`	
 	Code 		string 				`json:"code" bson:"code"`
 	Creator		primitive.ObjectID  `json:"creator" bson:"creator"`
-	Description string              `json:"description" bson:"description"`
+	Description string              `json:"description" bson:"description" binding:"max=20000"`
+	ContractType string             `json:"contract_type" bson:"contract_type" binding:"omitempty,contract_type"`
+	Remote      string              `json:"home_office" bson:"home_office" binding:"omitempty,job_mode"`
+	IsDraft     bool                `json:"is_draft" bson:"-"` // Saved as a draft instead of sent to review
 }
 
 type JobsPaginatedResult struct {
@@ -89,6 +197,13 @@ type JobsPaginatedResult struct {
 	Page    int
 	PerPage int
 	Data    []Job
+	NextCursor string `json:",omitempty"` // Cursor of the next page, if any
+	PrevCursor string `json:",omitempty"` // Cursor of the previous page, if any
+}
+
+func (result JobsPaginatedResult) ToPage() commons.Page[Job] {
+	return commons.NewPage(result.Data, result.Total, result.Page, result.PerPage).
+		WithCursors(commons.Cursors{Next: result.NextCursor, Prev: result.PrevCursor})
 }
 
 type Job struct {
@@ -123,6 +238,11 @@ type Job struct {
 	ContractType          string                  `json:"contract_type" bson:"contract_type"`
 	LastUpdate            time.Time               `json:"last_update" bson:"last_update"`
 	UdatedBy              primitive.ObjectID      `json:"updated_by" bson:"updated_by"`
+	ExpiresAt             time.Time               `json:"expires_at" bson:"expires_at"`
+	IsExpiryReminderSent  bool                    `json:"is_expiry_reminder_sent" bson:"is_expiry_reminder_sent"`
+	Status                string                  `json:"status" bson:"status"`
+	RejectionReason       string                  `json:"rejection_reason,omitempty" bson:"rejection_reason,omitempty"`
+	StatusHistory         []JobTransition         `json:"status_history" bson:"status_history"`
 }
 
 type AffirmativeJobParameter struct {
@@ -0,0 +1,393 @@
+package jobs
+
+import (
+	"context"
+
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// InMemoryJobRepository keeps the jobs in memory, applying the same filters,
+// sorting and pagination as MongoJobRepository. It is meant for tests.
+type InMemoryJobRepository struct {
+	mutex      sync.Mutex
+	baseUiHost string
+	jobs       []Job
+}
+
+func NewInMemoryJobRepository(baseUiHost string, jobs ...Job) *InMemoryJobRepository {
+
+	jobs = append([]Job{}, jobs...)
+
+	// The jobs without a status get it as the migration gives it
+	for i := range jobs {
+		jobs[i].Status = jobs[i].CurrentStatus()
+	}
+
+	return &InMemoryJobRepository{baseUiHost: baseUiHost, jobs: jobs}
+}
+
+func (repository *InMemoryJobRepository) GetJobs(ctx context.Context, body JobFilter) (PaginatedResult, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	query, err := commons.NewPageQuery(body.Sort, body.IsAscending, body.Page, body.PageSize, body.Cursor)
+
+	if err != nil {
+		return PaginatedResult{}, err
+	}
+
+	documents, total, err := commons.FindTextPage(repository.jobs, getJobsFilter(body), query, TEXT_INDEX)
+
+	if err != nil {
+		return PaginatedResult{}, err
+	}
+
+	items, cursors, err := commons.DecodePage[JobViewPublic](query, documents)
+
+	if err != nil {
+		return PaginatedResult{}, err
+	}
+
+	if body.SkipTotal {
+		total = commons.NO_TOTAL
+	}
+
+	return PaginatedResult{
+		Total:      int64(total),
+		Page:       query.Page,
+		PerPage:    query.PageSize,
+		Data:       items,
+		NextCursor: cursors.Next,
+		PrevCursor: cursors.Prev,
+	}, nil
+}
+
+func (repository *InMemoryJobRepository) GetJobsAsAdmin(ctx context.Context, filter commons.FilterRequest) (JobsPaginatedResult, error) {
+	return repository.getJobsPage(filter.GetFilter(), filter)
+}
+
+func (repository *InMemoryJobRepository) GetJobsByCreator(ctx context.Context, creator primitive.ObjectID, filter commons.FilterRequest) (JobsPaginatedResult, error) {
+	return repository.getJobsPage(getCreatorJobsFilter(creator, filter), filter)
+}
+
+func (repository *InMemoryJobRepository) getJobsPage(jobsFilter bson.M, filter commons.FilterRequest) (JobsPaginatedResult, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	query, err := commons.NewPageQuery(filter.Sort, filter.IsAscending, filter.Page, filter.PageSize, filter.Cursor)
+
+	if err != nil {
+		return JobsPaginatedResult{}, err
+	}
+
+	documents, total, err := commons.FindPage(repository.jobs, jobsFilter, query)
+
+	if err != nil {
+		return JobsPaginatedResult{}, err
+	}
+
+	items, cursors, err := commons.DecodePage[Job](query, documents)
+
+	if err != nil {
+		return JobsPaginatedResult{}, err
+	}
+
+	if filter.SkipTotal {
+		total = commons.NO_TOTAL
+	}
+
+	return JobsPaginatedResult{
+		Total:      int64(total),
+		Page:       query.Page,
+		PerPage:    query.PageSize,
+		Data:       items,
+		NextCursor: cursors.Next,
+		PrevCursor: cursors.Prev,
+	}, nil
+}
+
+func (repository *InMemoryJobRepository) GetAggregatedJobsValues(ctx context.Context, body JobFilter) (JobFacets, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	documents, err := commons.FindTextDocuments(repository.jobs, getFacetsFilter(body), TEXT_INDEX)
+
+	if err != nil {
+		return JobFacets{}, err
+	}
+
+	facets := make(map[string][]FacetValue, len(FACETS))
+
+	for _, facet := range FACETS {
+		filter := matchAll(facetConditions(body.JobFilterOptions, facet))
+		counts := map[string]int64{}
+
+		for _, document := range documents {
+			if !commons.MatchFilter(document, filter) {
+				continue
+			}
+			for _, value := range facetValues(document, facet) {
+				counts[value]++
+			}
+		}
+
+		values := make([]FacetValue, 0, len(counts))
+		for value, count := range counts {
+			values = append(values, FacetValue{Value: value, Count: count})
+		}
+
+		sort.Slice(values, func(i, j int) bool { return values[i].Value < values[j].Value })
+		facets[facet] = values
+	}
+
+	return JobFacets{
+		Companies:             facets[FACET_COMPANIES],
+		Locations:             facets[FACET_LOCATIONS],
+		Providers:             facets[FACET_PROVIDERS],
+		Salaries:              facets[FACET_SALARIES],
+		ContractTypes:         facets[FACET_CONTRACT_TYPES],
+		Modes:                 facets[FACET_MODES],
+		AffirmativeParameters: facets[FACET_AFFIRMATIVE_PARAMETERS],
+	}, nil
+}
+
+// facetValues returns the values of the facet the job document has.
+func facetValues(document bson.M, facet string) []string {
+
+	if field, ok := facetFields[facet]; ok {
+		if value, _ := document[field].(string); value != "" {
+			return []string{value}
+		}
+		return nil
+	}
+
+	values := []string{}
+	parameters, _ := document["affirmative_parameters"].(bson.M)
+
+	for _, parameter := range AFFIRMATIVE_PARAMETERS {
+		if enabled, _ := parameters[parameter].(bool); enabled {
+			values = append(values, parameter)
+		}
+	}
+
+	return values
+}
+
+func (repository *InMemoryJobRepository) GetJob(ctx context.Context, code string) (Job, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for _, job := range repository.jobs {
+		if job.Code == code {
+			return job, nil
+		}
+	}
+
+	return Job{}, nil
+}
+
+func (repository *InMemoryJobRepository) CreateJob(ctx context.Context, body CreateJobBody) (Job, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	job, err := newJob(repository.baseUiHost, body, func(shortUrl string) (bool, error) {
+		for _, item := range repository.jobs {
+			if item.JobShortUrl == shortUrl {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+
+	if err != nil {
+		return Job{}, err
+	}
+
+	repository.jobs = append(repository.jobs, job)
+
+	return job, nil
+}
+
+func (repository *InMemoryJobRepository) EditJob(ctx context.Context, id string, body EditJobBody, by primitive.ObjectID, transition JobTransition) (bool, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for i := range repository.jobs {
+		if repository.jobs[i].Id == id && repository.jobs[i].CurrentStatus() == transition.From {
+			repository.jobs[i].Title = body.Title
+			repository.jobs[i].Description = body.Description
+			repository.jobs[i].Salary = body.Salary
+			repository.jobs[i].Location = body.Location
+			repository.jobs[i].Url = body.Url
+			repository.jobs[i].ContractType = body.ContractType
+			repository.jobs[i].LastUpdate = commons.GetBrasiliaTime()
+			repository.jobs[i].UdatedBy = by
+			if transition.To != "" {
+				repository.jobs[i].apply(transition)
+			}
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (repository *InMemoryJobRepository) DeleteJob(ctx context.Context, code string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for i, job := range repository.jobs {
+		if job.Code == code {
+			repository.jobs = append(repository.jobs[:i], repository.jobs[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (repository *InMemoryJobRepository) GetOriginalURL(ctx context.Context, shortUrl string) (string, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for _, job := range repository.jobs {
+		if job.JobShortUrl == shortUrl && job.IsApproved && !job.IsClosed {
+			return job.Url, nil
+		}
+	}
+
+	if len(shortUrl) < 6 {
+		return "", errors.New("short url inválida")
+	}
+
+	code := shortUrl[len(shortUrl)-6:]
+	return repository.baseUiHost + `/vagas/` + code, mongo.ErrNoDocuments
+}
+
+func (repository *InMemoryJobRepository) UpdateJobClicks(ctx context.Context, shortUrl string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for i := range repository.jobs {
+		if repository.jobs[i].JobShortUrl == shortUrl {
+			repository.jobs[i].QtyClicks++
+			break
+		}
+	}
+
+	return nil
+}
+
+func (repository *InMemoryJobRepository) RenewJob(ctx context.Context, id string, expiresAt time.Time, by primitive.ObjectID) (bool, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for i := range repository.jobs {
+		if repository.jobs[i].Id == id && repository.jobs[i].CurrentStatus() == JOB_STATUS_APPROVED {
+			repository.jobs[i].ExpiresAt = expiresAt
+			repository.jobs[i].IsExpiryReminderSent = false
+			repository.jobs[i].LastUpdate = commons.GetBrasiliaTime()
+			repository.jobs[i].UdatedBy = by
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (repository *InMemoryJobRepository) TransitionJob(ctx context.Context, id string, transition JobTransition) (bool, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for i := range repository.jobs {
+		if repository.jobs[i].Id == id && repository.jobs[i].CurrentStatus() == transition.From {
+			repository.jobs[i].apply(transition)
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (repository *InMemoryJobRepository) CloseExpiredJobs(ctx context.Context, now time.Time) (int64, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	documents, err := commons.FindDocuments(repository.jobs, getExpiredJobsFilter(now), "", true)
+
+	if err != nil {
+		return 0, err
+	}
+
+	expired := map[string]bool{}
+	for _, document := range documents {
+		expired[document["_id"].(string)] = true
+	}
+
+	for i := range repository.jobs {
+		if expired[repository.jobs[i].Id] {
+			repository.jobs[i].apply(JobTransition{From: JOB_STATUS_APPROVED, To: JOB_STATUS_EXPIRED, At: now})
+		}
+	}
+
+	return int64(len(expired)), nil
+}
+
+func (repository *InMemoryJobRepository) GetJobsToRemind(ctx context.Context, until time.Time) ([]Job, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	documents, err := commons.FindDocuments(repository.jobs, getJobsToRemindFilter(until), "", true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := []Job{}
+
+	for _, document := range documents {
+		var job Job
+		if err := commons.FromDocument(document, &job); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+func (repository *InMemoryJobRepository) ClaimExpiryReminder(ctx context.Context, id string) (bool, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for i := range repository.jobs {
+		if repository.jobs[i].Id == id && !repository.jobs[i].IsExpiryReminderSent {
+			repository.jobs[i].IsExpiryReminderSent = true
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (repository *InMemoryJobRepository) ReleaseExpiryReminder(ctx context.Context, id string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for i := range repository.jobs {
+		if repository.jobs[i].Id == id {
+			repository.jobs[i].IsExpiryReminderSent = false
+			break
+		}
+	}
+
+	return nil
+}
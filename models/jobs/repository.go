@@ -0,0 +1,120 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// JobRepository is the storage used by the jobs and short URL controllers.
+type JobRepository interface {
+	GetJobs(ctx context.Context, body JobFilter) (PaginatedResult, error)
+	GetJobsAsAdmin(ctx context.Context, filter commons.FilterRequest) (JobsPaginatedResult, error)
+	GetJobsByCreator(ctx context.Context, creator primitive.ObjectID, filter commons.FilterRequest) (JobsPaginatedResult, error)
+	GetAggregatedJobsValues(ctx context.Context, body JobFilter) (JobFacets, error)
+	GetJob(ctx context.Context, code string) (Job, error)
+	CreateJob(ctx context.Context, body CreateJobBody) (Job, error)
+	EditJob(ctx context.Context, id string, body EditJobBody, by primitive.ObjectID, transition JobTransition) (bool, error)
+	DeleteJob(ctx context.Context, code string) error
+	GetOriginalURL(ctx context.Context, shortUrl string) (string, error)
+	UpdateJobClicks(ctx context.Context, shortUrl string) error
+	RenewJob(ctx context.Context, id string, expiresAt time.Time, by primitive.ObjectID) (bool, error)
+	TransitionJob(ctx context.Context, id string, transition JobTransition) (bool, error)
+	CloseExpiredJobs(ctx context.Context, now time.Time) (int64, error)
+	GetJobsToRemind(ctx context.Context, until time.Time) ([]Job, error)
+	ClaimExpiryReminder(ctx context.Context, id string) (bool, error)
+	ReleaseExpiryReminder(ctx context.Context, id string) error
+}
+
+type MongoJobRepository struct {
+	db         *models.DbContext
+	baseUiHost string
+}
+
+func NewMongoJobRepository(db *models.DbContext, baseUiHost string) *MongoJobRepository {
+	return &MongoJobRepository{db: db, baseUiHost: baseUiHost}
+}
+
+func (repository *MongoJobRepository) GetJobs(ctx context.Context, body JobFilter) (PaginatedResult, error) {
+	defer metrics.ObserveMongoOperation("jobs", "GetJobs")()
+	return GetJobs(ctx, repository.db, body)
+}
+
+func (repository *MongoJobRepository) GetJobsAsAdmin(ctx context.Context, filter commons.FilterRequest) (JobsPaginatedResult, error) {
+	defer metrics.ObserveMongoOperation("jobs", "GetJobsAsAdmin")()
+	return GetJobsAsAdmin(ctx, repository.db, filter)
+}
+
+func (repository *MongoJobRepository) GetJobsByCreator(ctx context.Context, creator primitive.ObjectID, filter commons.FilterRequest) (JobsPaginatedResult, error) {
+	defer metrics.ObserveMongoOperation("jobs", "GetJobsByCreator")()
+	return GetJobsByCreator(ctx, repository.db, creator, filter)
+}
+
+func (repository *MongoJobRepository) GetAggregatedJobsValues(ctx context.Context, body JobFilter) (JobFacets, error) {
+	defer metrics.ObserveMongoOperation("jobs", "GetAggregatedJobsValues")()
+	return GetAggregatedJobsValues(ctx, repository.db, body)
+}
+
+func (repository *MongoJobRepository) GetJob(ctx context.Context, code string) (Job, error) {
+	defer metrics.ObserveMongoOperation("jobs", "GetJob")()
+	return GetJob(ctx, repository.db, code)
+}
+
+func (repository *MongoJobRepository) CreateJob(ctx context.Context, body CreateJobBody) (Job, error) {
+	defer metrics.ObserveMongoOperation("jobs", "CreateJob")()
+	return CreateJob(ctx, repository.db, repository.baseUiHost, body)
+}
+
+func (repository *MongoJobRepository) EditJob(ctx context.Context, id string, body EditJobBody, by primitive.ObjectID, transition JobTransition) (bool, error) {
+	defer metrics.ObserveMongoOperation("jobs", "EditJob")()
+	return EditJob(ctx, repository.db, id, body, by, transition)
+}
+
+func (repository *MongoJobRepository) DeleteJob(ctx context.Context, code string) error {
+	defer metrics.ObserveMongoOperation("jobs", "DeleteJob")()
+	return DeleteJob(ctx, repository.db, code)
+}
+
+func (repository *MongoJobRepository) GetOriginalURL(ctx context.Context, shortUrl string) (string, error) {
+	defer metrics.ObserveMongoOperation("jobs", "GetOriginalURL")()
+	return GetOriginalURL(ctx, repository.db, repository.baseUiHost, shortUrl)
+}
+
+func (repository *MongoJobRepository) UpdateJobClicks(ctx context.Context, shortUrl string) error {
+	defer metrics.ObserveMongoOperation("jobs", "UpdateJobClicks")()
+	return UpdateJobClicks(ctx, repository.db, shortUrl)
+}
+
+func (repository *MongoJobRepository) RenewJob(ctx context.Context, id string, expiresAt time.Time, by primitive.ObjectID) (bool, error) {
+	defer metrics.ObserveMongoOperation("jobs", "RenewJob")()
+	return RenewJob(ctx, repository.db, id, expiresAt, by)
+}
+
+func (repository *MongoJobRepository) TransitionJob(ctx context.Context, id string, transition JobTransition) (bool, error) {
+	defer metrics.ObserveMongoOperation("jobs", "TransitionJob")()
+	return TransitionJob(ctx, repository.db, id, transition)
+}
+
+func (repository *MongoJobRepository) CloseExpiredJobs(ctx context.Context, now time.Time) (int64, error) {
+	defer metrics.ObserveMongoOperation("jobs", "CloseExpiredJobs")()
+	return CloseExpiredJobs(ctx, repository.db, now)
+}
+
+func (repository *MongoJobRepository) GetJobsToRemind(ctx context.Context, until time.Time) ([]Job, error) {
+	defer metrics.ObserveMongoOperation("jobs", "GetJobsToRemind")()
+	return GetJobsToRemind(ctx, repository.db, until)
+}
+
+func (repository *MongoJobRepository) ClaimExpiryReminder(ctx context.Context, id string) (bool, error) {
+	defer metrics.ObserveMongoOperation("jobs", "ClaimExpiryReminder")()
+	return ClaimExpiryReminder(ctx, repository.db, id)
+}
+
+func (repository *MongoJobRepository) ReleaseExpiryReminder(ctx context.Context, id string) error {
+	defer metrics.ObserveMongoOperation("jobs", "ReleaseExpiryReminder")()
+	return ReleaseExpiryReminder(ctx, repository.db, id)
+}
@@ -0,0 +1,204 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	MIGRATIONS_COLLECTION = "migrations"
+
+	// The instances of the API starting together take turns to apply the
+	// migrations through a lock document. The lock is a lease, so it is
+	// taken over when its holder dies without releasing it.
+	LOCK_COLLECTION     = "migrations_lock"
+	LOCK_ID             = "migrations"
+	LOCK_LEASE          = 10 * time.Minute
+	LOCK_RETRY_INTERVAL = time.Second
+)
+
+// Migration changes the schema or the data of the database. Up must be safe to
+// run again if it fails halfway, since the version is only recorded after it
+// succeeds.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *models.DbContext) error
+}
+
+// AppliedMigration is the record kept in the migrations collection.
+type AppliedMigration struct {
+	Version     int       `bson:"_id" json:"version"`
+	Description string    `bson:"description" json:"description"`
+	AppliedAt   time.Time `bson:"applied_at" json:"applied_at"`
+}
+
+// Run applies the migrations of the application not yet recorded.
+func Run(ctx context.Context, db *models.DbContext, settings config.Config) ([]AppliedMigration, error) {
+	return Apply(ctx, db, All(settings))
+}
+
+// Apply runs, in version order, the migrations not yet recorded in the
+// migrations collection and returns the ones it applied. It holds the lock
+// meanwhile, so the migrations applied by another instance are skipped.
+func Apply(ctx context.Context, db *models.DbContext, migrations []Migration) ([]AppliedMigration, error) {
+
+	release, err := lock(ctx, db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	applied, err := GetAppliedMigrations(ctx, db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err = Pending(migrations, applied)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := []AppliedMigration{}
+
+	for _, migration := range migrations {
+
+		slog.InfoContext(ctx, "Applying migration", "version", migration.Version, "description", migration.Description)
+
+		if err = migration.Up(ctx, db); err != nil {
+			return result, fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Description, err)
+		}
+
+		record := AppliedMigration{
+			Version:     migration.Version,
+			Description: migration.Description,
+			AppliedAt:   time.Now().UTC(),
+		}
+
+		// Recorded meanwhile by an instance that took over an expired lock
+		if _, err = db.Collection(MIGRATIONS_COLLECTION).InsertOne(ctx, record); mongo.IsDuplicateKeyError(err) {
+			slog.WarnContext(ctx, "Migration already recorded", "version", migration.Version)
+			continue
+		}
+
+		if err != nil {
+			return result, fmt.Errorf("recording migration %d: %w", migration.Version, err)
+		}
+
+		result = append(result, record)
+	}
+
+	return result, nil
+}
+
+// lock waits for the lock of the migrations and returns the function
+// releasing it.
+func lock(ctx context.Context, db *models.DbContext) (func(), error) {
+
+	collection := db.Collection(LOCK_COLLECTION)
+	owner := primitive.NewObjectID()
+
+	for {
+		now := time.Now().UTC()
+
+		// A lock still held does not match, and the upsert of its _id fails
+		_, err := collection.UpdateOne(ctx,
+			bson.M{"_id": LOCK_ID, "locked_until": bson.M{"$lt": now}},
+			bson.M{"$set": bson.M{"owner": owner, "locked_until": now.Add(LOCK_LEASE)}},
+			options.Update().SetUpsert(true),
+		)
+
+		if err == nil {
+			break
+		}
+
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("locking the migrations: %w", err)
+		}
+
+		slog.InfoContext(ctx, "Waiting for the migrations applied by another instance")
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("locking the migrations: %w", ctx.Err())
+		case <-time.After(LOCK_RETRY_INTERVAL):
+		}
+	}
+
+	return func() {
+		if _, err := collection.DeleteOne(context.Background(), bson.M{"_id": LOCK_ID, "owner": owner}); err != nil {
+			slog.ErrorContext(ctx, "Error releasing the lock of the migrations", "error", err)
+		}
+	}, nil
+}
+
+// GetAppliedMigrations returns the migrations recorded so far, by version.
+func GetAppliedMigrations(ctx context.Context, db *models.DbContext) ([]AppliedMigration, error) {
+
+	options := options.Find().SetSort(bson.M{"_id": 1})
+
+	cursor, err := db.Collection(MIGRATIONS_COLLECTION).Find(ctx, bson.M{}, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	applied := []AppliedMigration{}
+
+	if err = cursor.All(ctx, &applied); err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// Pending returns the migrations not yet applied, sorted by version. The
+// versions must be positive and unique.
+func Pending(migrations []Migration, applied []AppliedMigration) ([]Migration, error) {
+
+	done := map[int]bool{}
+
+	for _, migration := range applied {
+		done[migration.Version] = true
+	}
+
+	seen := map[int]bool{}
+	pending := []Migration{}
+
+	for _, migration := range migrations {
+
+		if migration.Version <= 0 {
+			return nil, fmt.Errorf("migration %q has an invalid version %d", migration.Description, migration.Version)
+		}
+
+		if seen[migration.Version] {
+			return nil, fmt.Errorf("migration version %d is duplicated", migration.Version)
+		}
+
+		seen[migration.Version] = true
+
+		if !done[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].Version < pending[j].Version
+	})
+
+	return pending, nil
+}
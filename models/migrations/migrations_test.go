@@ -0,0 +1,67 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/jobs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingSkipsAppliedAndSortsByVersion(t *testing.T) {
+	migrations := []Migration{
+		{Version: 3, Description: "three"},
+		{Version: 1, Description: "one"},
+		{Version: 2, Description: "two"},
+	}
+
+	pending, err := Pending(migrations, []AppliedMigration{{Version: 2}})
+
+	assert.NoError(t, err)
+	assert.Len(t, pending, 2)
+	assert.Equal(t, 1, pending[0].Version)
+	assert.Equal(t, 3, pending[1].Version)
+}
+
+func TestPendingRejectsInvalidVersions(t *testing.T) {
+	_, err := Pending([]Migration{{Version: 1}, {Version: 1}}, nil)
+	assert.ErrorContains(t, err, "duplicated")
+
+	_, err = Pending([]Migration{{Version: 0}}, nil)
+	assert.ErrorContains(t, err, "invalid version")
+}
+
+func TestAllVersionsAreValid(t *testing.T) {
+	pending, err := Pending(All(config.Default()), nil)
+
+	assert.NoError(t, err)
+	assert.Len(t, pending, len(All(config.Default())))
+}
+
+func TestBackfilledExpiresAtLeavesTheOldJobsTimeToRenew(t *testing.T) {
+	settings := config.Default().Jobs
+	settings.ProviderLifetimes = []string{"linkedin=336h"}
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	// A recent job keeps the date its lifetime gives
+	createdAt := now.Add(-24 * time.Hour)
+	assert.Equal(t, createdAt.Add(settings.DefaultLifetime), backfilledExpiresAt(settings, createdAt, "vagasprajr", now))
+	assert.Equal(t, createdAt.Add(336*time.Hour), backfilledExpiresAt(settings, createdAt, "LinkedIn", now))
+
+	// An old job is neither expired nor reminded by the first run after the backfill
+	old := jobs.Job{Id: "1", Code: "AAAAA1", IsApproved: true, CreatedAt: now.AddDate(-2, 0, 0)}
+	old.ExpiresAt = backfilledExpiresAt(settings, old.CreatedAt, old.Provider, now)
+	assert.Equal(t, now.Add(settings.ExpiryReminder+EXPIRY_BACKFILL_GRACE), old.ExpiresAt)
+
+	repository := jobs.NewInMemoryJobRepository("https://vagasprajr.test", old)
+
+	closed, err := repository.CloseExpiredJobs(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Zero(t, closed)
+
+	reminded, err := repository.GetJobsToRemind(context.Background(), now.Add(settings.ExpiryReminder))
+	assert.NoError(t, err)
+	assert.Empty(t, reminded)
+}
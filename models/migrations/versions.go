@@ -0,0 +1,216 @@
+package migrations
+
+import (
+	"context"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// All returns the migrations of the application. New migrations are appended
+// with the next version; applied ones must never change.
+func All(settings config.Config) []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "Create the collections",
+			Up:          createCollections,
+		},
+		{
+			Version:     2,
+			Description: "Normalise the users emails to lowercase",
+			Up:          lowercaseUserEmails,
+		},
+		{
+			Version:     3,
+			Description: "Rename jobs updated_at to last_update",
+			Up:          renameJobsUpdatedAt,
+		},
+		{
+			Version:     4,
+			Description: "Index the refresh tokens and validation tokens lookups",
+			Up:          createTokenIndexes,
+		},
+		{
+			Version:     5,
+			Description: "Derive the jobs status from is_approved and is_closed",
+			Up:          setJobsStatus,
+		},
+		{
+			Version:     6,
+			Description: "Set the expiry date of the open jobs without one",
+			Up: func(ctx context.Context, db *models.DbContext) error {
+				return setJobsExpiresAt(ctx, db, settings.Jobs)
+			},
+		},
+	}
+}
+
+func createCollections(ctx context.Context, db *models.DbContext) error {
+
+	existing, err := db.Database.ListCollectionNames(ctx, bson.M{})
+
+	if err != nil {
+		return err
+	}
+
+	exists := map[string]bool{}
+
+	for _, name := range existing {
+		exists[name] = true
+	}
+
+	collections := []string{"jobs", "users", "users_tokens", "users_google", "roles", "ads", "ad_references", "settings"}
+
+	for _, name := range collections {
+		if exists[name] {
+			continue
+		}
+
+		if err = db.Database.CreateCollection(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func lowercaseUserEmails(ctx context.Context, db *models.DbContext) error {
+
+	filter := bson.M{"email": bson.M{"$type": "string", "$regex": "[A-Z]"}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{"email": bson.M{"$toLower": "$email"}}}}}
+
+	_, err := db.Collection("users").UpdateMany(ctx, filter, update)
+
+	return err
+}
+
+func renameJobsUpdatedAt(ctx context.Context, db *models.DbContext) error {
+
+	filter := bson.M{"updated_at": bson.M{"$exists": true}}
+	update := bson.M{"$rename": bson.M{"updated_at": "last_update"}}
+
+	_, err := db.Collection("jobs").UpdateMany(ctx, filter, update)
+
+	return err
+}
+
+func createTokenIndexes(ctx context.Context, db *models.DbContext) error {
+
+	_, err := db.Collection("users_tokens").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}},
+		Options: options.Index().SetName("user_id_1"),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection("users").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "validation_token", Value: 1}},
+		Options: options.Index().SetName("validation_token_1"),
+	})
+
+	return err
+}
+
+// closedByExpiry tells the jobs closed by the expiry scheduler, whose closed_at
+// is their expires_at or later, from the ones closed by hand.
+var closedByExpiry = bson.M{"$and": bson.A{
+	bson.M{"$gt": bson.A{"$expires_at", time.Time{}}},
+	bson.M{"$gte": bson.A{"$closed_at", "$expires_at"}},
+}}
+
+func setJobsStatus(ctx context.Context, db *models.DbContext) error {
+
+	filter := bson.M{"status": bson.M{"$exists": false}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{"status": bson.M{"$switch": bson.M{
+		"branches": bson.A{
+			bson.M{"case": bson.M{"$and": bson.A{bson.M{"$eq": bson.A{"$is_closed", true}}, closedByExpiry}}, "then": "expired"},
+			bson.M{"case": bson.M{"$eq": bson.A{"$is_closed", true}}, "then": "closed"},
+			bson.M{"case": bson.M{"$eq": bson.A{"$is_approved", true}}, "then": "approved"},
+		},
+		"default": "pending_review",
+	}}}}}}
+
+	_, err := db.Collection("jobs").UpdateMany(ctx, filter, update)
+
+	return err
+}
+
+// EXPIRY_BACKFILL_GRACE is how long, past the reminder period, the approved
+// jobs given an expiry date by the backfill stay open at least, so their creators
+// are reminded and can renew them before they expire.
+const EXPIRY_BACKFILL_GRACE = 7 * 24 * time.Hour
+
+// setJobsExpiresAt gives the approved jobs created before expires_at existed
+// the expiry date they would have had, created_at plus the lifetime of their
+// provider, or a renewal window when that date has already passed. The jobs
+// not approved yet get theirs when they are.
+func setJobsExpiresAt(ctx context.Context, db *models.DbContext, settings config.JobsConfig) error {
+
+	collection := db.Collection("jobs")
+
+	filter := bson.M{
+		"is_approved": true,
+		"is_closed":   bson.M{"$ne": true},
+		"created_at":  bson.M{"$type": "date"},
+		"$or": bson.A{
+			bson.M{"expires_at": bson.M{"$exists": false}},
+			bson.M{"expires_at": nil},
+			bson.M{"expires_at": bson.M{"$lte": time.Time{}}},
+		},
+	}
+
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"created_at": 1, "provider": 1}))
+
+	if err != nil {
+		return err
+	}
+
+	defer cursor.Close(ctx)
+
+	now := time.Now()
+	updates := []mongo.WriteModel{}
+
+	for cursor.Next(ctx) {
+
+		var job struct {
+			Id        interface{} `bson:"_id"`
+			CreatedAt time.Time   `bson:"created_at"`
+			Provider  string      `bson:"provider"`
+		}
+
+		if err = cursor.Decode(&job); err != nil {
+			return err
+		}
+
+		expiresAt := backfilledExpiresAt(settings, job.CreatedAt, job.Provider, now)
+		updates = append(updates, mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": job.Id}).SetUpdate(bson.M{"$set": bson.M{"expires_at": expiresAt}}))
+	}
+
+	if err = cursor.Err(); err != nil || len(updates) == 0 {
+		return err
+	}
+
+	_, err = collection.BulkWrite(ctx, updates, options.BulkWrite().SetOrdered(false))
+
+	return err
+}
+
+// backfilledExpiresAt is created_at plus the lifetime of the provider, but no
+// sooner than the reminder period and the grace from now.
+func backfilledExpiresAt(settings config.JobsConfig, createdAt time.Time, provider string, now time.Time) time.Time {
+
+	expiresAt := createdAt.Add(settings.Lifetime(provider))
+
+	if earliest := now.Add(settings.ExpiryReminder + EXPIRY_BACKFILL_GRACE); expiresAt.Before(earliest) {
+		return earliest
+	}
+
+	return expiresAt
+}
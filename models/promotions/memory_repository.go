@@ -0,0 +1,46 @@
+package promotions
+
+import (
+	"context"
+
+	"sync"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// InMemoryAdRepository keeps the ads in memory. It is meant for tests.
+type InMemoryAdRepository struct {
+	mutex sync.Mutex
+	ads   []AdItem
+}
+
+func NewInMemoryAdRepository(ads ...AdItem) *InMemoryAdRepository {
+	return &InMemoryAdRepository{ads: append([]AdItem{}, ads...)}
+}
+
+func (repository *InMemoryAdRepository) GetAdOriginalURL(ctx context.Context, shortUrl string) (string, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for _, ad := range repository.ads {
+		if ad.ShortUrl == shortUrl {
+			return ad.OriginalUrl, nil
+		}
+	}
+
+	return "", mongo.ErrNoDocuments
+}
+
+func (repository *InMemoryAdRepository) UpdateAdvertisementClicks(ctx context.Context, shortUrl string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for i := range repository.ads {
+		if repository.ads[i].ShortUrl == shortUrl {
+			repository.ads[i].QtyClicks++
+			break
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package promotions
+
+import (
+	"context"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
+)
+
+// AdRepository is the storage of the advertisement short URLs.
+type AdRepository interface {
+	GetAdOriginalURL(ctx context.Context, shortUrl string) (string, error)
+	UpdateAdvertisementClicks(ctx context.Context, shortUrl string) error
+}
+
+type MongoAdRepository struct {
+	db *models.DbContext
+}
+
+func NewMongoAdRepository(db *models.DbContext) *MongoAdRepository {
+	return &MongoAdRepository{db: db}
+}
+
+func (repository *MongoAdRepository) GetAdOriginalURL(ctx context.Context, shortUrl string) (string, error) {
+	defer metrics.ObserveMongoOperation("ads", "GetAdOriginalURL")()
+	return GetAdOriginalURL(ctx, repository.db, shortUrl)
+}
+
+func (repository *MongoAdRepository) UpdateAdvertisementClicks(ctx context.Context, shortUrl string) error {
+	defer metrics.ObserveMongoOperation("ads", "UpdateAdvertisementClicks")()
+	return UpdateAdvertisementClicks(ctx, repository.db, shortUrl)
+}
@@ -3,50 +3,36 @@ package roles
 import (
 	"context"
 	"encoding/json"
-	"os"
-	"time"
+	"errors"
+	"log/slog"
 
 	"github.com/flaviofrancisco/vagasprajr-api-v2/cache"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
-	"github.com/go-redis/redis"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
-func GetRoles() ([]Role, error) {
+const (
+	ROLES_CACHE_KEY = "all"
+)
+
+// GetRoles returns the roles from the cache or, on a miss, from the database.
+// A cache failure is not an error: the roles are read from the database.
+func GetRoles(ctx context.Context, db *models.DbContext, store cache.Store) ([]Role, error) {
 
-	roles, err := getRolesFromCache()
+	roles, err := getRolesFromCache(ctx, store)
 
 	if err != nil {
-		return []Role{}, err
+		slog.WarnContext(ctx, "Error reading the roles from the cache", "error", err)
 	}
 
+	metrics.ObserveCache(metrics.CACHE_ROLES, len(roles) > 0)
+
 	if len(roles) > 0 {
 		return roles, nil
 	}
 
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-
-	client, err := models.Connect()
-
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	if err != nil {
-		return []Role{}, err
-	}
-
-	collection := client.Database(mongodb_database).Collection("roles")	
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	if err != nil {
-		return []Role{}, err
-	}
+	collection := db.Collection("roles")	
 
 	cursor, err := collection.Find(ctx, bson.M{})
 
@@ -58,17 +44,14 @@ func GetRoles() ([]Role, error) {
 		return []Role{}, err
 	}
 
-	setCacheRoles(roles)
+	if err = setCacheRoles(ctx, store, roles); err != nil {
+		slog.WarnContext(ctx, "Error caching the roles", "error", err)
+	}
 
 	return roles, nil
 }
 
-func setCacheRoles(roles []Role) error {
-	redis, err := cache.NewRedis()
-
-	if err != nil {
-		return err
-	}
+func setCacheRoles(ctx context.Context, store cache.Store, roles []Role) error {
 
 	rolesBytes, err := json.Marshal(roles)
 
@@ -76,30 +59,18 @@ func setCacheRoles(roles []Role) error {
 		return err
 	}
 
-	err = redis.RedisClient.Set("roles", rolesBytes, 0).Err()
-
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return store.Set(ctx, ROLES_CACHE_KEY, rolesBytes, 0)
 }
 
-func getRolesFromCache() ([]Role, error) {
-
-	cacheServer, err := cache.NewRedis()	
-
-	if err != nil {
-		return []Role{}, err
-	}
+func getRolesFromCache(ctx context.Context, store cache.Store) ([]Role, error) {
 
 	// Get the roles from the cache
-	rolesBytes, err := cacheServer.RedisClient.Get("roles").Bytes()
+	rolesBytes, err := store.Get(ctx, ROLES_CACHE_KEY)
 
-    if err != nil && err != redis.Nil {
-        return []Role{}, err
-    } else if err == redis.Nil {
+	if errors.Is(err, cache.ErrNotFound) {
 		return []Role{}, nil
+	} else if err != nil {
+		return []Role{}, err
 	}
 
 	var roles []Role
@@ -112,4 +83,4 @@ func getRolesFromCache() ([]Role, error) {
 
 	return roles, nil
 
-}
\ No newline at end of file
+}
@@ -0,0 +1,144 @@
+package shopping
+
+import (
+	"context"
+
+	"sync"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// InMemoryAdReferenceRepository keeps the ad references in memory, applying the
+// same filters, sorting and pagination as MongoAdReferenceRepository. It is
+// meant for tests.
+type InMemoryAdReferenceRepository struct {
+	mutex        sync.Mutex
+	adReferences []AdReference
+}
+
+func NewInMemoryAdReferenceRepository(adReferences ...AdReference) *InMemoryAdReferenceRepository {
+	return &InMemoryAdReferenceRepository{adReferences: append([]AdReference{}, adReferences...)}
+}
+
+func (repository *InMemoryAdReferenceRepository) GetFilteredAdReferences(ctx context.Context, filter commons.FilterRequest) (AdReferencesPaginatedResult, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	page, perPage := filter.Page, filter.PageSize
+
+	if (page - 1) < 0 {
+		page = 1
+	}
+
+	if filter.Sort == "" {
+		filter.Sort = "created_at"
+	}
+
+	documents, err := commons.FindDocuments(repository.adReferences, filter.GetFilter(), filter.Sort, filter.IsAscending)
+
+	if err != nil {
+		return AdReferencesPaginatedResult{}, err
+	}
+
+	start, end := commons.GetPageBounds(len(documents), page, perPage)
+
+	var adReferences []AdReference
+
+	for _, document := range documents[start:end] {
+		var adReference AdReference
+		if err = commons.FromDocument(document, &adReference); err != nil {
+			return AdReferencesPaginatedResult{}, err
+		}
+		adReferences = append(adReferences, adReference)
+	}
+
+	return AdReferencesPaginatedResult{
+		Total:   int64(len(documents)),
+		Page:    page,
+		PerPage: perPage,
+		Data:    adReferences,
+	}, nil
+}
+
+func (repository *InMemoryAdReferenceRepository) GetAdReferences(ctx context.Context) ([]AdReference, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	var adReferences []AdReference
+
+	for _, adReference := range repository.adReferences {
+		if adReference.IsActive {
+			adReferences = append(adReferences, adReference)
+		}
+	}
+
+	return adReferences, nil
+}
+
+func (repository *InMemoryAdReferenceRepository) GetAdReference(ctx context.Context, id string) (AdReference, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	idHex, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return AdReference{}, err
+	}
+
+	for _, adReference := range repository.adReferences {
+		if adReference.Id == idHex {
+			return adReference, nil
+		}
+	}
+
+	return AdReference{}, mongo.ErrNoDocuments
+}
+
+func (repository *InMemoryAdReferenceRepository) CreateAdReference(ctx context.Context, adReference AdReference) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	adReference.Id = primitive.NewObjectID()
+	adReference.CreatedAt = commons.GetBrasiliaTime()
+
+	repository.adReferences = append(repository.adReferences, adReference)
+
+	return nil
+}
+
+func (repository *InMemoryAdReferenceRepository) DeleteAdReference(ctx context.Context, id string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	idHex, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return err
+	}
+
+	for i, adReference := range repository.adReferences {
+		if adReference.Id == idHex {
+			repository.adReferences = append(repository.adReferences[:i], repository.adReferences[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (repository *InMemoryAdReferenceRepository) UpdateAdReference(ctx context.Context, adReference AdReference) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for i := range repository.adReferences {
+		if repository.adReferences[i].Id == adReference.Id {
+			adReference.LastUpdate = commons.GetBrasiliaTime()
+			repository.adReferences[i] = adReference
+			break
+		}
+	}
+
+	return nil
+}
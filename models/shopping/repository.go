@@ -0,0 +1,57 @@
+package shopping
+
+import (
+	"context"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
+)
+
+// AdReferenceRepository is the storage used by the shopping controller.
+type AdReferenceRepository interface {
+	GetFilteredAdReferences(ctx context.Context, filter commons.FilterRequest) (AdReferencesPaginatedResult, error)
+	GetAdReferences(ctx context.Context) ([]AdReference, error)
+	GetAdReference(ctx context.Context, id string) (AdReference, error)
+	CreateAdReference(ctx context.Context, adReference AdReference) error
+	DeleteAdReference(ctx context.Context, id string) error
+	UpdateAdReference(ctx context.Context, adReference AdReference) error
+}
+
+type MongoAdReferenceRepository struct {
+	db *models.DbContext
+}
+
+func NewMongoAdReferenceRepository(db *models.DbContext) *MongoAdReferenceRepository {
+	return &MongoAdReferenceRepository{db: db}
+}
+
+func (repository *MongoAdReferenceRepository) GetFilteredAdReferences(ctx context.Context, filter commons.FilterRequest) (AdReferencesPaginatedResult, error) {
+	defer metrics.ObserveMongoOperation("ad_references", "GetFilteredAdReferences")()
+	return GetFilteredAdReferences(ctx, repository.db, filter)
+}
+
+func (repository *MongoAdReferenceRepository) GetAdReferences(ctx context.Context) ([]AdReference, error) {
+	defer metrics.ObserveMongoOperation("ad_references", "GetAdReferences")()
+	return GetAdReferences(ctx, repository.db)
+}
+
+func (repository *MongoAdReferenceRepository) GetAdReference(ctx context.Context, id string) (AdReference, error) {
+	defer metrics.ObserveMongoOperation("ad_references", "GetAdReference")()
+	return GetAdReference(ctx, repository.db, id)
+}
+
+func (repository *MongoAdReferenceRepository) CreateAdReference(ctx context.Context, adReference AdReference) error {
+	defer metrics.ObserveMongoOperation("ad_references", "CreateAdReference")()
+	return CreateAdReference(ctx, repository.db, adReference)
+}
+
+func (repository *MongoAdReferenceRepository) DeleteAdReference(ctx context.Context, id string) error {
+	defer metrics.ObserveMongoOperation("ad_references", "DeleteAdReference")()
+	return DeleteAdReference(ctx, repository.db, id)
+}
+
+func (repository *MongoAdReferenceRepository) UpdateAdReference(ctx context.Context, adReference AdReference) error {
+	defer metrics.ObserveMongoOperation("ad_references", "UpdateAdReference")()
+	return UpdateAdReference(ctx, repository.db, adReference)
+}
@@ -2,7 +2,6 @@ package shopping
 
 import (
 	"context"
-	"os"
 
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
@@ -11,24 +10,9 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func GetFilteredAdReferences(filter commons.FilterRequest) (AdReferencesPaginatedResult, error) {
+func GetFilteredAdReferences(ctx context.Context, db *models.DbContext, filter commons.FilterRequest) (AdReferencesPaginatedResult, error) {
 		collection:= "ad_references"
 
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return AdReferencesPaginatedResult{}, err
-	}
-
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
-
 	page:= filter.Page
 	perPage:= filter.PageSize
 
@@ -50,7 +34,7 @@ func GetFilteredAdReferences(filter commons.FilterRequest) (AdReferencesPaginate
 
 	options := options.Find().SetSort(bson.M{filter.Sort: orderDirection}).SetSkip(int64(skip)).SetLimit(int64(perPage))
 
-	cursor, err := db.Collection(collection).Find(context.Background(), filter.GetFilter(), options)
+	cursor, err := db.Collection(collection).Find(ctx, filter.GetFilter(), options)
 
 	if err != nil {
 		return AdReferencesPaginatedResult{}, err
@@ -58,11 +42,11 @@ func GetFilteredAdReferences(filter commons.FilterRequest) (AdReferencesPaginate
 
 	var adReferences []AdReference
 
-	if err = cursor.All(context.Background(), &adReferences); err != nil {
+	if err = cursor.All(ctx, &adReferences); err != nil {
 		return AdReferencesPaginatedResult{}, err
 	}
 
-	total, err := db.Collection(collection).CountDocuments(context.Background(), filter.GetFilter())
+	total, err := db.Collection(collection).CountDocuments(ctx, filter.GetFilter())
 
 	return AdReferencesPaginatedResult{
 		Total:   total,
@@ -72,28 +56,14 @@ func GetFilteredAdReferences(filter commons.FilterRequest) (AdReferencesPaginate
 	}, nil
 }
 
-func GetAdReferences() ([]AdReference, error) {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return nil, err		
-	}
-
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()	
-
-	db := client.Database(mongodb_database)
+func GetAdReferences(ctx context.Context, db *models.DbContext) ([]AdReference, error) {
 	collection := db.Collection("ad_references")
 
 	filter := bson.M{
 		"is_active": true,
 	}
 
-	cursor, err := collection.Find(context.Background(), filter)
+	cursor, err := collection.Find(ctx, filter)
 	
 	if err != nil {
 		return nil, err
@@ -101,7 +71,7 @@ func GetAdReferences() ([]AdReference, error) {
 
 	var adReferences []AdReference
 
-	for cursor.Next(context.Background()) {
+	for cursor.Next(ctx) {
 		var adReference AdReference
 		err := cursor.Decode(&adReference)
 
@@ -115,21 +85,7 @@ func GetAdReferences() ([]AdReference, error) {
 	return adReferences, nil
 }
 
-func GetAdReference(id string) (AdReference, error) {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return AdReference{}, err
-	}
-
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
+func GetAdReference(ctx context.Context, db *models.DbContext, id string) (AdReference, error) {
 	collection := db.Collection("ad_references")
 
 	idHex, err := primitive.ObjectIDFromHex(id)
@@ -144,7 +100,7 @@ func GetAdReference(id string) (AdReference, error) {
 
 	var adReference AdReference
 
-	err = collection.FindOne(context.Background(), filter).Decode(&adReference)
+	err = collection.FindOne(ctx, filter).Decode(&adReference)
 
 	if err != nil {
 		return AdReference{}, err
@@ -153,27 +109,13 @@ func GetAdReference(id string) (AdReference, error) {
 	return adReference, nil
 }
 
-func CreateAdReference(adReference AdReference) error {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
+func CreateAdReference(ctx context.Context, db *models.DbContext, adReference AdReference) error {
 	collection := db.Collection("ad_references")
 
 	adReference.Id = primitive.NewObjectID()
 	adReference.CreatedAt =commons.GetBrasiliaTime()
 
-	_, err = collection.InsertOne(context.Background(), adReference)
+	_, err := collection.InsertOne(ctx, adReference)
 
 	if err != nil {
 		return err
@@ -182,21 +124,7 @@ func CreateAdReference(adReference AdReference) error {
 	return nil
 }
 
-func DeleteAdReference(id string) error {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
+func DeleteAdReference(ctx context.Context, db *models.DbContext, id string) error {
 	collection := db.Collection("ad_references")
 
 	idHex, err := primitive.ObjectIDFromHex(id)
@@ -209,7 +137,7 @@ func DeleteAdReference(id string) error {
 		"_id": idHex,
 	}
 
-	_, err = collection.DeleteOne(context.Background(), filter)
+	_, err = collection.DeleteOne(ctx, filter)
 
 	if err != nil {
 		return err
@@ -218,32 +146,20 @@ func DeleteAdReference(id string) error {
 	return nil
 }
 
-func UpdateAdReference(adReference AdReference) error {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
+func UpdateAdReference(ctx context.Context, db *models.DbContext, adReference AdReference) error {
 	collection := db.Collection("ad_references")
 
 	filter := bson.M{
 		"_id": adReference.Id,
 	}
 
+	adReference.LastUpdate = commons.GetBrasiliaTime()
+
 	update := bson.M{
 		"$set": adReference,
 	}
 
-	_, err = collection.UpdateOne(context.Background(), filter, update)
+	_, err := collection.UpdateOne(ctx, filter, update)
 
 	if err != nil {
 		return err
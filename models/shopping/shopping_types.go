@@ -3,16 +3,18 @@ package shopping
 import (
 	"time"
 
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type AdReference struct {
 	Id 				primitive.ObjectID 	`json:"id" bson:"_id"`
-	Description 	string 				`json:"description" bson:"description"`
+	Description 	string 				`json:"description" bson:"description" binding:"required,max=500"`
 	IsActive 		bool 				`json:"is_active" bson:"is_active"`
 	CreatedAt 		time.Time 			`json:"created_at" bson:"created_at"`
-	ImageUrl 		string 				`json:"image_url" bson:"image_url"`
-	Url 			string 				`json:"url" bson:"url"`
+	LastUpdate 		time.Time 			`json:"last_update" bson:"last_update"`
+	ImageUrl 		string 				`json:"image_url" bson:"image_url" binding:"omitempty,http_url,max=2048"`
+	Url 			string 				`json:"url" bson:"url" binding:"omitempty,http_url,max=2048"`
 }
 
 type AdReferencesPaginatedResult struct {
@@ -20,4 +22,9 @@ type AdReferencesPaginatedResult struct {
 	Page    int
 	PerPage int
 	Data    []AdReference
-}
\ No newline at end of file
+}
+
+// ToPage returns the result in the envelope of the v2 API.
+func (result AdReferencesPaginatedResult) ToPage() commons.Page[AdReference] {
+	return commons.NewPage(result.Data, result.Total, result.Page, result.PerPage)
+}
@@ -0,0 +1,118 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RecordFailedLogin counts a failed login of the user with the email and
+// returns the user as updated, or an empty user when there is none.
+func RecordFailedLogin(ctx context.Context, db *models.DbContext, email string) (User, error) {
+
+	filter := bson.D{
+		{Key: "email", Value: strings.ToLower(email)},
+		{Key: "is_deleted", Value: false},
+	}
+	update := bson.D{
+		{Key: "$inc", Value: bson.D{{Key: "failed_login_attempts", Value: 1}}},
+		{Key: "$set", Value: bson.D{{Key: "last_failed_login_at", Value: primitive.NewDateTimeFromTime(time.Now().UTC())}}},
+	}
+	options := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var result User
+	err := db.Collection("users").FindOneAndUpdate(ctx, filter, update, options).Decode(&result)
+
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return User{}, nil
+	}
+
+	return result, err
+}
+
+// LockUser locks the user until user.LockedUntil, keeping user.UnlockToken
+// for the unlock link. The failed logins start again from zero.
+func LockUser(ctx context.Context, db *models.DbContext, user *User) error {
+
+	filter := bson.D{{Key: "_id", Value: user.Id}}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "locked_until", Value: user.LockedUntil},
+			{Key: "unlock_token", Value: user.UnlockToken},
+			{Key: "failed_login_attempts", Value: 0},
+		}},
+		{Key: "$inc", Value: bson.D{{Key: "lockouts", Value: 1}}},
+	}
+
+	_, err := db.Collection("users").UpdateOne(ctx, filter, update)
+
+	return err
+}
+
+// UnlockUser clears the lockout and the failed logins of the user.
+func UnlockUser(ctx context.Context, db *models.DbContext, id primitive.ObjectID) error {
+
+	filter := bson.D{{Key: "_id", Value: id}}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "failed_login_attempts", Value: 0}}},
+		{Key: "$unset", Value: bson.D{
+			{Key: "locked_until", Value: ""},
+			{Key: "unlock_token", Value: ""},
+		}},
+	}
+
+	_, err := db.Collection("users").UpdateOne(ctx, filter, update)
+
+	return err
+}
+
+func GetUserByUnlockToken(ctx context.Context, db *models.DbContext, token string) (User, error) {
+
+	if token == "" {
+		return User{}, nil
+	}
+
+	filter := bson.D{{Key: "unlock_token", Value: token}}
+	var result User
+	err := db.Collection("users").FindOne(ctx, filter).Decode(&result)
+
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return User{}, nil
+	}
+
+	return result, err
+}
+
+// GetLockedUsers returns the users locked out now, the ones locked the
+// longest ahead first.
+func GetLockedUsers(ctx context.Context, db *models.DbContext) ([]UserLockout, error) {
+
+	filter := bson.D{{Key: "locked_until", Value: bson.D{{Key: "$gt", Value: primitive.NewDateTimeFromTime(time.Now().UTC())}}}}
+	options := options.Find().SetSort(bson.D{{Key: "locked_until", Value: -1}})
+
+	cursor, err := db.Collection("users").Find(ctx, filter, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := []UserLockout{}
+
+	if err = cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// IsLocked tells whether the user is locked out at the time.
+func (user *User) IsLocked(at time.Time) bool {
+	return at.Before(user.LockedUntil.Time())
+}
@@ -0,0 +1,427 @@
+package users
+
+import (
+	"context"
+
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/roles"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InMemoryUserRepository keeps the users in memory, applying the same filters,
+// sorting and pagination as MongoUserRepository. The roles resolved by
+// GetUserRoles are the ones given to the constructor. It is meant for tests.
+type InMemoryUserRepository struct {
+	mutex sync.Mutex
+	users []User
+	roles []roles.Role
+}
+
+func NewInMemoryUserRepository(roles ...roles.Role) *InMemoryUserRepository {
+	return &InMemoryUserRepository{roles: roles}
+}
+
+// AddUser stores the user as it is, without the defaults set by CreateUser.
+func (repository *InMemoryUserRepository) AddUser(user User) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.users = append(repository.users, user)
+}
+
+func (repository *InMemoryUserRepository) CreateUser(ctx context.Context, user User) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.users = append(repository.users, newUser(user))
+
+	return nil
+}
+
+func (repository *InMemoryUserRepository) IsAuthenticated(ctx context.Context, user *User) (bool, error) {
+	result, found := repository.findOne(func(item User) bool {
+		return item.Email == strings.ToLower(user.Email) && !item.IsDeleted
+	})
+
+	if !found {
+		return false, nil
+	}
+
+	return isPasswordValid(result, user.Password)
+}
+
+func (repository *InMemoryUserRepository) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	result, _ := repository.findOne(func(item User) bool {
+		return item.Email == strings.ToLower(email)
+	})
+
+	return result, nil
+}
+
+func (repository *InMemoryUserRepository) GetUserById(ctx context.Context, id primitive.ObjectID) (User, error) {
+	result, _ := repository.findOne(func(item User) bool {
+		return item.Id == id
+	})
+
+	return result, nil
+}
+
+func (repository *InMemoryUserRepository) GetUserByUserName(ctx context.Context, userName string) (User, error) {
+	result, _ := repository.findOne(func(item User) bool {
+		return item.UserName == strings.ToLower(userName) && !item.IsDeleted && item.IsEmailConfirmed && item.IsPublic
+	})
+
+	return result, nil
+}
+
+func (repository *InMemoryUserRepository) GetUserByValidationToken(ctx context.Context, token string) (User, error) {
+	result, _ := repository.findOne(func(item User) bool {
+		return item.ValidationToken == token
+	})
+
+	return result, nil
+}
+
+func (repository *InMemoryUserRepository) GetUserRoles(ctx context.Context, id primitive.ObjectID) ([]string, error) {
+	result, found := repository.findOne(func(item User) bool {
+		return item.Id == id
+	})
+
+	if !found || result.Roles == nil {
+		return []string{}, nil
+	}
+
+	return getRoleNames(result.Roles, repository.roles), nil
+}
+
+func (repository *InMemoryUserRepository) GetUsers(ctx context.Context, filter commons.FilterRequest) (UsersPaginatedResult, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	query, err := commons.NewPageQuery(filter.Sort, filter.IsAscending, filter.Page, filter.PageSize, filter.Cursor)
+
+	if err != nil {
+		return UsersPaginatedResult{}, err
+	}
+
+	documents, total, err := commons.FindPage(repository.users, filter.GetFilter(), query)
+
+	if err != nil {
+		return UsersPaginatedResult{}, err
+	}
+
+	items, cursors, err := commons.DecodePage[UserView](query, documents)
+
+	if err != nil {
+		return UsersPaginatedResult{}, err
+	}
+
+	if filter.SkipTotal {
+		total = commons.NO_TOTAL
+	}
+
+	return UsersPaginatedResult{
+		Total:      int64(total),
+		Page:       query.Page,
+		PerPage:    query.PageSize,
+		Data:       items,
+		NextCursor: cursors.Next,
+		PrevCursor: cursors.Prev,
+	}, nil
+}
+
+func (repository *InMemoryUserRepository) GetTalents(ctx context.Context, filter commons.FilterRequest, is_admin bool, is_recruiter bool) (UserTalentsPaginatedResult, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	query, err := commons.NewPageQuery(filter.Sort, filter.IsAscending, filter.Page, filter.PageSize, filter.Cursor)
+
+	if err != nil {
+		return UserTalentsPaginatedResult{}, err
+	}
+
+	documents, total, err := commons.FindPage(repository.users, getTalentsFilter(filter, is_admin, is_recruiter), query)
+
+	if err != nil {
+		return UserTalentsPaginatedResult{}, err
+	}
+
+	items, cursors, err := commons.DecodePage[UserTalentView](query, documents)
+
+	if err != nil {
+		return UserTalentsPaginatedResult{}, err
+	}
+
+	if filter.SkipTotal {
+		total = commons.NO_TOTAL
+	}
+
+	return UserTalentsPaginatedResult{
+		Total:      int64(total),
+		Page:       query.Page,
+		PerPage:    query.PageSize,
+		Data:       items,
+		NextCursor: cursors.Next,
+		PrevCursor: cursors.Prev,
+	}, nil
+}
+
+func (repository *InMemoryUserRepository) UserNameAlreadyExists(ctx context.Context, userName string) (bool, error) {
+	_, found := repository.findOne(func(item User) bool {
+		return item.UserName == strings.ToLower(userName)
+	})
+
+	return found, nil
+}
+
+func (repository *InMemoryUserRepository) Update(ctx context.Context, user *User) error {
+	user.setDefaultJobLocations()
+
+	repository.updateOne(user.Id, func(item *User) {
+		item.FirstName = user.FirstName
+		item.LastName = user.LastName
+		item.City = user.City
+		item.State = user.State
+		item.Links = user.Links
+		item.Experiences = user.Experiences
+		item.UserName = strings.ToLower(user.UserName)
+		item.LastUpdate = user.LastUpdate
+		item.AboutMe = user.AboutMe
+		item.IsPublic = user.IsPublic
+		item.TechExperiences = user.TechExperiences
+		item.Educations = user.Educations
+		item.Certifications = user.Certifications
+		item.JobPreference = user.JobPreference
+		item.DiversityInfo = user.DiversityInfo
+		item.IdiomsInfo = user.IdiomsInfo
+		item.IsPublicForRecruiter = user.IsPublicForRecruiter
+	})
+
+	return nil
+}
+
+func (repository *InMemoryUserRepository) UpdateProfile(ctx context.Context, user *User) error {
+	repository.updateOne(user.Id, func(item *User) {
+		item.FirstName = user.FirstName
+		item.LastName = user.LastName
+		item.City = user.City
+		item.State = user.State
+		item.Links = user.Links
+		item.LastUpdate = now()
+		item.AboutMe = user.AboutMe
+		item.TechExperiences = user.TechExperiences
+		item.IdiomsInfo = user.IdiomsInfo
+		item.Certifications = user.Certifications
+		item.Educations = user.Educations
+		item.Experiences = user.Experiences
+		item.IsPublic = user.IsPublic
+		item.ProfileImageUrl = user.ProfileImageUrl
+		item.OAuthImageURL = user.OAuthImageURL
+	})
+
+	return nil
+}
+
+func (repository *InMemoryUserRepository) UpdateLastLogin(ctx context.Context, user *User) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for i := range repository.users {
+		if repository.users[i].Email == strings.ToLower(user.Email) {
+			repository.users[i].LastLogin = now()
+			break
+		}
+	}
+
+	return nil
+}
+
+func (repository *InMemoryUserRepository) IncrementProfileViews(ctx context.Context, user *User) error {
+	repository.updateOne(user.Id, func(item *User) {
+		item.ProfileViews++
+	})
+
+	return nil
+}
+
+func (repository *InMemoryUserRepository) ConfirmEmail(ctx context.Context, user *User) error {
+	repository.updateOne(user.Id, func(item *User) {
+		item.IsEmailConfirmed = true
+		item.LastUpdate = now()
+	})
+
+	return nil
+}
+
+func (repository *InMemoryUserRepository) UpdateUserBookmarkedJobs(ctx context.Context, user *User) error {
+	repository.updateOne(user.Id, func(item *User) {
+		item.BookmarkedJobs = user.BookmarkedJobs
+		item.LastUpdate = now()
+	})
+
+	return nil
+}
+
+func (repository *InMemoryUserRepository) UpdateValidationToken(ctx context.Context, user User) error {
+	repository.updateOne(user.Id, func(item *User) {
+		item.ValidationToken = user.ValidationToken
+	})
+
+	return nil
+}
+
+func (repository *InMemoryUserRepository) UpdatePassword(ctx context.Context, user *User, password string) error {
+	user.Password = password
+	user.SetSaltedPassword()
+
+	repository.updateOne(user.Id, func(item *User) {
+		item.Password = user.Password
+		item.Salt = user.Salt
+	})
+
+	return nil
+}
+
+func (repository *InMemoryUserRepository) UpdateProfilePicture(ctx context.Context, user *User) error {
+	repository.updateOne(user.Id, func(item *User) {
+		item.ProfileImageUrl = user.ProfileImageUrl
+		item.LastUpdate = now()
+	})
+
+	return nil
+}
+
+func (repository *InMemoryUserRepository) UpdateUserName(ctx context.Context, user *User) error {
+	repository.updateOne(user.Id, func(item *User) {
+		item.UserName = user.UserName
+		item.LastUpdate = now()
+	})
+
+	return nil
+}
+
+func (repository *InMemoryUserRepository) DeleteUser(ctx context.Context, id primitive.ObjectID) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for i, user := range repository.users {
+		if user.Id == id {
+			repository.users = append(repository.users[:i], repository.users[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (repository *InMemoryUserRepository) RecordFailedLogin(ctx context.Context, email string) (User, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for i := range repository.users {
+		if repository.users[i].Email == strings.ToLower(email) && !repository.users[i].IsDeleted {
+			repository.users[i].FailedLoginAttempts++
+			repository.users[i].LastFailedLoginAt = now()
+			return repository.users[i], nil
+		}
+	}
+
+	return User{}, nil
+}
+
+func (repository *InMemoryUserRepository) LockUser(ctx context.Context, user *User) error {
+	repository.updateOne(user.Id, func(item *User) {
+		item.LockedUntil = user.LockedUntil
+		item.UnlockToken = user.UnlockToken
+		item.FailedLoginAttempts = 0
+		item.Lockouts++
+	})
+
+	return nil
+}
+
+func (repository *InMemoryUserRepository) UnlockUser(ctx context.Context, id primitive.ObjectID) error {
+	repository.updateOne(id, func(item *User) {
+		item.FailedLoginAttempts = 0
+		item.LockedUntil = primitive.DateTime(0)
+		item.UnlockToken = ""
+	})
+
+	return nil
+}
+
+func (repository *InMemoryUserRepository) GetUserByUnlockToken(ctx context.Context, token string) (User, error) {
+	result, _ := repository.findOne(func(item User) bool {
+		return token != "" && item.UnlockToken == token
+	})
+
+	return result, nil
+}
+
+func (repository *InMemoryUserRepository) GetLockedUsers(ctx context.Context) ([]UserLockout, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	locked := []User{}
+
+	for _, user := range repository.users {
+		if user.IsLocked(time.Now()) {
+			locked = append(locked, user)
+		}
+	}
+
+	sort.Slice(locked, func(i, j int) bool {
+		return locked[i].LockedUntil > locked[j].LockedUntil
+	})
+
+	result := []UserLockout{}
+
+	for _, user := range locked {
+		result = append(result, UserLockout{
+			Id:                  user.Id,
+			FirstName:           user.FirstName,
+			LastName:            user.LastName,
+			Email:               user.Email,
+			FailedLoginAttempts: user.FailedLoginAttempts,
+			LastFailedLoginAt:   user.LastFailedLoginAt,
+			LockedUntil:         user.LockedUntil,
+			Lockouts:            user.Lockouts,
+		})
+	}
+
+	return result, nil
+}
+
+func (repository *InMemoryUserRepository) findOne(match func(user User) bool) (User, bool) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for _, user := range repository.users {
+		if match(user) {
+			return user, true
+		}
+	}
+
+	return User{}, false
+}
+
+func (repository *InMemoryUserRepository) updateOne(id primitive.ObjectID, update func(user *User)) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for i := range repository.users {
+		if repository.users[i].Id == id {
+			update(&repository.users[i])
+			return
+		}
+	}
+}
+
+func now() primitive.DateTime {
+	return primitive.NewDateTimeFromTime(time.Now().UTC())
+}
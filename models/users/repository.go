@@ -0,0 +1,181 @@
+package users
+
+import (
+	"context"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/cache"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserRepository is the storage used by the users controller, the OAuth
+// handlers and the authorization middleware.
+type UserRepository interface {
+	CreateUser(ctx context.Context, user User) error
+	IsAuthenticated(ctx context.Context, user *User) (bool, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserById(ctx context.Context, id primitive.ObjectID) (User, error)
+	GetUserByUserName(ctx context.Context, userName string) (User, error)
+	GetUserByValidationToken(ctx context.Context, token string) (User, error)
+	GetUserRoles(ctx context.Context, id primitive.ObjectID) ([]string, error)
+	GetUsers(ctx context.Context, filter commons.FilterRequest) (UsersPaginatedResult, error)
+	GetTalents(ctx context.Context, filter commons.FilterRequest, is_admin bool, is_recruiter bool) (UserTalentsPaginatedResult, error)
+	UserNameAlreadyExists(ctx context.Context, userName string) (bool, error)
+	Update(ctx context.Context, user *User) error
+	UpdateProfile(ctx context.Context, user *User) error
+	UpdateLastLogin(ctx context.Context, user *User) error
+	IncrementProfileViews(ctx context.Context, user *User) error
+	ConfirmEmail(ctx context.Context, user *User) error
+	UpdateUserBookmarkedJobs(ctx context.Context, user *User) error
+	UpdateValidationToken(ctx context.Context, user User) error
+	UpdatePassword(ctx context.Context, user *User, password string) error
+	UpdateProfilePicture(ctx context.Context, user *User) error
+	UpdateUserName(ctx context.Context, user *User) error
+	DeleteUser(ctx context.Context, id primitive.ObjectID) error
+	RecordFailedLogin(ctx context.Context, email string) (User, error)
+	LockUser(ctx context.Context, user *User) error
+	UnlockUser(ctx context.Context, id primitive.ObjectID) error
+	GetUserByUnlockToken(ctx context.Context, token string) (User, error)
+	GetLockedUsers(ctx context.Context) ([]UserLockout, error)
+}
+
+type MongoUserRepository struct {
+	db    *models.DbContext
+	roles cache.Store
+}
+
+func NewMongoUserRepository(db *models.DbContext, store cache.Store) *MongoUserRepository {
+	return &MongoUserRepository{db: db, roles: cache.Namespace(store, "roles")}
+}
+
+func (repository *MongoUserRepository) CreateUser(ctx context.Context, user User) error {
+	defer metrics.ObserveMongoOperation("users", "CreateUser")()
+	return CreateUser(ctx, repository.db, user)
+}
+
+func (repository *MongoUserRepository) IsAuthenticated(ctx context.Context, user *User) (bool, error) {
+	defer metrics.ObserveMongoOperation("users", "IsAuthenticated")()
+	return user.IsAuthenticated(ctx, repository.db)
+}
+
+func (repository *MongoUserRepository) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	defer metrics.ObserveMongoOperation("users", "GetUserByEmail")()
+	return GetUserByEmail(ctx, repository.db, email)
+}
+
+func (repository *MongoUserRepository) GetUserById(ctx context.Context, id primitive.ObjectID) (User, error) {
+	defer metrics.ObserveMongoOperation("users", "GetUserById")()
+	return GetUserById(ctx, repository.db, id)
+}
+
+func (repository *MongoUserRepository) GetUserByUserName(ctx context.Context, userName string) (User, error) {
+	defer metrics.ObserveMongoOperation("users", "GetUserByUserName")()
+	return GetUserByUserName(ctx, repository.db, userName)
+}
+
+func (repository *MongoUserRepository) GetUserByValidationToken(ctx context.Context, token string) (User, error) {
+	defer metrics.ObserveMongoOperation("users", "GetUserByValidationToken")()
+	return GetUserByValidationToken(ctx, repository.db, token)
+}
+
+func (repository *MongoUserRepository) GetUserRoles(ctx context.Context, id primitive.ObjectID) ([]string, error) {
+	defer metrics.ObserveMongoOperation("users", "GetUserRoles")()
+	return GetUserRoles(ctx, repository.db, repository.roles, id)
+}
+
+func (repository *MongoUserRepository) GetUsers(ctx context.Context, filter commons.FilterRequest) (UsersPaginatedResult, error) {
+	defer metrics.ObserveMongoOperation("users", "GetUsers")()
+	return GetUsers(ctx, repository.db, filter)
+}
+
+func (repository *MongoUserRepository) GetTalents(ctx context.Context, filter commons.FilterRequest, is_admin bool, is_recruiter bool) (UserTalentsPaginatedResult, error) {
+	defer metrics.ObserveMongoOperation("users", "GetTalents")()
+	return GetTalents(ctx, repository.db, filter, is_admin, is_recruiter)
+}
+
+func (repository *MongoUserRepository) UserNameAlreadyExists(ctx context.Context, userName string) (bool, error) {
+	defer metrics.ObserveMongoOperation("users", "UserNameAlreadyExists")()
+	return UserNameAlreadyExists(ctx, repository.db, userName)
+}
+
+func (repository *MongoUserRepository) Update(ctx context.Context, user *User) error {
+	defer metrics.ObserveMongoOperation("users", "Update")()
+	return Update(ctx, repository.db, user)
+}
+
+func (repository *MongoUserRepository) UpdateProfile(ctx context.Context, user *User) error {
+	defer metrics.ObserveMongoOperation("users", "UpdateProfile")()
+	return user.Update(ctx, repository.db)
+}
+
+func (repository *MongoUserRepository) UpdateLastLogin(ctx context.Context, user *User) error {
+	defer metrics.ObserveMongoOperation("users", "UpdateLastLogin")()
+	return user.UpdateLastLogin(ctx, repository.db)
+}
+
+func (repository *MongoUserRepository) IncrementProfileViews(ctx context.Context, user *User) error {
+	defer metrics.ObserveMongoOperation("users", "IncrementProfileViews")()
+	return user.IncrementProfileViews(ctx, repository.db)
+}
+
+func (repository *MongoUserRepository) ConfirmEmail(ctx context.Context, user *User) error {
+	defer metrics.ObserveMongoOperation("users", "ConfirmEmail")()
+	return user.ConfirmEmail(ctx, repository.db)
+}
+
+func (repository *MongoUserRepository) UpdateUserBookmarkedJobs(ctx context.Context, user *User) error {
+	defer metrics.ObserveMongoOperation("users", "UpdateUserBookmarkedJobs")()
+	return user.UpdateUserBookmarkedJobs(ctx, repository.db)
+}
+
+func (repository *MongoUserRepository) UpdateValidationToken(ctx context.Context, user User) error {
+	defer metrics.ObserveMongoOperation("users", "UpdateValidationToken")()
+	return UpdateValidationToken(ctx, repository.db, user)
+}
+
+func (repository *MongoUserRepository) UpdatePassword(ctx context.Context, user *User, password string) error {
+	defer metrics.ObserveMongoOperation("users", "UpdatePassword")()
+	return user.UpdatePassword(ctx, repository.db, password)
+}
+
+func (repository *MongoUserRepository) UpdateProfilePicture(ctx context.Context, user *User) error {
+	defer metrics.ObserveMongoOperation("users", "UpdateProfilePicture")()
+	return user.UpdateProfilePicture(ctx, repository.db)
+}
+
+func (repository *MongoUserRepository) UpdateUserName(ctx context.Context, user *User) error {
+	defer metrics.ObserveMongoOperation("users", "UpdateUserName")()
+	return user.UpdateUserName(ctx, repository.db)
+}
+
+func (repository *MongoUserRepository) DeleteUser(ctx context.Context, id primitive.ObjectID) error {
+	defer metrics.ObserveMongoOperation("users", "DeleteUser")()
+	return DeleteUser(ctx, repository.db, id)
+}
+
+func (repository *MongoUserRepository) RecordFailedLogin(ctx context.Context, email string) (User, error) {
+	defer metrics.ObserveMongoOperation("users", "RecordFailedLogin")()
+	return RecordFailedLogin(ctx, repository.db, email)
+}
+
+func (repository *MongoUserRepository) LockUser(ctx context.Context, user *User) error {
+	defer metrics.ObserveMongoOperation("users", "LockUser")()
+	return LockUser(ctx, repository.db, user)
+}
+
+func (repository *MongoUserRepository) UnlockUser(ctx context.Context, id primitive.ObjectID) error {
+	defer metrics.ObserveMongoOperation("users", "UnlockUser")()
+	return UnlockUser(ctx, repository.db, id)
+}
+
+func (repository *MongoUserRepository) GetUserByUnlockToken(ctx context.Context, token string) (User, error) {
+	defer metrics.ObserveMongoOperation("users", "GetUserByUnlockToken")()
+	return GetUserByUnlockToken(ctx, repository.db, token)
+}
+
+func (repository *MongoUserRepository) GetLockedUsers(ctx context.Context) ([]UserLockout, error) {
+	defer metrics.ObserveMongoOperation("users", "GetLockedUsers")()
+	return GetLockedUsers(ctx, repository.db)
+}
@@ -0,0 +1,65 @@
+package tokens
+
+import (
+	"context"
+
+	"sync"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InMemoryTokenStore keeps the refresh tokens in memory. It is meant for tests.
+type InMemoryTokenStore struct {
+	mutex  sync.Mutex
+	auth   config.AuthConfig
+	tokens map[primitive.ObjectID]UserToken
+}
+
+func NewInMemoryTokenStore(auth config.AuthConfig) *InMemoryTokenStore {
+	return &InMemoryTokenStore{auth: auth, tokens: map[primitive.ObjectID]UserToken{}}
+}
+
+func (store *InMemoryTokenStore) SaveRefreshToken(ctx context.Context, userInfo users.UserTokenInfo) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	refreshToken := UserToken{UserId: userInfo.Id}
+
+	err := refreshToken.SetAuthenticationToken(store.auth, userInfo, getExpirationRefreshToken())
+
+	if err != nil {
+		return err
+	}
+
+	userToken, found := store.tokens[userInfo.Id]
+
+	if !found {
+		userToken = UserToken{
+			Id:        primitive.NewObjectID(),
+			UserId:    userInfo.Id,
+			CreatedAt: primitive.NewDateTimeFromTime(time.Now().UTC()),
+		}
+	}
+
+	userToken.Token = refreshToken.Token
+	userToken.ExpirationDate = refreshToken.ExpirationDate
+	userToken.UpdatedAt = primitive.NewDateTimeFromTime(time.Now().UTC())
+
+	store.tokens[userInfo.Id] = userToken
+
+	return nil
+}
+
+func (store *InMemoryTokenStore) GetRefreshToken(ctx context.Context, userId primitive.ObjectID) (UserToken, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if userToken, found := store.tokens[userId]; found {
+		return userToken, nil
+	}
+
+	return UserToken{UserId: userId}, nil
+}
@@ -0,0 +1,40 @@
+package tokens
+
+import (
+	"context"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TokenStore keeps the refresh tokens of the users.
+type TokenStore interface {
+	SaveRefreshToken(ctx context.Context, userInfo users.UserTokenInfo) error
+	// GetRefreshToken returns the refresh token of the user, with an empty
+	// Token when the user has none.
+	GetRefreshToken(ctx context.Context, userId primitive.ObjectID) (UserToken, error)
+}
+
+type MongoTokenStore struct {
+	db   *models.DbContext
+	auth config.AuthConfig
+}
+
+func NewMongoTokenStore(db *models.DbContext, auth config.AuthConfig) *MongoTokenStore {
+	return &MongoTokenStore{db: db, auth: auth}
+}
+
+func (store *MongoTokenStore) SaveRefreshToken(ctx context.Context, userInfo users.UserTokenInfo) error {
+	defer metrics.ObserveMongoOperation("tokens", "SaveRefreshToken")()
+	return SaveRefreshToken(ctx, store.db, store.auth, userInfo)
+}
+
+func (store *MongoTokenStore) GetRefreshToken(ctx context.Context, userId primitive.ObjectID) (UserToken, error) {
+	defer metrics.ObserveMongoOperation("tokens", "GetRefreshToken")()
+	userToken := UserToken{UserId: userId}
+	err := userToken.SetRefreshToken(ctx, store.db)
+	return userToken, err
+}
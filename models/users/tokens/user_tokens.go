@@ -5,12 +5,12 @@ import (
 	"errors"
 	"math/rand"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users"
 	"github.com/golang-jwt/jwt/v5"
@@ -50,10 +50,10 @@ func getExpirationRefreshToken() time.Time {
 }
 
 // SetToken - Set the token for the user with a default expiration time
-func (userToken *UserToken) SetToken(userInfo users.UserTokenInfo) (error)  {
+func (userToken *UserToken) SetToken(auth config.AuthConfig, userInfo users.UserTokenInfo) (error)  {
 
 	expirationDateTime := getExpirationToken()
-	err := userToken.SetAuthenticationToken(userInfo, expirationDateTime)
+	err := userToken.SetAuthenticationToken(auth, userInfo, expirationDateTime)
 	
 	if err != nil {
 		return err
@@ -63,7 +63,7 @@ func (userToken *UserToken) SetToken(userInfo users.UserTokenInfo) (error)  {
 }
 
 // SetAuthenticationToken - Set the authentication token for the user with an expiration time
-func (userToken *UserToken) SetAuthenticationToken(userInfo users.UserTokenInfo, expirationDateTime time.Time) (error) {
+func (userToken *UserToken) SetAuthenticationToken(auth config.AuthConfig, userInfo users.UserTokenInfo, expirationDateTime time.Time) (error) {
 
 	var (
 		key   []byte
@@ -71,7 +71,7 @@ func (userToken *UserToken) SetAuthenticationToken(userInfo users.UserTokenInfo,
 		token string
 	)
 
-	key = []byte(os.Getenv("JWT_SECRET"))
+	key = []byte(auth.JwtSecret)
 
 	if userInfo.Roles == nil {
 		userInfo.Roles = []primitive.ObjectID{}
@@ -121,14 +121,14 @@ func GenerateValidationToken() string {
 	return string(b)
 }
 
-func ExtractUserInfoFromTokenString(token string) (users.UserTokenInfo, error) {
+func ExtractUserInfoFromTokenString(auth config.AuthConfig, token string) (users.UserTokenInfo, error) {
 
 	var (
 		key   []byte		
 		claim jwt.MapClaims
 	)
 
-	key = []byte(os.Getenv("JWT_SECRET"))
+	key = []byte(auth.JwtSecret)
 
 	t, _ := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
 		return key, nil
@@ -140,14 +140,14 @@ func ExtractUserInfoFromTokenString(token string) (users.UserTokenInfo, error) {
 }
 
 
-func GetUserInfoFromTokenString(token string) (users.UserTokenInfo, error) {
+func GetUserInfoFromTokenString(auth config.AuthConfig, token string) (users.UserTokenInfo, error) {
 	
 	var (
 		key   []byte		
 		claim jwt.MapClaims
 	)
 
-	key = []byte(os.Getenv("JWT_SECRET"))
+	key = []byte(auth.JwtSecret)
 
 	t, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
 		return key, nil
@@ -166,7 +166,7 @@ func GetUserInfoFromTokenString(token string) (users.UserTokenInfo, error) {
 	return GetUserInfo(claim)
 }
 
-func ExtractUserInfoForTokenRefresh(context *gin.Context) (users.UserTokenInfo, error) {
+func ExtractUserInfoForTokenRefresh(auth config.AuthConfig, context *gin.Context) (users.UserTokenInfo, error) {
 	
 	token, _ := context.Cookie(TOKEN_NAME)
 
@@ -185,10 +185,10 @@ func ExtractUserInfoForTokenRefresh(context *gin.Context) (users.UserTokenInfo,
 		}		
 	}
 
-	return ExtractUserInfoFromTokenString(token)
+	return ExtractUserInfoFromTokenString(auth, token)
 }
 
-func GetUserInfoFromContext(context *gin.Context) (users.UserTokenInfo, error) {
+func GetUserInfoFromContext(auth config.AuthConfig, context *gin.Context) (users.UserTokenInfo, error) {
 
 	token, err := context.Cookie(TOKEN_NAME)
 
@@ -211,7 +211,7 @@ func GetUserInfoFromContext(context *gin.Context) (users.UserTokenInfo, error) {
 		}
 	}
 
-	return GetUserInfoFromTokenString(token)
+	return GetUserInfoFromTokenString(auth, token)
 }
 
 func GetUserInfo(jwtClaims jwt.MapClaims) (users.UserTokenInfo, error) {
@@ -254,14 +254,14 @@ func GetUserInfo(jwtClaims jwt.MapClaims) (users.UserTokenInfo, error) {
 	return userInfo, nil
 }
 
-func (userToken *UserToken) SetTokenCookie(c *gin.Context) {
+func (userToken *UserToken) SetTokenCookie(auth config.AuthConfig, c *gin.Context) {
 
 	if userToken.ExpirationDate.Time().IsZero() {
 		panic("Expiration date is required")
 	}
 
-    secure := os.Getenv("COOKIE_SECURE") == "true"
-	domain := os.Getenv("COOKIE_DOMAIN")
+    secure := auth.CookieSecure
+	domain := auth.CookieDomain
 	c.SetSameSite(http.SameSiteLaxMode)	
     c.SetCookie(
         TOKEN_NAME,
@@ -274,51 +274,35 @@ func (userToken *UserToken) SetTokenCookie(c *gin.Context) {
     )	
 }
 
-func DeleteTokenCookie(c *gin.Context) {
+func DeleteTokenCookie(auth config.AuthConfig, c *gin.Context) {
 	c.SetSameSite(http.SameSiteLaxMode)	
-	secure := os.Getenv("COOKIE_SECURE") == "true"
+	secure := auth.CookieSecure
 	c.SetCookie(
 		TOKEN_NAME,
 		"",
 		-1,
 		"/",
-		os.Getenv("COOKIE_DOMAIN"),
+		auth.CookieDomain,
 		secure,
 		true,		
 	)
 }
 
-func SaveRefreshToken(userInfo users.UserTokenInfo) error {
+func SaveRefreshToken(ctx context.Context, db *models.DbContext, auth config.AuthConfig, userInfo users.UserTokenInfo) error {
 
 	userRefershToken := UserToken{
 		UserId: userInfo.Id,
 		ExpirationDate: primitive.NewDateTimeFromTime(getExpirationRefreshToken()),
 	}
 
-	userRefershToken.SetAuthenticationToken(userInfo, getExpirationRefreshToken())
-
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-
-	client, err := models.Connect()
-
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	if err != nil {
-		return err
-	}
-
-	db := client.Database(mongodb_database)
+	userRefershToken.SetAuthenticationToken(auth, userInfo, getExpirationRefreshToken())
 
 	// Check if user already exists
 	filter := bson.D{{Key: "user_id", Value: userInfo.Id}}
 
 	fetched_user_token := UserToken{}
 	
-	err = db.Collection(users.USERS_TOKENS_COLLECTION).FindOne(context.Background(), filter).Decode(&fetched_user_token)
+	err := db.Collection(users.USERS_TOKENS_COLLECTION).FindOne(ctx, filter).Decode(&fetched_user_token)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -330,7 +314,7 @@ func SaveRefreshToken(userInfo users.UserTokenInfo) error {
 			fetched_user_token.UserId = userRefershToken.UserId
 			fetched_user_token.Token = userRefershToken.Token
 
-			_, err = db.Collection(users.USERS_TOKENS_COLLECTION).InsertOne(context.Background(), fetched_user_token)
+			_, err = db.Collection(users.USERS_TOKENS_COLLECTION).InsertOne(ctx, fetched_user_token)
 
 			if err != nil {
 				return err
@@ -347,7 +331,7 @@ func SaveRefreshToken(userInfo users.UserTokenInfo) error {
 		}}}
 
 		opts := options.Update().SetUpsert(true)
-		_, err = db.Collection(users.USERS_TOKENS_COLLECTION).UpdateOne(context.Background(), filter, update, opts)
+		_, err = db.Collection(users.USERS_TOKENS_COLLECTION).UpdateOne(ctx, filter, update, opts)
 
 		if err != nil {
 			return err
@@ -356,26 +340,11 @@ func SaveRefreshToken(userInfo users.UserTokenInfo) error {
 	return nil
 }
 
-func (u *UserToken) SetRefreshToken() error {
-
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	if err != nil {
-		return err
-	}
-
-	db := client.Database(mongodb_database)
+func (u *UserToken) SetRefreshToken(ctx context.Context, db *models.DbContext) error {
 
 	filter := bson.D{{Key: "user_id", Value: u.UserId}}
 
-	err = db.Collection(users.USERS_TOKENS_COLLECTION).FindOne(context.Background(), filter).Decode(&u)
+	err := db.Collection(users.USERS_TOKENS_COLLECTION).FindOne(ctx, filter).Decode(&u)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
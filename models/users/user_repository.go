@@ -4,13 +4,13 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
-	"errors"
 	"io"
-	"os"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/cache"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/roles"
@@ -18,7 +18,6 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/crypto/scrypt"
 )
 
@@ -31,7 +30,11 @@ const (
 	USERS_TOKENS_COLLECTION = "users_tokens"
 )
 
-func SignUp(user User) (error) {
+// ErrInvalidCredentials is returned by IsAuthenticated when the password does
+// not match.
+var ErrInvalidCredentials = apperrors.Unauthorized("Usuário não encontrado, verifique o e-mail ou senha")
+
+func SignUp(ctx context.Context, repository UserRepository, sender emails.Sender, baseUiHost string, user User) (error) {
 
 	err:= commons.ValidatePassword(user.Password)
 
@@ -42,34 +45,31 @@ func SignUp(user User) (error) {
 	user.Email = strings.ToLower(user.Email)	
 	user.ValidationToken = commons.GetValidationToken()
 
-	err = CreateUser(user)
+	err = repository.CreateUser(ctx, user)
 
 	if err != nil {
 		return err
 	}
 
-	go emails.SendEmail("", []string{user.Email}, "Confirmação de email", emails.GetWelcomeEmail(user.ValidationToken))
+	sender.Send(ctx, "", []string{user.Email}, "Confirmação de email", emails.GetWelcomeEmail(baseUiHost, user.ValidationToken))
 
 	return nil
 
 }
 
-func CreateUser(user User) (error) {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
+func CreateUser(ctx context.Context, db *models.DbContext, user User) (error) {
 
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
+	_, err := db.Collection("users").InsertOne(ctx, newUser(user))
 
 	if err != nil {
 		return err
 	}
 
-	db := client.Database(mongodb_database)
+	return nil	
+}
+
+// newUser sets the defaults of a user being created, including the salted password.
+func newUser(user User) User {
 	
 	user.Id = primitive.NewObjectID()
 	user.SetSaltedPassword()
@@ -83,13 +83,7 @@ func CreateUser(user User) (error) {
 	user.Experiences = []UserExperice{}
 	user.TechExperiences = []UserTechExperience{}
 
-	_, err = db.Collection("users").InsertOne(context.Background(), user)
-
-	if err != nil {
-		return err
-	}
-
-	return nil	
+	return user
 }
 
 func (user *User) SetSaltedPassword() error {
@@ -116,22 +110,7 @@ func (user *User) SetSaltedPassword() error {
 	return nil
 }
 
-func (user *User) IsAuthenticated() (bool, error) {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	if err != nil {
-		return false, err
-	}
-
-	db := client.Database(mongodb_database)
+func (user *User) IsAuthenticated(ctx context.Context, db *models.DbContext) (bool, error) {
 
 	// Check if a user with the given email exists
 	filter := bson.D{
@@ -139,15 +118,21 @@ func (user *User) IsAuthenticated() (bool, error) {
 		{Key: "is_deleted", Value: false},
 	}
 	var result User
-	err = db.Collection("users").FindOne(context.Background(), filter).Decode(&result)
+	err := db.Collection("users").FindOne(ctx, filter).Decode(&result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return false, nil
 		}
 	}
 
+	return isPasswordValid(result, user.Password)
+}
+
+// isPasswordValid checks the password against the salted hash stored for the user.
+func isPasswordValid(result User, password string) (bool, error) {
+
 	if result.Email == "" {
-		return false, errors.New("usuário não encontrado, verifique o e-mail ou senha")
+		return false, ErrInvalidCredentials
 	}
 
 	// Decode the salt from the string
@@ -157,7 +142,7 @@ func (user *User) IsAuthenticated() (bool, error) {
 	}
 
 	// Hash the password with the salt
-	hash, err := scrypt.Key([]byte(user.Password), salt, 1<<14, 8, 1, PW_HASH_BYTES)
+	hash, err := scrypt.Key([]byte(password), salt, 1<<14, 8, 1, PW_HASH_BYTES)
 	if err != nil {
 		return false, err
 	}
@@ -166,33 +151,18 @@ func (user *User) IsAuthenticated() (bool, error) {
 	hashStr := base64.StdEncoding.EncodeToString(hash)
 
 	if hashStr != result.Password {
-		return false, errors.New("usuário não encontrado, verifique o e-mail ou senha")
+		return false, ErrInvalidCredentials
 	}
 
 	return true, nil	
 }
 
-func GetUserByEmail(email string) (User, error) {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	if err != nil {
-		return User{}, err
-	}
-
-	db := client.Database(mongodb_database)
+func GetUserByEmail(ctx context.Context, db *models.DbContext, email string) (User, error) {
 
 	// Check if a user with the given email exists
 	filter := bson.D{{Key: "email", Value: strings.ToLower(email)}}
 	var result User
-	err = db.Collection("users").FindOne(context.Background(), filter).Decode(&result)
+	err := db.Collection("users").FindOne(ctx, filter).Decode(&result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return User{}, nil
@@ -202,27 +172,12 @@ func GetUserByEmail(email string) (User, error) {
 	return result, nil	
 }
 
-func (user *User) UpdateLastLogin() error {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return err
-	}
-
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
+func (user *User) UpdateLastLogin(ctx context.Context, db *models.DbContext) error {
 
-	filter := bson.D{{"email", strings.ToLower(user.Email)}}
-	update := bson.D{{"$set", bson.D{{"last_login", primitive.NewDateTimeFromTime(time.Now().UTC())}}}}
+	filter := bson.D{{Key: "email", Value: strings.ToLower(user.Email)}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "last_login", Value: primitive.NewDateTimeFromTime(time.Now().UTC())}}}}
 
-	_, err = db.Collection("users").UpdateOne(context.Background(), filter, update)
+	_, err := db.Collection("users").UpdateOne(ctx, filter, update)
 
 	if err != nil {
 		return err
@@ -231,33 +186,9 @@ func (user *User) UpdateLastLogin() error {
 	return nil	
 }
 
-func Update(user *User) error {
-
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
+func Update(ctx context.Context, db *models.DbContext, user *User) error {
 
-	if err != nil {
-		return err
-	}
-
-	db := client.Database(mongodb_database)
-
-	if user.JobPreference.JobLocations == nil || len(user.JobPreference.JobLocations) == 0 {
-		user.JobPreference.JobLocations = []UserJobLocation{
-			{
-				City:     user.City,
-				State:    user.State,
-				Priority: 1,
-			},
-		}
-	}
+	user.setDefaultJobLocations()
 
 	filter := bson.D{{Key: "_id", Value: user.Id}}
 	update := bson.D{
@@ -282,7 +213,7 @@ func Update(user *User) error {
 		}},
 	}
 
-	_, err = db.Collection("users").UpdateOne(context.Background(), filter, update)
+	_, err := db.Collection("users").UpdateOne(ctx, filter, update)
 
 	if err != nil {
 		return err
@@ -291,27 +222,27 @@ func Update(user *User) error {
 	return nil
 }
 
-func (user *User) IncrementProfileViews() error {
-	
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
+// setDefaultJobLocations uses the user's city as job location when none was chosen.
+func (user *User) setDefaultJobLocations() {
 
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
+	if user.JobPreference.JobLocations == nil || len(user.JobPreference.JobLocations) == 0 {
+		user.JobPreference.JobLocations = []UserJobLocation{
+			{
+				City:     user.City,
+				State:    user.State,
+				Priority: 1,
+			},
 		}
-	}()
+	}
+}
 
-	db := client.Database(mongodb_database)
+func (user *User) IncrementProfileViews(ctx context.Context, db *models.DbContext) error {
+	
 
 	filter := bson.D{{Key: "_id", Value: user.Id}}
 	update := bson.D{{Key: "$inc", Value: bson.D{{Key: "public_profile_views", Value: 1}}}}
 
-	_, err = db.Collection("users").UpdateOne(context.Background(), filter, update)
+	_, err := db.Collection("users").UpdateOne(ctx, filter, update)
 
 	if err != nil {
 		return err
@@ -320,28 +251,16 @@ func (user *User) IncrementProfileViews() error {
 	return nil
 }
 
-func (user *User) ConfirmEmail() error {
+func (user *User) ConfirmEmail(ctx context.Context, db *models.DbContext) error {
 
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	if err != nil {
-		return err
-	}
-
-	db := client.Database(mongodb_database)
 
 	filter := bson.D{{Key: "_id", Value: user.Id}}
-	update := bson.D{{Key: "$set", Value: bson.D{{Key: "is_email_confirmed", Value: true}}}}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "is_email_confirmed", Value: true},
+		{Key: "last_update", Value: primitive.NewDateTimeFromTime(time.Now().UTC())},
+	}}}
 
-	_, err = db.Collection("users").UpdateOne(context.Background(), filter, update)
+	_, err := db.Collection("users").UpdateOne(ctx, filter, update)
 
 	if err != nil {
 		return err
@@ -350,22 +269,7 @@ func (user *User) ConfirmEmail() error {
 	return nil
 }
 
-func GetUserByUserName(userName string) (User, error) {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return User{}, err
-	}
-
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
+func GetUserByUserName(ctx context.Context, db *models.DbContext, userName string) (User, error) {
 
 	filter := bson.D{
 		{Key: "user_name", Value: strings.ToLower(userName)},
@@ -374,7 +278,7 @@ func GetUserByUserName(userName string) (User, error) {
 		{Key: "is_public", Value: true},
 	}
 	var result User
-	err = db.Collection("users").FindOne(context.Background(), filter).Decode(&result)
+	err := db.Collection("users").FindOne(ctx, filter).Decode(&result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return User{}, nil
@@ -384,26 +288,7 @@ func GetUserByUserName(userName string) (User, error) {
 	return result, nil	
 }
 
-func (user *User) UpdateUserBookmarkedJobs() error {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return err
-	}
-
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
-	
-	if err != nil {
-		return err
-	}
+func (user *User) UpdateUserBookmarkedJobs(ctx context.Context, db *models.DbContext) error {
 
 	filter := bson.D{{Key: "_id", Value: user.Id}}
 
@@ -412,7 +297,7 @@ func (user *User) UpdateUserBookmarkedJobs() error {
 		{Key: "$set", Value: bson.D{{Key: "last_update", Value: primitive.NewDateTimeFromTime(time.Now().UTC())}}},
 	}
 	
-	_, err = db.Collection("users").UpdateOne(context.Background(), filter, update)
+	_, err := db.Collection("users").UpdateOne(ctx, filter, update)
 
 	if err != nil {
 		return err
@@ -421,34 +306,19 @@ func (user *User) UpdateUserBookmarkedJobs() error {
 	return nil
 }
 
-func DeleteUser(id primitive.ObjectID) error {
+func DeleteUser(ctx context.Context, db *models.DbContext, id primitive.ObjectID) error {
 	
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return err
-	}
-
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
 
 	filter := bson.D{{Key: "_id", Value: id}}	
 
-	_, err = db.Collection("users").DeleteOne(context.Background(), filter)
+	_, err := db.Collection("users").DeleteOne(ctx, filter)
 
 	if err != nil {
 		return err
 	}
 
 	filter = bson.D{{Key: "user_id", Value: id}}
-	_, err = db.Collection("users_tokens").DeleteMany(context.Background(), filter)
+	_, err = db.Collection("users_tokens").DeleteMany(ctx, filter)
 
 	if err != nil {
 		return err
@@ -457,30 +327,11 @@ func DeleteUser(id primitive.ObjectID) error {
 	return nil
 }
 
-func GetUserById(id primitive.ObjectID) (User, error) {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return User{}, err
-	}
-
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
-	
-	if err != nil {
-		return User{}, err
-	}
+func GetUserById(ctx context.Context, db *models.DbContext, id primitive.ObjectID) (User, error) {
 
 	filter := bson.D{{Key: "_id", Value: id}}
 	var result User
-	err = db.Collection("users").FindOne(context.Background(), filter).Decode(&result)
+	err := db.Collection("users").FindOne(ctx, filter).Decode(&result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return User{}, nil
@@ -490,26 +341,12 @@ func GetUserById(id primitive.ObjectID) (User, error) {
 	return result, nil	
 }
 
-func GetUserRoles (id primitive.ObjectID) ([]string, error) {
+func GetUserRoles (ctx context.Context, db *models.DbContext, rolesCache cache.Store, id primitive.ObjectID) ([]string, error) {
 	
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return []string{}, err
-	}
-	
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
 
 	filter := bson.D{{Key: "_id", Value: id}}
 	var result User
-	err = db.Collection("users").FindOne(context.Background(), filter).Decode(&result)
+	err := db.Collection("users").FindOne(ctx, filter).Decode(&result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return []string{}, nil
@@ -520,15 +357,21 @@ func GetUserRoles (id primitive.ObjectID) ([]string, error) {
 		return []string{}, nil
 	}
 	
-	roles, err := roles.GetRoles()
+	roles, err := roles.GetRoles(ctx, db, rolesCache)
 
 	if err != nil {
 		return []string{}, err
 	}
 	
+	return getRoleNames(result.Roles, roles), nil	
+}
+
+// getRoleNames resolves the role ids of a user to their names.
+func getRoleNames(userRoles []primitive.ObjectID, roles []roles.Role) []string {
+
 	resultRoles := []string{}
 
-	for _, role := range result.Roles {
+	for _, role := range userRoles {
 		for _, r := range roles {
 			roleID, err := primitive.ObjectIDFromHex(r.Id)
 			if err != nil {
@@ -539,29 +382,15 @@ func GetUserRoles (id primitive.ObjectID) ([]string, error) {
 			}
 		}
 	}
-	
-	return resultRoles, nil	
-}
-
-func GetUserByValidationToken(token string) (User, error) {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
 
-	if err != nil {
-		return User{}, err
-	}
-
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
+	return resultRoles
+}
 
-	db := client.Database(mongodb_database)
+func GetUserByValidationToken(ctx context.Context, db *models.DbContext, token string) (User, error) {
 
 	filter := bson.D{{Key: "validation_token", Value: token}}
 	var result User
-	err = db.Collection("users").FindOne(context.Background(), filter).Decode(&result)
+	err := db.Collection("users").FindOne(ctx, filter).Decode(&result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return User{}, nil
@@ -571,85 +400,58 @@ func GetUserByValidationToken(token string) (User, error) {
 	return result, nil	
 }
 
-func GetUsers(filter commons.FilterRequest) (UsersPaginatedResult, error) {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
+func GetUsers(ctx context.Context, db *models.DbContext, filter commons.FilterRequest) (UsersPaginatedResult, error) {
+
+	query, err := commons.NewPageQuery(filter.Sort, filter.IsAscending, filter.Page, filter.PageSize, filter.Cursor)
 
 	if err != nil {
 		return UsersPaginatedResult{}, err
 	}
 
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
+	cursor, err := db.Collection("users").Find(ctx, query.Filter(filter.GetFilter()), query.FindOptions())
 
-	db := client.Database(mongodb_database)
-
-	page:= filter.Page
-	perPage:= filter.PageSize
-
-	if (page - 1) < 0 {
-		page = 1
+	if err != nil {
+		return UsersPaginatedResult{}, err
 	}
 
-	skip := (page - 1) * perPage
-
-	if filter.Sort == "" {
-		filter.Sort = "created_at"
-	}
+	defer cursor.Close(ctx)
 
-	orderDirection := -1
+	var documents []bson.M
 
-	if filter.IsAscending {
-		orderDirection = 1
+	if err = cursor.All(ctx, &documents); err != nil {
+		return UsersPaginatedResult{}, err
 	}
 
-	options := options.Find().SetSort(bson.M{filter.Sort: orderDirection}).SetSkip(int64(skip)).SetLimit(int64(perPage))
-
-	cursor, err := db.Collection("users").Find(context.Background(), filter.GetFilter(), options)
+	users, cursors, err := commons.DecodePage[UserView](query, documents)
 
 	if err != nil {
 		return UsersPaginatedResult{}, err
 	}
 
-	var users []UserView
+	total := int64(commons.NO_TOTAL)
 
-	if err = cursor.All(context.Background(), &users); err != nil {
-		return UsersPaginatedResult{}, err
+	if !filter.SkipTotal {
+		if total, err = db.Collection("users").CountDocuments(ctx, filter.GetFilter()); err != nil {
+			return UsersPaginatedResult{}, err
+		}
 	}
 
-	total, err := db.Collection("users").CountDocuments(context.Background(), filter.GetFilter())
-
 	return UsersPaginatedResult{
 		Total:   total,
-		Page:    page,
-		PerPage: perPage,
+		Page:    query.Page,
+		PerPage: query.PageSize,
 		Data:    users,
-	}, nil		
+		NextCursor: cursors.Next,
+		PrevCursor: cursors.Prev,
+	}, nil
 }
 
-func UpdateValidationToken(user User) error {
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
+func UpdateValidationToken(ctx context.Context, db *models.DbContext, user User) error {
 
 	filter := bson.D{{Key: "_id", Value: user.Id}}
 	update := bson.D{{Key: "$set", Value: bson.D{{Key: "validation_token", Value: user.ValidationToken}}}}
 
-	_, err = db.Collection("users").UpdateOne(context.Background(), filter, update)
+	_, err := db.Collection("users").UpdateOne(ctx, filter, update)
 
 	if err != nil {
 		return err
@@ -658,22 +460,8 @@ func UpdateValidationToken(user User) error {
 	return nil	
 }
 
-func (user *User) UpdatePassword(password string) error {
+func (user *User) UpdatePassword(ctx context.Context, db *models.DbContext, password string) error {
 	
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
 
 	user.Password = password
 	user.SetSaltedPassword()
@@ -682,7 +470,7 @@ func (user *User) UpdatePassword(password string) error {
 
 	update := bson.D{{Key: "$set", Value: bson.D{{Key: "password", Value: user.Password}, {Key: "password_salt", Value: user.Salt}}}}
 
-	_, err = db.Collection("users").UpdateOne(context.Background(), filter, update)
+	_, err := db.Collection("users").UpdateOne(ctx, filter, update)
 
 	if err != nil {
 		return err
@@ -691,11 +479,11 @@ func (user *User) UpdatePassword(password string) error {
 	return nil
 }
 
-func (user *User) ResetValidationToken() error {
+func (user *User) ResetValidationToken(ctx context.Context, repository UserRepository) error {
 	
 	user.ValidationToken = commons.GetValidationToken()
 
-	err := UpdateValidationToken(*user)
+	err := repository.UpdateValidationToken(ctx, *user)
 
 	if err != nil {
 		return err
@@ -719,22 +507,8 @@ func (user *User) IsAuthorized(roles string) bool {
 	return false	
 }
 
-func (user *User) Update() error {
+func (user *User) Update(ctx context.Context, db *models.DbContext) error {
 	
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
 
 	filter := bson.D{{Key: "_id", Value: user.Id}}
 
@@ -760,7 +534,7 @@ func (user *User) Update() error {
 			},
 		},		
 	}
-	_, err = db.Collection("users").UpdateOne(context.Background(), filter, update)
+	_, err := db.Collection("users").UpdateOne(ctx, filter, update)
 
 	if err != nil {
 		return err
@@ -786,26 +560,12 @@ func IsUserNameValid(userName string) bool {
 	return match
 }
 
-func UserNameAlreadyExists(userName string) (bool, error) {
+func UserNameAlreadyExists(ctx context.Context, db *models.DbContext, userName string) (bool, error) {
 	
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return false, err
-	}
-
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
 
 	filter := bson.D{{Key: "user_name", Value: strings.ToLower(userName)}}
 	var result User
-	err = db.Collection("users").FindOne(context.Background(), filter).Decode(&result)
+	err := db.Collection("users").FindOne(ctx, filter).Decode(&result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return false, nil
@@ -815,22 +575,8 @@ func UserNameAlreadyExists(userName string) (bool, error) {
 	return true, nil
 }
 
-func (user *User) UpdateProfilePicture() error {
+func (user *User) UpdateProfilePicture(ctx context.Context, db *models.DbContext) error {
 	
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
 
 	filter := bson.D{{Key: "_id", Value: user.Id}}
 
@@ -843,7 +589,7 @@ func (user *User) UpdateProfilePicture() error {
 			},
 		},		
 	}
-	_, err = db.Collection("users").UpdateOne(context.Background(), filter, update)
+	_, err := db.Collection("users").UpdateOne(ctx, filter, update)
 
 	if err != nil {
 		return err
@@ -853,22 +599,8 @@ func (user *User) UpdateProfilePicture() error {
 
 }
 
-func( user *User) UpdateUserName() error {
+func( user *User) UpdateUserName(ctx context.Context, db *models.DbContext) error {
 	
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	db := client.Database(mongodb_database)
 
 	filter := bson.D{{Key: "_id", Value: user.Id}}
 
@@ -881,7 +613,7 @@ func( user *User) UpdateUserName() error {
 			},
 		},		
 	}
-	_, err = db.Collection("users").UpdateOne(context.Background(), filter, update)
+	_, err := db.Collection("users").UpdateOne(ctx, filter, update)
 
 	if err != nil {
 		return err
@@ -890,42 +622,57 @@ func( user *User) UpdateUserName() error {
 	return nil
 }
 
-func GetTalents(filter commons.FilterRequest, is_admin bool, is_recruiter bool) (UserTalentsPaginatedResult, error) {
-	
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
+func GetTalents(ctx context.Context, db *models.DbContext, filter commons.FilterRequest, is_admin bool, is_recruiter bool) (UserTalentsPaginatedResult, error) {
+
+	final_filter := getTalentsFilter(filter, is_admin, is_recruiter)
+
+	query, err := commons.NewPageQuery(filter.Sort, filter.IsAscending, filter.Page, filter.PageSize, filter.Cursor)
 
 	if err != nil {
 		return UserTalentsPaginatedResult{}, err
 	}
 
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
+	cursor, err := db.Collection("users").Find(ctx, query.Filter(final_filter), query.FindOptions())
 
-	db := client.Database(mongodb_database)
+	if err != nil {
+		return UserTalentsPaginatedResult{}, err
+	}
 
-	page:= filter.Page
-	perPage:= filter.PageSize
+	defer cursor.Close(ctx)
 
-	if (page - 1) < 0 {
-		page = 1
+	var documents []bson.M
+
+	if err = cursor.All(ctx, &documents); err != nil {
+		return UserTalentsPaginatedResult{}, err
 	}
 
-	skip := (page - 1) * perPage
+	users, cursors, err := commons.DecodePage[UserTalentView](query, documents)
 
-	if filter.Sort == "" {
-		filter.Sort = "created_at"
+	if err != nil {
+		return UserTalentsPaginatedResult{}, err
 	}
 
-	orderDirection := -1
+	total := int64(commons.NO_TOTAL)
 
-	if filter.IsAscending {
-		orderDirection = 1
+	if !filter.SkipTotal {
+		if total, err = db.Collection("users").CountDocuments(ctx, final_filter); err != nil {
+			return UserTalentsPaginatedResult{}, err
+		}
 	}
 
+	return UserTalentsPaginatedResult{
+		Total:   total,
+		Page:    query.Page,
+		PerPage: query.PageSize,
+		Data:    users,
+		NextCursor: cursors.Next,
+		PrevCursor: cursors.Prev,
+	}, nil
+}
+
+// getTalentsFilter restricts the requested filter to the profiles the caller is allowed to see.
+func getTalentsFilter(filter commons.FilterRequest, is_admin bool, is_recruiter bool) bson.M {
+
 	final_filter := filter.GetFilter()
 	
 	is_public_filter := bson.M {
@@ -953,26 +700,5 @@ func GetTalents(filter commons.FilterRequest, is_admin bool, is_recruiter bool)
 		}
 	}
 
-	options := options.Find().SetSort(bson.M{filter.Sort: orderDirection}).SetSkip(int64(skip)).SetLimit(int64(perPage))
-
-	cursor, err := db.Collection("users").Find(context.Background(), final_filter, options)
-
-	if err != nil {
-		return UserTalentsPaginatedResult{}, err
-	}
-
-	var users []UserTalentView
-
-	if err = cursor.All(context.Background(), &users); err != nil {
-		return UserTalentsPaginatedResult{}, err
-	}
-
-	total, err := db.Collection("users").CountDocuments(context.Background(), final_filter)
-
-	return UserTalentsPaginatedResult{
-		Total:   total,
-		Page:    page,
-		PerPage: perPage,
-		Data:    users,
-	}, nil	
-}
\ No newline at end of file
+	return final_filter
+}
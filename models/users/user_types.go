@@ -1,12 +1,13 @@
 package users
 
 import (
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type AuthRequestBody struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" binding:"required,email,max=254"`
+	Password string `json:"password" binding:"required,max=128"`
 }
 
 type AuthResponse struct {
@@ -111,35 +112,53 @@ type User struct {
 	ProfileImageUrl      string               `bson:"profile_image_url" json:"profile_image_url"`
 	OAuthImageURL        string               `bson:"oauth_image_url" json:"oauth_image_url"`
 	GravatarImageUrl     string               `bson:"gravatar_image_url" json:"gravatar_image_url"`
+	FailedLoginAttempts  int                  `bson:"failed_login_attempts" json:"failed_login_attempts"`
+	LastFailedLoginAt    primitive.DateTime   `bson:"last_failed_login_at" json:"last_failed_login_at"`
+	LockedUntil          primitive.DateTime   `bson:"locked_until" json:"locked_until"`
+	Lockouts             int                  `bson:"lockouts" json:"lockouts"`
+	UnlockToken          string               `bson:"unlock_token" json:"-"`
+}
+
+// UserLockout is a user locked out after too many failed logins, as listed
+// to the admins.
+type UserLockout struct {
+	Id                  primitive.ObjectID `bson:"_id" json:"id"`
+	FirstName           string             `bson:"first_name" json:"first_name"`
+	LastName            string             `bson:"last_name" json:"last_name"`
+	Email               string             `bson:"email" json:"email"`
+	FailedLoginAttempts int                `bson:"failed_login_attempts" json:"failed_login_attempts"`
+	LastFailedLoginAt   primitive.DateTime `bson:"last_failed_login_at" json:"last_failed_login_at"`
+	LockedUntil         primitive.DateTime `bson:"locked_until" json:"locked_until"`
+	Lockouts            int                `bson:"lockouts" json:"lockouts"`
 }
 
 type UserTechExperience struct {
 	Id               int    `json:"id"`
-	Technology       string `json:"technology"`
+	Technology       string `json:"technology" binding:"max=100"`
 	ExperienceNumber int    `json:"experience_number"`
-	ExperienceTime   string `json:"experience_time"`
+	ExperienceTime   string `json:"experience_time" binding:"max=50"`
 }
 
 type UserEducation struct {
 	Id           float64 `json:"id"`
-	Institution  string  `json:"institution"`
-	Course       string  `json:"course"`
-	FieldOfStudy string  `json:"field_of_study"`
-	Grade        string  `json:"grade"`
-	Description  string  `json:"description"`
-	Degree       string  `json:"degree"`
-	StartDate    string  `json:"start_date"`
-	EndDate      string  `json:"end_date"`
+	Institution  string  `json:"institution" binding:"max=200"`
+	Course       string  `json:"course" binding:"max=200"`
+	FieldOfStudy string  `json:"field_of_study" binding:"max=200"`
+	Grade        string  `json:"grade" binding:"max=200"`
+	Description  string  `json:"description" binding:"max=5000"`
+	Degree       string  `json:"degree" binding:"max=200"`
+	StartDate    string  `json:"start_date" binding:"max=30"`
+	EndDate      string  `json:"end_date" binding:"max=30"`
 }
 
 type UserCertification struct {
 	Id             float64 `json:"id"`
-	IssuingCompany string  `json:"issuing_company"`
-	Name           string  `json:"name"`
-	IssueDate      string  `json:"start_date"`
-	ExpirationDate string  `json:"end_date"`
-	CredentialId   string  `json:"credential_id"`
-	CredentialUrl  string  `json:"credential_url"`
+	IssuingCompany string  `json:"issuing_company" binding:"max=200"`
+	Name           string  `json:"name" binding:"max=200"`
+	IssueDate      string  `json:"start_date" binding:"max=30"`
+	ExpirationDate string  `json:"end_date" binding:"max=30"`
+	CredentialId   string  `json:"credential_id" binding:"max=200"`
+	CredentialUrl  string  `json:"credential_url" binding:"max=2048"`
 }
 
 type UserProfileFilter struct {
@@ -161,8 +180,8 @@ type UserDiversityInfo struct {
 
 type UserIdiomInfo struct {
 	Id   float64 `json:"id"`
-	Name  string `json:"name"`
-	Level string `json:"level"`
+	Name  string `json:"name" binding:"max=100"`
+	Level string `json:"level" binding:"max=50"`
 }
 
 type UserHealthInfo struct {
@@ -183,17 +202,17 @@ type UserRaceInfo struct {
 
 type UserLink struct {
 	Id   float64 `json:"id"`
-	Url  string  `json:"url"`
-	Name string  `json:"name"`
+	Url  string  `json:"url" binding:"max=2048"`
+	Name string  `json:"name" binding:"max=100"`
 }
 
 type UserExperice struct {
 	Id          float64 `json:"id"`
-	Company     string  `json:"company"`
-	Position    string  `json:"position"`
-	StartDate   string  `json:"start_date"`
-	EndDate     string  `json:"end_date"`
-	Description string  `json:"description"`
+	Company     string  `json:"company" binding:"max=200"`
+	Position    string  `json:"position" binding:"max=200"`
+	StartDate   string  `json:"start_date" binding:"max=30"`
+	EndDate     string  `json:"end_date" binding:"max=30"`
+	Description string  `json:"description" binding:"max=5000"`
 }
 
 type UserJobPreference struct {
@@ -215,6 +234,14 @@ type UsersPaginatedResult struct {
 	Page    int
 	PerPage int
 	Data    []UserView
+	NextCursor string `json:",omitempty"` // Cursor of the next page, if any
+	PrevCursor string `json:",omitempty"` // Cursor of the previous page, if any
+}
+
+// ToPage returns the result in the envelope of the v2 API.
+func (result UsersPaginatedResult) ToPage() commons.Page[UserView] {
+	return commons.NewPage(result.Data, result.Total, result.Page, result.PerPage).
+		WithCursors(commons.Cursors{Next: result.NextCursor, Prev: result.PrevCursor})
 }
 
 type UserTalentsPaginatedResult struct {
@@ -222,4 +249,11 @@ type UserTalentsPaginatedResult struct {
 	Page    int
 	PerPage int
 	Data    []UserTalentView
-}
\ No newline at end of file
+	NextCursor string `json:",omitempty"` // Cursor of the next page, if any
+	PrevCursor string `json:",omitempty"` // Cursor of the previous page, if any
+}
+
+func (result UserTalentsPaginatedResult) ToPage() commons.Page[UserTalentView] {
+	return commons.NewPage(result.Data, result.Total, result.Page, result.PerPage).
+		WithCursors(commons.Cursors{Next: result.NextCursor, Prev: result.PrevCursor})
+}
@@ -0,0 +1,239 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	VERSION           = "3.0.3"
+	JSON_CONTENT_TYPE = "application/json"
+)
+
+// Document is an OpenAPI 3 document, built from the routes of the API with
+// the schemas taken from their DTOs.
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+
+	// Rules describe the custom binding rules registered in the validator.
+	Rules map[string]Rule `json:"-"`
+	// Error is the body answered by every operation on failure.
+	Error interface{} `json:"-"`
+
+	types map[reflect.Type]string
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationId string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Route describes an operation of the API. Request and Response are values
+// of the DTOs, or a *Schema for the bodies without a type; a nil Response is
+// an empty body. Path is in the gin syntax, such as /jobs/:code.
+type Route struct {
+	Method       string
+	Path         string
+	Summary      string
+	Description  string
+	Tag          string
+	Security     []string
+	Request      interface{}
+	RequestType  string
+	Response     interface{}
+	ResponseType string
+	Status       int
+	Deprecated   bool
+}
+
+func NewDocument(title string, version string) *Document {
+	return &Document{
+		OpenAPI:    VERSION,
+		Info:       Info{Title: title, Version: version},
+		Paths:      map[string]map[string]*Operation{},
+		Components: Components{Schemas: map[string]*Schema{}, SecuritySchemes: map[string]*SecurityScheme{}},
+		Rules:      map[string]Rule{},
+		types:      map[reflect.Type]string{},
+	}
+}
+
+// Add adds the operations of the routes to the document.
+func (document *Document) Add(routes ...Route) {
+	for _, route := range routes {
+		path, parameters := convertPath(route.Path)
+
+		operation := &Operation{
+			Summary:     route.Summary,
+			Description: route.Description,
+			OperationId: operationId(route.Method, route.Path),
+			Parameters:  parameters,
+			Responses:   map[string]*Response{},
+			Deprecated:  route.Deprecated,
+		}
+
+		if route.Tag != "" {
+			operation.Tags = []string{route.Tag}
+		}
+
+		for _, scheme := range route.Security {
+			operation.Security = append(operation.Security, map[string][]string{scheme: {}})
+		}
+
+		if route.Request != nil {
+			operation.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]*MediaType{contentType(route.RequestType): {Schema: document.schemaOf(route.Request)}},
+			}
+		}
+
+		status := route.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		response := &Response{Description: http.StatusText(status)}
+		if route.Response != nil {
+			response.Content = map[string]*MediaType{contentType(route.ResponseType): {Schema: document.schemaOf(route.Response)}}
+		}
+		operation.Responses[strconv.Itoa(status)] = response
+
+		if document.Error != nil {
+			operation.Responses["default"] = &Response{
+				Description: "Erro",
+				Content:     map[string]*MediaType{JSON_CONTENT_TYPE: {Schema: document.schemaOf(document.Error)}},
+			}
+		}
+
+		if document.Paths[path] == nil {
+			document.Paths[path] = map[string]*Operation{}
+		}
+		document.Paths[path][strings.ToLower(route.Method)] = operation
+	}
+}
+
+// WithPrefix returns the routes under the path prefix, such as /v1.
+func WithPrefix(prefix string, routes []Route) []Route {
+	result := make([]Route, 0, len(routes))
+	for _, route := range routes {
+		route.Path = prefix + route.Path
+		result = append(result, route)
+	}
+	return result
+}
+
+// Has tells whether the document describes the route, with the path in the
+// gin syntax.
+func (document *Document) Has(method string, path string) bool {
+	converted, _ := convertPath(path)
+	_, found := document.Paths[converted][strings.ToLower(method)]
+	return found
+}
+
+// Operations lists the routes described by the document as "METHOD /path".
+func (document *Document) Operations() []string {
+	operations := []string{}
+	for path, item := range document.Paths {
+		for method := range item {
+			operations = append(operations, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(operations)
+	return operations
+}
+
+// Handler answers the document as JSON.
+func (document *Document) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, document)
+	}
+}
+
+// convertPath turns the parameters of a gin path, :code and *path, into the
+// {code} of OpenAPI.
+func convertPath(path string) (string, []Parameter) {
+	segments := strings.Split(path, "/")
+	parameters := []Parameter{}
+
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+			continue
+		}
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+		parameters = append(parameters, Parameter{Name: name, In: "path", Required: true, Schema: String()})
+	}
+
+	return strings.Join(segments, "/"), parameters
+}
+
+// operationId names the operation by its method and path, such as
+// get_jobs_code for GET /jobs/:code.
+func operationId(method string, path string) string {
+	name := strings.ToLower(method)
+	for _, segment := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '-' || r == '.' }) {
+		name += "_" + strings.TrimLeft(segment, ":*")
+	}
+	return name
+}
+
+func contentType(value string) string {
+	if value == "" {
+		return JSON_CONTENT_TYPE
+	}
+	return value
+}
@@ -0,0 +1,248 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Schema is an OpenAPI schema object, limited to what the DTOs of the API use.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Rule describes a custom binding rule, such as contract_type, on the schema
+// of the field.
+type Rule func(schema *Schema)
+
+func String() *Schema {
+	return &Schema{Type: "string"}
+}
+
+func Boolean() *Schema {
+	return &Schema{Type: "boolean"}
+}
+
+func Integer() *Schema {
+	return &Schema{Type: "integer"}
+}
+
+func ArrayOf(items *Schema) *Schema {
+	return &Schema{Type: "array", Items: items}
+}
+
+// Object is an inline object, for the responses built with gin.H.
+func Object(properties map[string]*Schema) *Schema {
+	return &Schema{Type: "object", Properties: properties}
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	dateTimeType = reflect.TypeOf(primitive.DateTime(0))
+	objectIdType = reflect.TypeOf(primitive.ObjectID{})
+	schemaType   = reflect.TypeOf(&Schema{})
+)
+
+// schemaOf returns the schema of the value: a *Schema as it is, the named
+// structs as references to the components and the other types inline.
+func (document *Document) schemaOf(value interface{}) *Schema {
+	if value == nil {
+		return nil
+	}
+	if schema, ok := value.(*Schema); ok {
+		return schema
+	}
+	return document.schemaOfType(reflect.TypeOf(value))
+}
+
+func (document *Document) schemaOfType(t reflect.Type) *Schema {
+	switch t {
+	case timeType, dateTimeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case objectIdType:
+		return &Schema{Type: "string", Pattern: "^[0-9a-f]{24}$"}
+	case schemaType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return document.schemaOfType(t.Elem())
+	case reflect.String:
+		return String()
+	case reflect.Bool:
+		return Boolean()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Integer()
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return ArrayOf(document.schemaOfType(t.Elem()))
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: document.schemaOfType(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return document.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + document.component(t)}
+	}
+
+	// interface{} and the other kinds accept any value.
+	return &Schema{}
+}
+
+// component registers the named struct in the components once, under its
+// name, or with the package as prefix when the name is taken by another type.
+func (document *Document) component(t reflect.Type) string {
+	if name, ok := document.types[t]; ok {
+		return name
+	}
+
+	name := typeName(t.Name())
+	if _, taken := document.Components.Schemas[name]; taken {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+
+	// Registered before the fields, so recursive types end in a reference.
+	document.types[t] = name
+	document.Components.Schemas[name] = &Schema{}
+	*document.Components.Schemas[name] = *document.structSchema(t)
+
+	return name
+}
+
+// typeName names the instances of generic types by their arguments without the
+// packages, so Page[github.com/.../jobs.Job] is PageJob.
+func typeName(name string) string {
+	base, arguments, generic := strings.Cut(name, "[")
+	if !generic {
+		return name
+	}
+	for _, argument := range strings.Split(strings.TrimSuffix(arguments, "]"), ",") {
+		base += argument[strings.LastIndex(argument, ".")+1:]
+	}
+	return base
+}
+
+func (document *Document) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := document.structSchema(field.Type)
+			for key, property := range embedded.Properties {
+				schema.Properties[key] = property
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property := document.schemaOfType(field.Type)
+
+		if document.applyBinding(property, field.Tag.Get("binding")) {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = property
+	}
+
+	return schema
+}
+
+// applyBinding describes the binding rules of the field on its schema and
+// tells whether the field is required. The rules after dive apply to the
+// items of an array.
+func (document *Document) applyBinding(schema *Schema, binding string) bool {
+	if binding == "" || schema.Ref != "" {
+		return strings.Contains(binding, "required")
+	}
+
+	required := false
+	target := schema
+
+	for _, rule := range strings.Split(binding, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "dive":
+			if target.Items == nil || target.Items.Ref != "" {
+				return required
+			}
+			target = target.Items
+		case "required":
+			required = true
+		case "email":
+			target.Format = "email"
+		case "url", "http_url":
+			target.Format = "uri"
+		case "oneof":
+			target.Enum = strings.Fields(param)
+		case "min", "max":
+			limit(target, name, param)
+		default:
+			if describe, ok := document.Rules[name]; ok {
+				describe(target)
+			}
+		}
+	}
+
+	return required
+}
+
+func limit(schema *Schema, name string, param string) {
+	value, err := strconv.Atoi(param)
+	if err != nil {
+		return
+	}
+
+	number := float64(value)
+
+	switch {
+	case schema.Type == "string" && name == "min":
+		schema.MinLength = &value
+	case schema.Type == "string":
+		schema.MaxLength = &value
+	case schema.Type == "array" && name == "max":
+		schema.MaxItems = &value
+	case schema.Type == "integer" || schema.Type == "number":
+		if name == "min" {
+			schema.Minimum = &number
+		} else {
+			schema.Maximum = &number
+		}
+	}
+}
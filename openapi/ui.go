@@ -0,0 +1,39 @@
+package openapi
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const SWAGGER_UI_URL = "https://unpkg.com/swagger-ui-dist@5"
+
+var uiTemplate = template.Must(template.New("ui").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+	<meta charset="utf-8">
+	<title>{{.Title}}</title>
+	<link rel="stylesheet" href="{{.Assets}}/swagger-ui.css">
+</head>
+<body>
+	<div id="swagger-ui"></div>
+	<script src="{{.Assets}}/swagger-ui-bundle.js" crossorigin></script>
+	<script>
+		window.onload = function () {
+			window.ui = SwaggerUIBundle({ url: {{.SpecUrl}}, dom_id: "#swagger-ui" });
+		};
+	</script>
+</body>
+</html>
+`))
+
+// UiHandler answers the Swagger UI page, which renders the document served at
+// specUrl. The assets of the page come from a CDN.
+func UiHandler(title string, specUrl string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		_ = uiTemplate.Execute(c.Writer, gin.H{"Title": title, "Assets": SWAGGER_UI_URL, "SpecUrl": specUrl})
+	}
+}
@@ -0,0 +1,178 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/controllers/jobs"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/controllers/users"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares/authentication"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
+	jobsModel "github.com/flaviofrancisco/vagasprajr-api-v2/models/jobs"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/shopping"
+	usersModel "github.com/flaviofrancisco/vagasprajr-api-v2/models/users"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/openapi"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/health"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/oauth/google"
+)
+
+const (
+	OPENAPI_PATH = "/openapi.json"
+	DOCS_PATH    = "/docs"
+	API_TITLE    = "vagasprajr API"
+	API_VERSION  = "2.0.0"
+)
+
+const (
+	BEARER_SECURITY = "bearer"
+	COOKIE_SECURITY = "cookie"
+)
+
+// authenticated is the security of the routes behind the authentication
+// middleware, which reads the token from the cookie or the Authorization header.
+var authenticated = []string{BEARER_SECURITY, COOKIE_SECURITY}
+
+// The inline bodies of the handlers answering gin.H.
+var (
+	successBody = openapi.Object(map[string]*openapi.Schema{"success": openapi.Boolean()})
+	messageBody = openapi.Object(map[string]*openapi.Schema{"message": openapi.String()})
+)
+
+// NewApiDocument describes every route registered by RegisterRoutes. A route
+// missing here fails the tests of the package.
+func NewApiDocument() *openapi.Document {
+
+	document := openapi.NewDocument(API_TITLE, API_VERSION)
+	document.Error = apperrors.Response{}
+
+	document.Components.SecuritySchemes[BEARER_SECURITY] = &openapi.SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"}
+	document.Components.SecuritySchemes[COOKIE_SECURITY] = &openapi.SecurityScheme{Type: "apiKey", In: "cookie", Name: authentication.TOKEN_NAME}
+
+	document.Rules["contract_type"] = func(schema *openapi.Schema) { schema.Enum = jobsModel.CONTRACT_TYPES }
+	document.Rules["job_mode"] = func(schema *openapi.Schema) { schema.Enum = jobsModel.JOB_MODES }
+	document.Rules["username"] = func(schema *openapi.Schema) {
+		minLength := 3
+		schema.MinLength = &minLength
+		schema.Pattern = "^[a-zA-Z0-9]*$"
+	}
+	document.Rules["password"] = func(schema *openapi.Schema) {
+		minLength := 10
+		schema.MinLength = &minLength
+		schema.Format = "password"
+		schema.Description = commons.PASSWORD_POLICY_MESSAGE
+	}
+
+	document.Add(
+		openapi.Route{Method: http.MethodGet, Path: OPENAPI_PATH, Tag: "docs", Summary: "Este documento OpenAPI", Response: openapi.Object(nil)},
+		openapi.Route{Method: http.MethodGet, Path: DOCS_PATH, Tag: "docs", Summary: "Documentação interativa da API", Response: openapi.String(), ResponseType: "text/html"},
+		openapi.Route{Method: http.MethodGet, Path: "/metrics", Tag: "health", Summary: "Métricas no formato do Prometheus", Response: openapi.String(), ResponseType: "text/plain"},
+		openapi.Route{Method: http.MethodGet, Path: "/health", Tag: "health", Summary: "Status da API", Response: openapi.Object(map[string]*openapi.Schema{"status": openapi.String()})},
+		openapi.Route{Method: http.MethodGet, Path: "/health/live", Tag: "health", Summary: "O processo está no ar", Response: openapi.Object(map[string]*openapi.Schema{"status": openapi.String()})},
+		openapi.Route{Method: http.MethodGet, Path: "/health/ready", Tag: "health", Summary: "Status de cada dependência; 503 quando uma crítica está fora", Response: health.Report{}},
+
+		// The short URLs, at the root only
+		openapi.Route{Method: http.MethodGet, Path: "/r/:code", Tag: "short-urls", Summary: "Redireciona para o anúncio", Status: http.StatusTemporaryRedirect},
+		openapi.Route{Method: http.MethodGet, Path: "/j/:code", Tag: "short-urls", Summary: "Redireciona para a vaga", Status: http.StatusTemporaryRedirect},
+	)
+
+	document.Add(openapi.WithPrefix(API_V1, apiRoutes(API_V1))...)
+	document.Add(openapi.WithPrefix(API_V2, apiRoutes(API_V2))...)
+	document.Add(rootAliases(apiRoutes(""))...)
+
+	return document
+}
+
+// apiRoutes describes the routes registered for the version by api.register.
+func apiRoutes(version string) []openapi.Route {
+
+	// listing picks the response of a listing by version.
+	listing := func(v1 interface{}, v2 interface{}) interface{} {
+		if version == API_V2 {
+			return v2
+		}
+		return v1
+	}
+
+	routes := []openapi.Route{
+		// Admin users
+		{Method: http.MethodPost, Path: "/admin/users", Tag: "admin", Security: authenticated, Summary: "Lista os usuários", Request: commons.FilterRequest{}, Response: listing(usersModel.UsersPaginatedResult{}, commons.Page[usersModel.UserView]{}), Deprecated: version == API_V1},
+		{Method: http.MethodGet, Path: "/admin/users/:id", Tag: "admin", Security: authenticated, Summary: "Perfil de um usuário", Response: users.UserProfileResponse{}},
+		{Method: http.MethodDelete, Path: "/admin/users/:id", Tag: "admin", Security: authenticated, Summary: "Exclui um usuário", Response: successBody},
+		{Method: http.MethodGet, Path: "/admin/lockouts", Tag: "admin", Security: authenticated, Summary: "Contas bloqueadas por tentativas de login", Response: []usersModel.UserLockout{}},
+		{Method: http.MethodDelete, Path: "/admin/lockouts/:id", Tag: "admin", Security: authenticated, Summary: "Desbloqueia uma conta", Response: successBody},
+
+		// Admin jobs
+		{Method: http.MethodPost, Path: "/admin/jobs", Tag: "admin", Security: authenticated, Summary: "Lista as vagas", Request: commons.FilterRequest{}, Response: listing(jobsModel.JobsPaginatedResult{}, commons.Page[jobsModel.Job]{}), Deprecated: version == API_V1},
+		{Method: http.MethodPut, Path: "/admin/jobs/:code", Tag: "admin", Security: authenticated, Summary: "Aprova ou encerra uma vaga", Request: jobsModel.UpdateJobBody{}, Response: jobsModel.Job{}},
+		{Method: http.MethodDelete, Path: "/admin/jobs/:code", Tag: "admin", Security: authenticated, Summary: "Exclui uma vaga", Response: messageBody},
+		{Method: http.MethodPost, Path: "/admin/jobs/new", Tag: "admin", Security: authenticated, Summary: "Cadastra uma vaga", Request: jobsModel.CreateJobBody{}, Response: jobsModel.Job{}},
+		{Method: http.MethodGet, Path: "/admin/jobs/:code", Tag: "admin", Security: authenticated, Summary: "Uma vaga", Response: jobsModel.Job{}},
+
+		// Admin shopping
+		{Method: http.MethodPost, Path: "/admin/ad-references", Tag: "admin", Security: authenticated, Summary: "Lista os anúncios", Request: commons.FilterRequest{}, Response: listing(shopping.AdReferencesPaginatedResult{}, commons.Page[shopping.AdReference]{}), Deprecated: version == API_V1},
+		{Method: http.MethodGet, Path: "/admin/ad-references/:id", Tag: "admin", Security: authenticated, Summary: "Um anúncio", Response: shopping.AdReference{}},
+		{Method: http.MethodPut, Path: "/admin/ad-references/:id", Tag: "admin", Security: authenticated, Summary: "Atualiza um anúncio", Request: shopping.AdReference{}, Response: messageBody},
+		{Method: http.MethodDelete, Path: "/admin/ad-references/:id", Tag: "admin", Security: authenticated, Summary: "Exclui um anúncio", Response: messageBody},
+		{Method: http.MethodPost, Path: "/admin/ad-reference", Tag: "admin", Security: authenticated, Summary: "Cadastra um anúncio", Request: shopping.AdReference{}, Response: messageBody, Status: http.StatusCreated},
+
+		// Authentication
+		{Method: http.MethodPost, Path: "/auth/login", Tag: "auth", Summary: "Login com e-mail e senha", Request: usersModel.AuthRequestBody{}, Response: usersModel.AuthResponse{}},
+		{Method: http.MethodGet, Path: "/auth/logout", Tag: "auth", Summary: "Logout", Response: messageBody},
+		{Method: http.MethodPost, Path: "/auth/forgotten-password", Tag: "auth", Summary: "Envia o e-mail de alteração de senha", Request: users.ResetPasswordRequest{}, Response: successBody},
+		{Method: http.MethodPost, Path: "/auth/verify-reset-token", Tag: "auth", Summary: "Verifica o token de alteração de senha", Request: users.VerifyResestTokenRequest{}, Response: successBody},
+		{Method: http.MethodPost, Path: "/auth/reset-password", Tag: "auth", Summary: "Altera a senha", Request: users.ResetPasswordRequestBody{}, Response: successBody},
+		{Method: http.MethodGet, Path: "/auth/unlock/:token", Tag: "auth", Summary: "Desbloqueia a conta pelo link do e-mail", Response: openapi.Object(map[string]*openapi.Schema{"isUnlocked": openapi.Boolean()})},
+		{Method: http.MethodPost, Path: "/auth/authorize", Tag: "auth", Security: authenticated, Summary: "Verifica se o usuário tem um dos papéis", Request: users.AuthorizeRequest{}, Response: openapi.Object(map[string]*openapi.Schema{"isAuthorized": openapi.Boolean()})},
+		{Method: http.MethodPost, Path: "/oauth/google", Tag: "auth", Summary: "Login com o Google", Request: google.GoogleTokenRequest{}, Response: usersModel.AuthResponse{}},
+		{Method: http.MethodPost, Path: "/auth/signup", Tag: "auth", Summary: "Cadastro", Request: users.SignUpRequest{}, Response: openapi.Object(map[string]*openapi.Schema{"isRegistered": openapi.Boolean()}), Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/auth/signup/confirm-email/:token", Tag: "auth", Summary: "Confirma o e-mail do cadastro", Response: openapi.Object(map[string]*openapi.Schema{"isEmailConfirmed": openapi.Boolean()})},
+		{Method: http.MethodGet, Path: "/auth/refresh-token", Tag: "auth", Summary: "Renova o token de acesso", Response: usersModel.AuthResponse{}},
+
+		// Jobs
+		{Method: http.MethodPost, Path: "/jobs/search", Tag: "jobs", Summary: "Busca as vagas aprovadas", Request: jobsModel.JobFilter{}, Response: listing(jobsModel.PaginatedResult{}, commons.Page[jobsModel.JobViewPublic]{}), Deprecated: version == API_V1},
+		{Method: http.MethodPost, Path: "/jobs/aggregated-values", Tag: "jobs", Summary: "Valores dos filtros da busca das vagas aprovadas", Request: jobsModel.JobFilter{}, Response: listing(jobsModel.JobFilterOptions{}, jobsModel.JobFacets{}), Deprecated: version == API_V1},
+		{Method: http.MethodPost, Path: "/jobs", Tag: "jobs", Security: authenticated, Summary: "Cadastra uma vaga", Request: jobsModel.CreateJobBody{}, Response: jobsModel.Job{}},
+		{Method: http.MethodGet, Path: "/jobs/:code", Tag: "jobs", Summary: "Detalhes de uma vaga aprovada", Response: jobs.JobDetailView{}},
+
+		// Short URLs
+		{Method: http.MethodGet, Path: "/go/:code", Tag: "short-urls", Summary: "URL original da vaga", Response: openapi.Object(map[string]*openapi.Schema{"originalUrl": openapi.String()})},
+
+		// Shopping
+		{Method: http.MethodGet, Path: "/shopping/ad-references", Tag: "shopping", Summary: "Anúncios", Response: []shopping.AdReference{}},
+
+		// Talents
+		{Method: http.MethodPost, Path: "/talents", Tag: "users", Summary: "Busca os perfis públicos", Request: commons.FilterRequest{}, Response: listing(usersModel.UserTalentsPaginatedResult{}, commons.Page[usersModel.UserTalentView]{}), Deprecated: version == API_V1},
+
+		// Users
+		{Method: http.MethodPost, Path: "/user/gravatar", Tag: "users", Summary: "URL do Gravatar de um e-mail", Request: users.GravatarRequest{}, Response: openapi.Object(map[string]*openapi.Schema{"gravatarUrl": openapi.String()})},
+		{Method: http.MethodGet, Path: "/users/profile", Tag: "users", Security: authenticated, Summary: "Perfil do usuário", Response: users.UserProfileResponse{}},
+		{Method: http.MethodGet, Path: "/users/profile/:username", Tag: "users", Summary: "Perfil público de um usuário", Response: users.UserProfileResponse{}},
+		{Method: http.MethodPut, Path: "/users/profile", Tag: "users", Security: authenticated, Summary: "Atualiza o perfil", Request: users.UpdateUserRequest{}, Response: users.UserProfileResponse{}},
+		{Method: http.MethodPost, Path: "/users/username", Tag: "users", Security: authenticated, Summary: "Altera o nome de usuário", Request: users.UpdateUserNameRequest{}, Response: openapi.Object(map[string]*openapi.Schema{"success": openapi.Boolean(), "user_name": openapi.String(), "message": openapi.String()})},
+		{Method: http.MethodPatch, Path: "/users/bookmarks", Tag: "users", Security: authenticated, Summary: "Altera as vagas salvas", Request: users.UpdateUserBookmarkRequest{}, Response: []string{}},
+		{Method: http.MethodPost, Path: "/users/profile-picture", Tag: "users", Security: authenticated, Summary: "Envia a foto de perfil", Request: openapi.Object(map[string]*openapi.Schema{"file": {Type: "string", Format: "binary"}}), RequestType: "multipart/form-data", Response: openapi.Object(map[string]*openapi.Schema{"success": openapi.Boolean(), "profile_picture": openapi.String(), "message": openapi.String()})},
+		{Method: http.MethodDelete, Path: "/users/profile", Tag: "users", Security: authenticated, Summary: "Exclui a conta", Response: successBody},
+	}
+
+	if version != API_V2 {
+		return routes
+	}
+
+	// The routes added after v1 was frozen
+	onlyV2 := "Só na v2: não existe na v1 nem na raiz."
+
+	return append(routes,
+		openapi.Route{Method: http.MethodPost, Path: "/jobs/mine", Tag: "jobs", Security: authenticated, Summary: "Lista as vagas cadastradas pelo usuário, com os cliques", Description: onlyV2, Request: commons.FilterRequest{}, Response: commons.Page[jobsModel.Job]{}},
+		openapi.Route{Method: http.MethodPut, Path: "/jobs/:code", Tag: "jobs", Security: authenticated, Summary: "Altera os detalhes da vaga, pelo criador ou um admin", Description: onlyV2, Request: jobsModel.EditJobBody{}, Response: jobsModel.Job{}},
+		openapi.Route{Method: http.MethodPatch, Path: "/jobs/:code/status", Tag: "jobs", Security: authenticated, Summary: "Muda o estado da vaga: os admins aprovam ou recusam, o criador envia para revisão ou fecha", Description: onlyV2, Request: jobsModel.ChangeJobStatusBody{}, Response: jobsModel.Job{}},
+		openapi.Route{Method: http.MethodPatch, Path: "/jobs/:code/renew", Tag: "jobs", Security: authenticated, Summary: "Renova a vaga por mais um período, pelo criador ou um admin", Description: onlyV2, Response: jobs.RenewedJobView{}},
+	)
+}
+
+// rootAliases marks the routes at the root, the aliases of v1, as deprecated.
+func rootAliases(routes []openapi.Route) []openapi.Route {
+	for i := range routes {
+		routes[i].Deprecated = true
+	}
+	return routes
+}
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	ratelimiting "github.com/flaviofrancisco/vagasprajr-api-v2/middlewares/ratelimit"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// The rate limits of the routes open to anonymous clients.
+var (
+	LoginPolicy           = ratelimit.Policy{Name: "login", Algorithm: ratelimit.FIXED_WINDOW, Limit: 10, Window: time.Minute}
+	SignUpPolicy          = ratelimit.Policy{Name: "signup", Algorithm: ratelimit.FIXED_WINDOW, Limit: 5, Window: time.Hour}
+	PasswordResetPolicy   = ratelimit.Policy{Name: "password_reset", Algorithm: ratelimit.FIXED_WINDOW, Limit: 5, Window: time.Hour}
+	OAuthPolicy           = ratelimit.Policy{Name: "oauth", Algorithm: ratelimit.FIXED_WINDOW, Limit: 20, Window: time.Minute}
+	GravatarPolicy        = ratelimit.Policy{Name: "gravatar", Algorithm: ratelimit.TOKEN_BUCKET, Limit: 30, Window: time.Minute}
+	PublicJobSearchPolicy = ratelimit.Policy{Name: "jobs_search", Algorithm: ratelimit.TOKEN_BUCKET, Limit: 120, Window: time.Minute}
+)
+
+// rateLimitFunc builds the rate limit middleware of a policy.
+type rateLimitFunc func(policy ratelimit.Policy, key ratelimiting.KeyFunc) gin.HandlerFunc
+
+// newRateLimit returns the function building the rate limit middleware of a
+// policy, which does nothing when the rate limits are disabled.
+func newRateLimit(settings config.Config, limiter ratelimit.Limiter) rateLimitFunc {
+	return func(policy ratelimit.Policy, key ratelimiting.KeyFunc) gin.HandlerFunc {
+
+		if !settings.RateLimit.Enabled {
+			return func(c *gin.Context) { c.Next() }
+		}
+
+		return ratelimiting.RateLimitMiddleware(limiter, policy, key)
+	}
+}
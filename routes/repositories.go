@@ -0,0 +1,96 @@
+package routes
+
+import (
+	"context"
+	"errors"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/cache"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/jobs"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/promotions"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/roles"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/shopping"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users/tokens"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/emails"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/health"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/oauth/google"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/ratelimit"
+)
+
+// Repositories holds the storage and services injected into the controllers.
+type Repositories struct {
+	Jobs         jobs.JobRepository
+	Users        users.UserRepository
+	Tokens       tokens.TokenStore
+	AdReferences shopping.AdReferenceRepository
+	Ads          promotions.AdRepository
+	GoogleUsers  google.GoogleUserRepository
+	EmailSender  emails.Sender
+	HealthChecks []health.Check
+	Cache        cache.Store
+	RateLimiter  ratelimit.Limiter
+}
+
+func NewMongoRepositories(db *models.DbContext, settings config.Config) Repositories {
+
+	redis := cache.NewRedisClient(settings.Redis)
+	store := cache.NewFallback(redis, cache.NewMemory(settings.Cache.MemorySize), settings.Cache.RedisRetryInterval)
+
+	return Repositories{
+		Jobs:         jobs.NewCachedJobRepository(jobs.NewMongoJobRepository(db, settings.BaseUiHost), store, settings.Cache),
+		Users:        users.NewMongoUserRepository(db, store),
+		Tokens:       tokens.NewMongoTokenStore(db, settings.Auth),
+		AdReferences: shopping.NewMongoAdReferenceRepository(db),
+		Ads:          promotions.NewMongoAdRepository(db),
+		GoogleUsers:  google.NewMongoGoogleUserRepository(db),
+		EmailSender:  emails.NewBackgroundSender(emails.NewSmtpSender(db)),
+		HealthChecks: []health.Check{
+			health.MongoCheck(db),
+			health.RedisCheck(redis),
+			health.DirectoryCheck("uploads", settings.UploadsDir),
+			health.SmtpSettingsCheck(db),
+		},
+		Cache:       store,
+		RateLimiter: ratelimit.NewFallback(ratelimit.NewRedis(redis), ratelimit.NewMemory(), settings.Cache.RedisRetryInterval),
+	}
+}
+
+// NewInMemoryRepositories returns empty in-memory repositories, for tests.
+func NewInMemoryRepositories(settings config.Config, roles ...roles.Role) Repositories {
+	return Repositories{
+		Jobs:         jobs.NewInMemoryJobRepository(settings.BaseUiHost),
+		Users:        users.NewInMemoryUserRepository(roles...),
+		Tokens:       tokens.NewInMemoryTokenStore(settings.Auth),
+		AdReferences: shopping.NewInMemoryAdReferenceRepository(),
+		Ads:          promotions.NewInMemoryAdRepository(),
+		GoogleUsers:  google.NewInMemoryGoogleUserRepository(),
+		EmailSender:  emails.NewInMemorySender(),
+		HealthChecks: []health.Check{},
+		Cache:        cache.NewMemory(settings.Cache.MemorySize),
+		RateLimiter:  ratelimit.NewMemory(),
+	}
+}
+
+// Shutdown waits for the background work of the repositories, such as the
+// emails still being sent and the cache entries being refreshed, or for the
+// context to be done, and then closes the cache.
+func (repositories Repositories) Shutdown(ctx context.Context) error {
+
+	var err error
+
+	if jobs, ok := repositories.Jobs.(interface{ Shutdown(context.Context) error }); ok {
+		err = jobs.Shutdown(ctx)
+	}
+
+	if sender, ok := repositories.EmailSender.(interface{ Shutdown(context.Context) error }); ok {
+		err = errors.Join(err, sender.Shutdown(ctx))
+	}
+
+	if closer, ok := repositories.Cache.(interface{ Close() error }); ok {
+		err = errors.Join(err, closer.Close())
+	}
+
+	return err
+}
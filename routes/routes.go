@@ -1,114 +1,233 @@
 package routes
 
 import (
-	"os"
+	"log/slog"
+	"strings"
 	"time"
 
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/controllers"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/controllers/health"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/controllers/jobs"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/controllers/shopping"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/controllers/shorturls"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/controllers/users"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares/authentication"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares/authorization"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares/deprecation"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares/errorhandler"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares/httpcache"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares/ratelimit"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares/requestid"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares/requestlog"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/middlewares/requestmetrics"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/openapi"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/lockout"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/services/oauth/google"
 	"github.com/gin-contrib/cors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoutes(server *gin.Engine) {
+const (
+	API_V1 = "/v1"
+	API_V2 = "/v2"
+)
+
+func RegisterRoutes(server *gin.Engine, settings config.Config, repositories Repositories) {
+
+	// The client IP, which the rate limits and the login lockout count by, is
+	// read from the forwarding headers only when set by a trusted proxy.
+	if err := server.SetTrustedProxies(settings.TrustedProxies); err != nil {
+		slog.Error("Invalid trusted proxies, trusting none", "error", err)
+		server.SetTrustedProxies(nil)
+	}
+
+	// The tracing middleware goes first, so the logs of the request carry its trace ID.
+	// The error handler goes last, so the log and the metrics see the status it answers.
+	server.Use(otelgin.Middleware(settings.Tracing.ServiceName), requestid.RequestIdMiddleware(), requestlog.RequestLogMiddleware(), requestmetrics.RequestMetricsMiddleware(), errorhandler.ErrorHandlerMiddleware())
 
-	debug := os.Getenv("DEBUG_MODE")	
+	server.NoRoute(func(c *gin.Context) {
+		c.Error(apperrors.NotFound("Rota não encontrada"))
+	})
 
 	allowedOrigins := []string{"https://vagasprajr.com", "https://vagasprajr.com.br", "https://vagasparajr.com", "https://vagasparajr.com.br", "https://api.vagasprajr.com"}
 
-	if debug == "true" {
+	if settings.DebugMode {
 		allowedOrigins = []string{"http://localhost:3000", "http://localhost:3001", "http://www.localhost:3000"}
 	}
 
     server.Use(cors.New(cors.Config{
         AllowOrigins:     allowedOrigins,
         AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-        AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-        ExposeHeaders:    []string{"Content-Length"},
+        AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", requestid.REQUEST_ID_HEADER, ratelimit.API_KEY_HEADER},
+        ExposeHeaders:    append(append(append([]string{"Content-Length", requestid.REQUEST_ID_HEADER}, ratelimit.Headers...), deprecation.Headers...), httpcache.Headers...),
         AllowCredentials: true,
         MaxAge:           12 * time.Hour,
     }))	
 
 
+	rateLimit := newRateLimit(settings, repositories.RateLimiter)
+
+	api := &api{
+		settings: settings,
+		repositories: repositories,
+		rateLimit: rateLimit,
+		jobs: jobs.NewController(settings, repositories.Jobs, repositories.Users, repositories.EmailSender),
+		shopping: shopping.NewController(repositories.AdReferences),
+		shortUrls: shorturls.NewController(settings, repositories.Jobs, repositories.Ads),
+		users: users.NewController(settings, repositories.Users, repositories.Tokens, repositories.EmailSender, lockout.NewGuard(settings, repositories.Users, repositories.RateLimiter, repositories.EmailSender)),
+		google: google.NewController(settings, repositories.GoogleUsers, repositories.Users, repositories.Tokens, repositories.EmailSender),
+	}
+	healthController := health.NewController(repositories.HealthChecks)
+
+	// API documentation
+	apiDocument := NewApiDocument()
+	server.GET(OPENAPI_PATH, apiDocument.Handler())
+	server.GET(DOCS_PATH, openapi.UiHandler(API_TITLE, OPENAPI_PATH))
+
+	// Prometheus metrics
+	server.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
 	// Health check
 	server.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"status": "ok",
 		})
 	})
+	server.GET("/health/live", healthController.Live)
+	server.GET("/health/ready", healthController.Ready)
+
+	// The short URLs are shared as links, so they stay at the root, out of the versions.
+	// Redirect to the ad's original URL from the short URL
+	server.GET("/r/:code", api.shortUrls.RedirectToOriginalAdURL)
+	// Redirect to the job's original URL from the short URL
+	server.GET("/j/:code", api.shortUrls.RedirectToOriginalJobUrl)
+
+	// Versions: v1 is frozen, v2 answers the listings in the snake_case page
+	// envelope. The routes at the root are deprecated aliases of v1.
+	deprecated := deprecation.Policy{Since: settings.Api.DeprecatedSinceDate(), Sunset: settings.Api.SunsetDate()}
+
+	api.register(server.Group(API_V1), API_V1, deprecated)
+	api.register(server.Group(API_V2), API_V2, deprecated)
+
+	rootAliases := deprecated
+	rootAliases.Successor = func(path string) string { return API_V1 + path }
+	api.register(server.Group("", deprecation.DeprecationMiddleware(rootAliases)), "", deprecated)
+}
+
+// api holds the controllers of the versioned routes.
+type api struct {
+	settings config.Config
+	repositories Repositories
+	rateLimit rateLimitFunc
+	jobs *jobs.Controller
+	shopping *shopping.Controller
+	shortUrls *shorturls.Controller
+	users *users.Controller
+	google *google.Controller
+}
+
+// register adds the routes of the version to the router; the version is
+// empty for the root aliases, which are the routes of v1.
+func (api *api) register(router *gin.RouterGroup, version string, deprecated deprecation.Policy) {
+
+	settings := api.settings
+	rateLimit := api.rateLimit
+	byApiKey := ratelimit.ByApiKey(settings.RateLimit.ApiKeys)
+
+	// versioned picks the handler of a route whose answer changed in v2: in v1
+	// it is deprecated in favor of the v2 route.
+	v1Listing := deprecated
+	v1Listing.Successor = func(path string) string { return API_V2 + strings.TrimPrefix(path, API_V1) }
+
+	versioned := func(v1 gin.HandlerFunc, v2 gin.HandlerFunc) []gin.HandlerFunc {
+		switch version {
+		case API_V2:
+			return []gin.HandlerFunc{v2}
+		case API_V1:
+			return []gin.HandlerFunc{deprecation.DeprecationMiddleware(v1Listing), v1}
+		}
+		return []gin.HandlerFunc{v1}
+	}
 
 	// Admin routes
-	admin := server.Group("/admin")
-	admin.Use(authentication.AuthMiddleware(), authorization.AuthorizationMiddleware([]string{controllers.ADMIN}))
+	admin := router.Group("/admin")
+	admin.Use(authentication.AuthMiddleware(settings.Auth), authorization.AuthorizationMiddleware(api.repositories.Users, []string{controllers.ADMIN}))
 
 	// Admin Users
-	admin.POST("/users", users.GetUsers)
-	admin.GET("/users/:id", users.GetUser)
-	admin.DELETE("/users/:id", users.DeleteUserAsAdmin)
+	admin.POST("/users", versioned(api.users.GetUsers, api.users.GetUsersV2)...)
+	admin.GET("/users/:id", api.users.GetUser)
+	admin.DELETE("/users/:id", api.users.DeleteUserAsAdmin)
+	admin.GET("/lockouts", api.users.GetLockouts)
+	admin.DELETE("/lockouts/:id", api.users.ClearLockout)
 
 	//Admin Jobs
-	admin.POST("/jobs", jobs.GetJobsAsAdmin)
-	admin.PUT("/jobs/:code", jobs.UpdateJob)
-	admin.DELETE("/jobs/:code", jobs.DeleteJob)
-	admin.POST("/jobs/new", jobs.CreateJob)
-	admin.GET("/jobs/:code", jobs.GetJobAsAdmin)
+	admin.POST("/jobs", versioned(api.jobs.GetJobsAsAdmin, api.jobs.GetJobsAsAdminV2)...)
+	admin.PUT("/jobs/:code", api.jobs.UpdateJob)
+	admin.DELETE("/jobs/:code", api.jobs.DeleteJob)
+	admin.POST("/jobs/new", api.jobs.CreateJob)
+	admin.GET("/jobs/:code", api.jobs.GetJobAsAdmin)
 
 	//Admin Shopping
-	admin.POST("/ad-references", shopping.GetFilteredAdReferences)	
-	admin.GET("/ad-references/:id", shopping.GetAdReference)
-	admin.PUT("/ad-references/:id", shopping.UpdateAdReference)
-	admin.DELETE("/ad-references/:id", shopping.DeleteAdReference)
-	admin.POST("/ad-reference", shopping.CreateAdReference)
+	admin.POST("/ad-references", versioned(api.shopping.GetFilteredAdReferences, api.shopping.GetFilteredAdReferencesV2)...)
+	admin.GET("/ad-references/:id", api.shopping.GetAdReference)
+	admin.PUT("/ad-references/:id", api.shopping.UpdateAdReference)
+	admin.DELETE("/ad-references/:id", api.shopping.DeleteAdReference)
+	admin.POST("/ad-reference", api.shopping.CreateAdReference)
 
 	// Authentication	
-	server.POST("/auth/login", users.Login)	
-	server.GET("/auth/logout", users.LogOut)		
-	server.POST("/auth/forgotten-password", users.RequestPasswordReset)	
-	server.POST("/auth/verify-reset-token", users.VerifyRessetToken)
-	server.POST("/auth/reset-password", users.ResetPassword)	
-	server.POST("/auth/authorize", authentication.AuthMiddleware(), users.IsAuthorized)	
-	server.POST("/oauth/google", google.OAuthGoogle)
+	router.POST("/auth/login", rateLimit(LoginPolicy, ratelimit.ByIp), api.users.Login)	
+	router.GET("/auth/logout", api.users.LogOut)		
+	router.POST("/auth/forgotten-password", rateLimit(PasswordResetPolicy, ratelimit.ByIp), api.users.RequestPasswordReset)	
+	router.POST("/auth/verify-reset-token", api.users.VerifyRessetToken)
+	router.POST("/auth/reset-password", api.users.ResetPassword)
+	router.GET("/auth/unlock/:token", api.users.UnlockAccount)	
+	router.POST("/auth/authorize", authentication.AuthMiddleware(settings.Auth), api.users.IsAuthorized)	
+	router.POST("/oauth/google", rateLimit(OAuthPolicy, ratelimit.ByIp), api.google.OAuthGoogle)
 	
 	// Jobs
-	server.POST("/jobs/search", jobs.GetJobs)
-	server.POST("/jobs/aggregated-values", jobs.GetAggregatedJobsValues)
-	server.POST("/jobs", authentication.AuthMiddleware(), jobs.CreateJob)
-	server.GET("/jobs/:code", jobs.GetJob)
+	router.POST("/jobs/search", append([]gin.HandlerFunc{rateLimit(PublicJobSearchPolicy, byApiKey)}, versioned(api.jobs.GetJobs, api.jobs.GetJobsV2)...)...)
+	router.POST("/jobs/aggregated-values", append([]gin.HandlerFunc{rateLimit(PublicJobSearchPolicy, byApiKey)}, versioned(api.jobs.GetAggregatedJobsValues, api.jobs.GetAggregatedJobsValuesV2)...)...)
+	router.POST("/jobs", authentication.AuthMiddleware(settings.Auth), api.jobs.CreateJob)
+	router.GET("/jobs/:code", httpcache.CacheControlMiddleware(settings.HttpCache.JobMaxAge), api.jobs.GetJob)
 
 	//Short URLs
 	// Get the original job's URL from the short URL
-	server.GET("/go/:code", shorturls.GetOriginalURL)	
-	// Redirect to the ad's original URL from the short URL
-	server.GET("/r/:code", shorturls.RedirectToOriginalAdURL)
-	// Redirect to the job's original URL from the short URL
-	server.GET("/j/:code", shorturls.RedirectToOriginalJobUrl)
+	router.GET("/go/:code", api.shortUrls.GetOriginalURL)	
 
 	//Shopping
-	server.GET("/shopping/ad-references", shopping.GetAdReferences)
+	router.GET("/shopping/ad-references", httpcache.CacheControlMiddleware(settings.HttpCache.AdReferencesMaxAge), api.shopping.GetAdReferences)
 
 	// Singn Up
-	server.POST("/auth/signup", users.SignUp)
-	server.GET("/auth/signup/confirm-email/:token", users.ConfirmEmail)
+	router.POST("/auth/signup", rateLimit(SignUpPolicy, ratelimit.ByIp), api.users.SignUp)
+	router.GET("/auth/signup/confirm-email/:token", api.users.ConfirmEmail)
 
 	//Talents
-	server.POST("/talents", users.GetTalents)
+	router.POST("/talents", versioned(api.users.GetTalents, api.users.GetTalentsV2)...)
 	
 	// Token
-	server.GET("/auth/refresh-token", users.RefreshToken)
+	router.GET("/auth/refresh-token", api.users.RefreshToken)
 
 	// Users	
-	server.POST("/user/gravatar", users.GetGravatarUrl)
-	server.GET("/users/profile", authentication.AuthMiddleware(), users.GetUserProfile)
-	server.GET("/users/profile/:username", users.GetPublicUserProfile)
-	server.PUT("/users/profile", authentication.AuthMiddleware(), users.UpdateUser)
-	server.POST("/users/username", authentication.AuthMiddleware(), users.UpdateUserName)
-	server.PATCH("/users/bookmarks",authentication.AuthMiddleware(), users.UpdateUserBookmarkedJobs)	
-	server.POST("/users/profile-picture", authentication.AuthMiddleware(), users.UploadProfilePicture)	
-	server.DELETE("/users/profile", authentication.AuthMiddleware(), users.DeleteUser)
+	router.POST("/user/gravatar", rateLimit(GravatarPolicy, ratelimit.ByIp), api.users.GetGravatarUrl)
+	router.GET("/users/profile", authentication.AuthMiddleware(settings.Auth), api.users.GetUserProfile)
+	router.GET("/users/profile/:username", httpcache.CacheControlMiddleware(settings.HttpCache.ProfileMaxAge), api.users.GetPublicUserProfile)
+	router.PUT("/users/profile", authentication.AuthMiddleware(settings.Auth), api.users.UpdateUser)
+	router.POST("/users/username", authentication.AuthMiddleware(settings.Auth), api.users.UpdateUserName)
+	router.PATCH("/users/bookmarks",authentication.AuthMiddleware(settings.Auth), api.users.UpdateUserBookmarkedJobs)	
+	router.POST("/users/profile-picture", authentication.AuthMiddleware(settings.Auth), api.users.UploadProfilePicture)	
+	router.DELETE("/users/profile", authentication.AuthMiddleware(settings.Auth), api.users.DeleteUser)
+
+	if version != API_V2 {
+		return
+	}
+
+	// The routes added after v1 was frozen are only in v2
+	router.POST("/jobs/mine", authentication.AuthMiddleware(settings.Auth), api.jobs.GetMyJobs)
+	router.PUT("/jobs/:code", authentication.AuthMiddleware(settings.Auth), api.jobs.EditJob)
+	router.PATCH("/jobs/:code/renew", authentication.AuthMiddleware(settings.Auth), api.jobs.RenewJob)
+	router.PATCH("/jobs/:code/status", authentication.AuthMiddleware(settings.Auth), api.jobs.ChangeJobStatus)
 }
\ No newline at end of file
@@ -0,0 +1,1224 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/jobs"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/promotions"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/roles"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/shopping"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users/tokens"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/emails"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/lockout"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/health"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const testBaseUiHost = "https://vagasprajr.test"
+
+type testServer struct {
+	settings     config.Config
+	server       *gin.Engine
+	jobs         *jobs.InMemoryJobRepository
+	users        *users.InMemoryUserRepository
+	adReferences *shopping.InMemoryAdReferenceRepository
+	emailSender  *emails.InMemorySender
+	adminRole    roles.Role
+}
+
+func newTestServer(t *testing.T, seedJobs []jobs.Job, ads ...promotions.AdItem) *testServer {
+	return newTestServerWithSettings(t, config.Default(), seedJobs, ads...)
+}
+
+func newTestServerWithSettings(t *testing.T, settings config.Config, seedJobs []jobs.Job, ads ...promotions.AdItem) *testServer {
+	gin.SetMode(gin.TestMode)
+
+	settings.BaseUiHost = testBaseUiHost
+	settings.Auth.JwtSecret = "test-secret"
+
+	adminRole := roles.Role{Id: primitive.NewObjectID().Hex(), Name: "admin"}
+
+	test := &testServer{
+		settings:     settings,
+		server:       gin.New(),
+		jobs:         jobs.NewInMemoryJobRepository(settings.BaseUiHost, seedJobs...),
+		users:        users.NewInMemoryUserRepository(adminRole),
+		adReferences: shopping.NewInMemoryAdReferenceRepository(),
+		emailSender:  emails.NewInMemorySender(),
+		adminRole:    adminRole,
+	}
+
+	repositories := NewInMemoryRepositories(settings, adminRole)
+	repositories.Jobs = test.jobs
+	repositories.Users = test.users
+	repositories.AdReferences = test.adReferences
+	repositories.Ads = promotions.NewInMemoryAdRepository(ads...)
+	repositories.EmailSender = test.emailSender
+
+	RegisterRoutes(test.server, settings, repositories)
+
+	return test
+}
+
+func (test *testServer) addUser(isAdmin bool) users.User {
+	user := users.User{
+		Id:               primitive.NewObjectID(),
+		FirstName:        "Maria",
+		Email:            primitive.NewObjectID().Hex() + "@vagasprajr.test",
+		IsEmailConfirmed: true,
+	}
+
+	if isAdmin {
+		roleId, _ := primitive.ObjectIDFromHex(test.adminRole.Id)
+		user.Roles = []primitive.ObjectID{roleId}
+	}
+
+	test.users.AddUser(user)
+
+	return user
+}
+
+func (test *testServer) request(t *testing.T, method string, path string, body interface{}, user *users.User) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+
+	if body != nil {
+		assert.NoError(t, json.NewEncoder(&payload).Encode(body))
+	}
+
+	request := httptest.NewRequest(method, path, &payload)
+	request.Header.Set("Content-Type", "application/json")
+
+	if user != nil {
+		userToken := tokens.UserToken{}
+		assert.NoError(t, userToken.SetToken(test.settings.Auth, users.UserTokenInfo{Id: user.Id, Email: user.Email, Roles: user.Roles}))
+		request.Header.Set("Authorization", "Bearer "+userToken.Token)
+	}
+
+	recorder := httptest.NewRecorder()
+	test.server.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func decode[T any](t *testing.T, recorder *httptest.ResponseRecorder) T {
+	var result T
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+	return result
+}
+
+func seedJobs() []jobs.Job {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	return []jobs.Job{
+		{Id: "1", Code: "AAAAA1", Title: "Desenvolvedor Go Junior", Company: "Acme", Location: "Remoto", Provider: "vagasprajr", CreatedAt: createdAt, IsApproved: true, JobShortUrl: testBaseUiHost + "/go/AAAAA1", Url: "https://acme.test/vaga"},
+		{Id: "2", Code: "AAAAA2", Title: "Desenvolvedor Java Junior", Company: "Globex", Location: "São Paulo", Provider: "linkedin", CreatedAt: createdAt.Add(time.Hour), IsApproved: true},
+		{Id: "3", Code: "AAAAA3", Title: "Estágio Go", Company: "Acme", Location: "Recife", Provider: "vagasprajr", CreatedAt: createdAt.Add(2 * time.Hour), IsClosed: true},
+	}
+}
+
+func TestHealth(t *testing.T) {
+	test := newTestServer(t, nil)
+
+	recorder := test.request(t, http.MethodGet, "/health", nil, nil)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestHealthProbes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	settings := config.Default()
+	repositories := NewInMemoryRepositories(settings)
+	repositories.HealthChecks = []health.Check{
+		{Name: "mongodb", Critical: true, Check: func(ctx context.Context) error { return nil }},
+		{Name: "smtp_settings", Check: func(ctx context.Context) error { return errors.New("SMTP host is not configured") }},
+	}
+
+	server := gin.New()
+	RegisterRoutes(server, settings, repositories)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusOK, get("/health/live").Code)
+
+	recorder := get("/health/ready")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	report := decode[health.Report](t, recorder)
+	assert.Equal(t, health.STATUS_UP, report.Status)
+	assert.Equal(t, health.STATUS_DOWN, report.Checks["smtp_settings"].Status)
+
+	repositories.HealthChecks = append(repositories.HealthChecks, health.Check{Name: "redis", Critical: true, Check: func(ctx context.Context) error { return errors.New("failed to connect to Redis") }})
+	server = gin.New()
+	RegisterRoutes(server, settings, repositories)
+
+	recorder = get("/health/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "failed to connect to Redis")
+	report = decode[health.Report](t, recorder)
+	assert.Equal(t, health.STATUS_DOWN, report.Status)
+	assert.Equal(t, health.STATUS_DOWN, report.Checks["redis"].Status)
+	assert.Equal(t, health.STATUS_UP, report.Checks["mongodb"].Status)
+
+	assert.Equal(t, http.StatusOK, get("/health/live").Code)
+}
+
+func TestRateLimit(t *testing.T) {
+	test := newTestServer(t, nil)
+	login := map[string]string{"email": "maria@vagasprajr.test", "password": "wrong"}
+
+	// Each email is tried once, so the logins are not delayed by the lockout.
+	for i := 0; i < LoginPolicy.Limit; i++ {
+		login["email"] = fmt.Sprintf("maria%d@vagasprajr.test", i)
+		recorder := test.request(t, http.MethodPost, "/auth/login", login, nil)
+		assert.NotEqual(t, http.StatusTooManyRequests, recorder.Code)
+		assert.Equal(t, strconv.Itoa(LoginPolicy.Limit-i-1), recorder.Header().Get("RateLimit-Remaining"))
+	}
+
+	recorder := test.request(t, http.MethodPost, "/auth/login", login, nil)
+
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "10", recorder.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "10;w=60", recorder.Header().Get("RateLimit-Policy"))
+	assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+	assert.Equal(t, apperrors.CODE_TOO_MANY_REQUESTS, decode[apperrors.Response](t, recorder).Code)
+
+	// The other policies have their own counters.
+	recorder = test.request(t, http.MethodPost, "/jobs/search", jobs.JobFilter{Page: 1, PageSize: 10}, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, strconv.Itoa(PublicJobSearchPolicy.Limit-1), recorder.Header().Get("RateLimit-Remaining"))
+}
+
+func TestRateLimitIgnoresForwardedFor(t *testing.T) {
+	test := newTestServer(t, nil)
+
+	post := func(forwardedFor string) *httptest.ResponseRecorder {
+		login := map[string]string{"email": forwardedFor + "@vagasprajr.test", "password": "wrong"}
+		payload, _ := json.Marshal(login)
+		request := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(payload))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("X-Forwarded-For", forwardedFor)
+		recorder := httptest.NewRecorder()
+		test.server.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// Without trusted proxies a spoofed header is not a new client.
+	for i := 0; i < LoginPolicy.Limit; i++ {
+		assert.NotEqual(t, http.StatusTooManyRequests, post(fmt.Sprintf("203.0.113.%d", i)).Code)
+	}
+
+	assert.Equal(t, http.StatusTooManyRequests, post("198.51.100.1").Code)
+}
+
+func TestRateLimitByIssuedApiKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	settings := config.Default()
+	settings.RateLimit.ApiKeys = []string{"partner-key"}
+
+	server := gin.New()
+	RegisterRoutes(server, settings, NewInMemoryRepositories(settings))
+
+	search := func(apiKey string) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(jobs.JobFilter{Page: 1, PageSize: 10})
+		request := httptest.NewRequest(http.MethodPost, "/jobs/search", bytes.NewReader(payload))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("X-API-Key", apiKey)
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// A made up key shares the counter of the IP address
+	for i := 0; i < PublicJobSearchPolicy.Limit; i++ {
+		assert.Equal(t, http.StatusOK, search(fmt.Sprintf("random-%d", i)).Code)
+	}
+
+	assert.Equal(t, http.StatusTooManyRequests, search("random-key").Code)
+
+	// An issued key has its own counter
+	recorder := search("partner-key")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, strconv.Itoa(PublicJobSearchPolicy.Limit-1), recorder.Header().Get("RateLimit-Remaining"))
+}
+
+func TestRequestId(t *testing.T) {
+	test := newTestServer(t, nil)
+
+	recorder := test.request(t, http.MethodGet, "/health", nil, nil)
+	assert.NotEmpty(t, recorder.Header().Get("X-Request-ID"))
+
+	request := httptest.NewRequest(http.MethodGet, "/health", nil)
+	request.Header.Set("X-Request-ID", "req-123")
+	recorder = httptest.NewRecorder()
+	test.server.ServeHTTP(recorder, request)
+	assert.Equal(t, "req-123", recorder.Header().Get("X-Request-ID"))
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	test := newTestServer(t, nil)
+
+	request := httptest.NewRequest(http.MethodGet, "/users/profile", nil)
+	request.Header.Set("Authorization", "Bearer not.a.token")
+	request.Header.Set("X-Request-ID", "req-456")
+	recorder := httptest.NewRecorder()
+	test.server.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	response := decode[apperrors.Response](t, recorder)
+	assert.Equal(t, apperrors.CODE_UNAUTHORIZED, response.Code)
+	assert.Equal(t, "req-456", response.RequestId)
+	assert.NotContains(t, response.Message, "not.a.token")
+
+	recorder = test.request(t, http.MethodPost, "/auth/forgotten-password", gin.H{}, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	response = decode[apperrors.Response](t, recorder)
+	assert.Equal(t, apperrors.CODE_VALIDATION, response.Code)
+	assert.Equal(t, []interface{}{map[string]interface{}{"field": "email", "message": "Campo obrigatório"}}, response.Details)
+
+	recorder = test.request(t, http.MethodGet, "/jobs/NAOEXISTE", nil, nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, apperrors.CODE_NOT_FOUND, decode[apperrors.Response](t, recorder).Code)
+
+	recorder = test.request(t, http.MethodGet, "/rota/inexistente", nil, nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, apperrors.CODE_NOT_FOUND, decode[apperrors.Response](t, recorder).Code)
+}
+
+func TestOpenApiDocumentCoversEveryRoute(t *testing.T) {
+	test := newTestServer(t, nil)
+	document := NewApiDocument()
+
+	registered := map[string]bool{}
+	for _, route := range test.server.Routes() {
+		assert.True(t, document.Has(route.Method, route.Path), "%s %s is missing from the OpenAPI document", route.Method, route.Path)
+		registered[route.Method+" "+route.Path] = true
+	}
+
+	// Nor does the document describe routes that are gone.
+	assert.Len(t, document.Operations(), len(registered))
+}
+
+func TestOpenApiDocument(t *testing.T) {
+	test := newTestServer(t, nil)
+
+	recorder := test.request(t, http.MethodGet, OPENAPI_PATH, nil, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var document struct {
+		OpenAPI    string                                       `json:"openapi"`
+		Paths      map[string]map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Required   []string                          `json:"required"`
+				Properties map[string]map[string]interface{} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &document))
+
+	assert.Equal(t, "3.0.3", document.OpenAPI)
+	assert.Contains(t, document.Paths["/jobs/{code}"], "get")
+	assert.Contains(t, document.Paths["/admin/jobs/{code}"]["put"], "security")
+	assert.Contains(t, document.Paths["/v2/jobs/{code}"]["put"], "description")
+	assert.NotContains(t, document.Paths["/v1/jobs/{code}"], "put")
+
+	createJob := document.Components.Schemas["CreateJobBody"]
+	assert.ElementsMatch(t, []string{"title", "company_name", "url"}, createJob.Required)
+	assert.Equal(t, "uri", createJob.Properties["url"]["format"])
+	assert.Len(t, createJob.Properties["contract_type"]["enum"], len(jobs.CONTRACT_TYPES))
+	assert.Contains(t, document.Components.Schemas, "Response")
+
+	recorder = test.request(t, http.MethodGet, DOCS_PATH, nil, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), OPENAPI_PATH)
+}
+
+func TestApiVersions(t *testing.T) {
+	test := newTestServer(t, seedJobs())
+	filter := jobs.JobFilter{Page: 1, PageSize: 1}
+
+	recorder := test.request(t, http.MethodPost, "/v2/jobs/search", filter, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Deprecation"))
+	page := decode[commons.Page[jobs.JobViewPublic]](t, recorder)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, int64(2), *page.Pagination.Total)
+	assert.Equal(t, 2, *page.Pagination.TotalPages)
+	assert.NotEmpty(t, page.Pagination.NextCursor)
+	assert.Empty(t, page.Pagination.PrevCursor)
+
+	// The listings of v1 keep their shape, deprecated in favor of v2.
+	recorder = test.request(t, http.MethodPost, "/v1/jobs/search", filter, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, int64(2), decode[jobs.PaginatedResult](t, recorder).Total)
+	assert.Equal(t, "true", recorder.Header().Get("Deprecation"))
+	assert.Equal(t, `</v2/jobs/search>; rel="successor-version"`, recorder.Header().Get("Link"))
+
+	recorder = test.request(t, http.MethodGet, "/v1/jobs/AAAAA1", nil, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Deprecation"))
+
+	// The routes at the root are aliases of v1.
+	recorder = test.request(t, http.MethodGet, "/jobs/AAAAA1", nil, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `</v1/jobs/AAAAA1>; rel="successor-version"`, recorder.Header().Get("Link"))
+
+	admin := test.addUser(true)
+	recorder = test.request(t, http.MethodPost, "/v2/admin/users", commons.FilterRequest{Page: 1, PageSize: 10}, &admin)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, int64(1), *decode[commons.Page[users.UserView]](t, recorder).Pagination.Total)
+
+	// The routes added after v1 was frozen are only in v2.
+	for _, prefix := range []string{"/v1", ""} {
+		assert.Equal(t, http.StatusNotFound, test.request(t, http.MethodPost, prefix+"/jobs/mine", commons.FilterRequest{}, &admin).Code)
+		assert.Equal(t, http.StatusNotFound, test.request(t, http.MethodPut, prefix+"/jobs/AAAAA1", jobs.EditJobBody{}, &admin).Code)
+		assert.Equal(t, http.StatusNotFound, test.request(t, http.MethodPatch, prefix+"/jobs/AAAAA1/renew", nil, &admin).Code)
+		assert.Equal(t, http.StatusNotFound, test.request(t, http.MethodPatch, prefix+"/jobs/AAAAA1/status", nil, &admin).Code)
+	}
+	assert.Equal(t, http.StatusOK, test.request(t, http.MethodPost, "/v2/jobs/mine", commons.FilterRequest{}, &admin).Code)
+
+	// The short URLs are not versioned.
+	assert.Equal(t, http.StatusTemporaryRedirect, test.request(t, http.MethodGet, "/j/AAAAA1", nil, nil).Code)
+	assert.Empty(t, test.request(t, http.MethodGet, "/j/AAAAA1", nil, nil).Header().Get("Deprecation"))
+}
+
+func TestCursorPagination(t *testing.T) {
+	seed := seedJobs()
+	seed[2].IsClosed, seed[2].IsApproved = false, true
+	test := newTestServer(t, seed)
+
+	search := func(filter jobs.JobFilter) commons.Page[jobs.JobViewPublic] {
+		recorder := test.request(t, http.MethodPost, "/v2/jobs/search", filter, nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		return decode[commons.Page[jobs.JobViewPublic]](t, recorder)
+	}
+
+	first := search(jobs.JobFilter{PageSize: 1, SkipTotal: true})
+	assert.Equal(t, "AAAAA3", first.Data[0].Code)
+	assert.Nil(t, first.Pagination.Total)
+
+	// A job published while scrolling does not repeat the last one seen.
+	job, err := test.jobs.CreateJob(context.Background(), jobs.CreateJobBody{Title: "Desenvolvedor Python Junior", Company: "Initech", Url: "https://initech.test/vaga"})
+	assert.NoError(t, err)
+	_, err = test.jobs.TransitionJob(context.Background(), job.Id, jobs.JobTransition{From: jobs.JOB_STATUS_PENDING_REVIEW, To: jobs.JOB_STATUS_APPROVED, At: time.Now()})
+	assert.NoError(t, err)
+
+	second := search(jobs.JobFilter{PageSize: 1, Cursor: first.Pagination.NextCursor})
+	assert.Equal(t, "AAAAA2", second.Data[0].Code)
+	assert.Equal(t, int64(4), *second.Pagination.Total)
+
+	third := search(jobs.JobFilter{PageSize: 1, Cursor: second.Pagination.NextCursor})
+	assert.Equal(t, "AAAAA1", third.Data[0].Code)
+	assert.Empty(t, third.Pagination.NextCursor)
+
+	previous := search(jobs.JobFilter{PageSize: 2, Cursor: third.Pagination.PrevCursor})
+	assert.Equal(t, []string{"AAAAA3", "AAAAA2"}, []string{previous.Data[0].Code, previous.Data[1].Code})
+	assert.NotEmpty(t, previous.Pagination.PrevCursor)
+	assert.NotEmpty(t, previous.Pagination.NextCursor)
+
+	// The cursor belongs to the order it was taken from.
+	recorder := test.request(t, http.MethodPost, "/v2/jobs/search", jobs.JobFilter{PageSize: 1, IsAscending: true, Cursor: first.Pagination.NextCursor}, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	recorder = test.request(t, http.MethodPost, "/v2/jobs/search", jobs.JobFilter{PageSize: 1, Cursor: "invalido"}, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+}
+
+func TestTextSearch(t *testing.T) {
+	test := newTestServer(t, seedJobs())
+
+	search := func(filter jobs.JobFilter) []string {
+		recorder := test.request(t, http.MethodPost, "/v2/jobs/search", filter, nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		codes := []string{}
+		for _, job := range decode[commons.Page[jobs.JobViewPublic]](t, recorder).Data {
+			codes = append(codes, job.Code)
+		}
+		return codes
+	}
+
+	// The search is ordered by relevance, stemmed and without accents.
+	assert.Equal(t, []string{"AAAAA1", "AAAAA2"}, search(jobs.JobFilter{Search: "desenvolvedores de Go"}))
+	assert.Equal(t, []string{"AAAAA1"}, search(jobs.JobFilter{Search: "acme", Sort: "created_at"}))
+
+	// The closed job is not found.
+	assert.Empty(t, search(jobs.JobFilter{Search: "estagio"}))
+
+	recorder := test.request(t, http.MethodPost, "/v2/jobs/search", jobs.JobFilter{Sort: commons.SORT_RELEVANCE}, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	recorder = test.request(t, http.MethodPost, "/v2/jobs/search", jobs.JobFilter{Search: "go", Cursor: "abc"}, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	recorder = test.request(t, http.MethodPost, "/v2/jobs/search", jobs.JobFilter{Search: "go", Ids: []string{"1"}}, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"field":"search"`)
+}
+
+func TestDeprecationDates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	settings := config.Default()
+	settings.Api.DeprecatedSince = "2026-10-01"
+	settings.Api.Sunset = "2027-06-30"
+
+	server := gin.New()
+	RegisterRoutes(server, settings, NewInMemoryRepositories(settings))
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/shopping/ad-references", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "@1790812800", recorder.Header().Get("Deprecation"))
+	assert.Equal(t, "Wed, 30 Jun 2027 00:00:00 GMT", recorder.Header().Get("Sunset"))
+}
+
+func TestSearchJobs(t *testing.T) {
+	test := newTestServer(t, seedJobs())
+
+	recorder := test.request(t, http.MethodPost, "/jobs/search", jobs.JobFilter{Title: "go junior"}, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	result := decode[jobs.PaginatedResult](t, recorder)
+	assert.Equal(t, int64(1), result.Total)
+	assert.Equal(t, "AAAAA1", result.Data[0].Code)
+
+	recorder = test.request(t, http.MethodPost, "/jobs/search", jobs.JobFilter{Page: 2, PageSize: 1, JobFilterOptions: jobs.JobFilterOptions{Companies: []string{"Acme", "Globex"}}}, nil)
+	result = decode[jobs.PaginatedResult](t, recorder)
+
+	// Sorted by created_at descending, the second page holds the oldest job
+	assert.Equal(t, int64(2), result.Total)
+	assert.Equal(t, 2, result.Page)
+	assert.Len(t, result.Data, 1)
+	assert.Equal(t, "AAAAA1", result.Data[0].Code)
+}
+
+func TestAggregatedJobsValues(t *testing.T) {
+	test := newTestServer(t, seedJobs())
+
+	recorder := test.request(t, http.MethodPost, "/jobs/aggregated-values", jobs.JobFilter{Title: "go"}, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	result := decode[jobs.JobFilterOptions](t, recorder)
+	assert.Equal(t, []string{"Acme"}, result.Companies)
+	assert.Equal(t, []string{"Remoto"}, result.Locations)
+}
+
+func TestFacetedJobsValues(t *testing.T) {
+	seed := seedJobs()
+	seed[0].ContractType, seed[0].AffirmativeParameters.IsWomen = jobs.CONTRACT_TYPE_CLT, true
+	seed[1].ContractType = jobs.CONTRACT_TYPE_PJ
+	seed[2].IsClosed, seed[2].IsApproved = false, true
+	test := newTestServer(t, seed)
+
+	filter := jobs.JobFilter{JobFilterOptions: jobs.JobFilterOptions{Companies: []string{"Acme"}}}
+
+	recorder := test.request(t, http.MethodPost, "/v2/jobs/aggregated-values", filter, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// The selected company narrows the other facets, not its own
+	result := decode[jobs.JobFacets](t, recorder)
+	assert.Equal(t, []jobs.FacetValue{{Value: "Acme", Count: 2}, {Value: "Globex", Count: 1}}, result.Companies)
+	assert.Equal(t, []jobs.FacetValue{{Value: "Recife", Count: 1}, {Value: "Remoto", Count: 1}}, result.Locations)
+	assert.Equal(t, []jobs.FacetValue{{Value: jobs.CONTRACT_TYPE_CLT, Count: 1}}, result.ContractTypes)
+	assert.Equal(t, []jobs.FacetValue{{Value: "is_women", Count: 1}}, result.AffirmativeParameters)
+
+	filter.JobFilterOptions.ContractTypes = []string{jobs.CONTRACT_TYPE_PJ}
+
+	result = decode[jobs.JobFacets](t, test.request(t, http.MethodPost, "/v2/jobs/aggregated-values", filter, nil))
+	assert.Equal(t, []jobs.FacetValue{{Value: "Globex", Count: 1}}, result.Companies)
+	assert.Equal(t, []jobs.FacetValue{{Value: jobs.CONTRACT_TYPE_CLT, Count: 1}}, result.ContractTypes)
+	assert.Empty(t, result.Locations)
+
+	filter.JobFilterOptions.AffirmativeParameters = []string{"is_tall"}
+
+	recorder = test.request(t, http.MethodPost, "/v2/jobs/aggregated-values", filter, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+}
+
+func TestGetJob(t *testing.T) {
+	test := newTestServer(t, seedJobs())
+
+	recorder := test.request(t, http.MethodGet, "/jobs/AAAAA2", nil, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "Globex", decode[gin.H](t, recorder)["company_name"])
+}
+
+func TestJobsNotApprovedAreNotPublic(t *testing.T) {
+	seed := append(seedJobs(),
+		jobs.Job{Id: "4", Code: "AAAAA4", Title: "Desenvolvedor Go Pleno", Company: "Initech", Status: jobs.JOB_STATUS_DRAFT},
+		jobs.Job{Id: "5", Code: "AAAAA5", Title: "Desenvolvedor Go Senior", Company: "Initech", Status: jobs.JOB_STATUS_REJECTED},
+		jobs.Job{Id: "6", Code: "AAAAA6", Title: "Desenvolvedor Go Trainee", Company: "Initech"},
+	)
+	test := newTestServer(t, seed)
+
+	for _, code := range []string{"AAAAA3", "AAAAA4", "AAAAA5", "AAAAA6"} {
+		assert.Equal(t, http.StatusNotFound, test.request(t, http.MethodGet, "/v2/jobs/"+code, nil, nil).Code, code)
+	}
+
+	recorder := test.request(t, http.MethodPost, "/v2/jobs/search", jobs.JobFilter{Title: "go"}, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	page := decode[commons.Page[jobs.JobViewPublic]](t, recorder)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, "AAAAA1", page.Data[0].Code)
+
+	recorder = test.request(t, http.MethodPost, "/v2/jobs/aggregated-values", jobs.JobFilter{}, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, []jobs.FacetValue{{Value: "Acme", Count: 1}, {Value: "Globex", Count: 1}}, decode[jobs.JobFacets](t, recorder).Companies)
+
+	// The jobs asked by id, like the bookmarks, are listed once closed but not before being published
+	bookmarks := jobs.JobFilter{Ids: []string{"1", "3", "4", "5", "6"}}
+
+	recorder = test.request(t, http.MethodPost, "/v2/jobs/search", bookmarks, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	codes := []string{}
+	for _, job := range decode[commons.Page[jobs.JobViewPublic]](t, recorder).Data {
+		codes = append(codes, job.Code)
+	}
+	assert.ElementsMatch(t, []string{"AAAAA1", "AAAAA3"}, codes)
+
+	recorder = test.request(t, http.MethodPost, "/v1/jobs/search", bookmarks, nil)
+	assert.Equal(t, int64(2), decode[jobs.PaginatedResult](t, recorder).Total)
+}
+
+func TestConditionalRequests(t *testing.T) {
+	test := newTestServer(t, seedJobs())
+
+	get := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		recorder := httptest.NewRecorder()
+		test.server.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := get("/v2/jobs/AAAAA2", nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "public, max-age=300", recorder.Header().Get("Cache-Control"))
+	etag, lastModified := recorder.Header().Get("ETag"), recorder.Header().Get("Last-Modified")
+	assert.True(t, strings.HasPrefix(etag, `W/"`))
+	assert.NotEmpty(t, lastModified)
+
+	recorder = get("/v2/jobs/AAAAA2", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+	assert.Equal(t, etag, recorder.Header().Get("ETag"))
+
+	recorder = get("/v2/jobs/AAAAA2", map[string]string{"If-Modified-Since": lastModified})
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+
+	// An updated job is answered again, with another ETag.
+	job, _ := test.jobs.GetJob(context.Background(), "AAAAA2")
+	edited, err := test.jobs.EditJob(context.Background(), job.Id, jobs.EditJobBody{Title: "Desenvolvedor Java Pleno", Url: job.Url}, job.Creator, jobs.JobTransition{From: job.CurrentStatus()})
+	assert.NoError(t, err)
+	assert.True(t, edited)
+
+	recorder = get("/v2/jobs/AAAAA2", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotEqual(t, etag, recorder.Header().Get("ETag"))
+
+	// The errors are not cached.
+	recorder = get("/v2/jobs/ZZZZZ9", nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Cache-Control"))
+
+	assert.NoError(t, test.adReferences.CreateAdReference(context.Background(), shopping.AdReference{Description: "Livro", IsActive: true}))
+
+	recorder = get("/v2/shopping/ad-references", nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "public, max-age=600", recorder.Header().Get("Cache-Control"))
+	assert.Equal(t, http.StatusNotModified, get("/v2/shopping/ad-references", map[string]string{"If-None-Match": recorder.Header().Get("ETag")}).Code)
+}
+
+func TestCreateJobRequiresAuthentication(t *testing.T) {
+	test := newTestServer(t, nil)
+
+	body := jobs.CreateJobBody{Title: "Nova vaga", Company: "Acme", Url: "https://acme.test/nova-vaga", ContractType: jobs.CONTRACT_TYPE_CLT}
+
+	recorder := test.request(t, http.MethodPost, "/jobs", body, nil)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	user := test.addUser(false)
+
+	recorder = test.request(t, http.MethodPost, "/jobs", body, &user)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	job := decode[jobs.Job](t, recorder)
+	assert.Equal(t, user.Id, job.Creator)
+	assert.Equal(t, "vagasprajr", job.Provider)
+
+	stored, _ := test.jobs.GetJob(context.Background(), job.Code)
+	assert.Equal(t, "Nova vaga", stored.Title)
+	assert.Equal(t, jobs.CONTRACT_TYPE_CLT, stored.ContractType)
+}
+
+func TestRenewJob(t *testing.T) {
+	test := newTestServer(t, nil)
+	ctx := context.Background()
+	creator, other, admin := test.addUser(false), test.addUser(false), test.addUser(true)
+
+	recorder := test.request(t, http.MethodPost, "/jobs", jobs.CreateJobBody{Title: "Nova vaga", Company: "Acme", Url: "https://acme.test/nova-vaga"}, &creator)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// The lifetime of a job starts when it is approved
+	job := decode[jobs.Job](t, recorder)
+	assert.True(t, job.ExpiresAt.IsZero())
+
+	recorder = test.request(t, http.MethodPatch, "/v2/jobs/"+job.Code+"/renew", nil, &other)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = test.request(t, http.MethodPatch, "/v2/jobs/"+job.Code+"/renew", nil, &creator)
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+
+	recorder = test.request(t, http.MethodPatch, "/v2/jobs/"+job.Code+"/status", jobs.ChangeJobStatusBody{Status: jobs.JOB_STATUS_APPROVED}, &admin)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.WithinDuration(t, time.Now().Add(test.settings.Jobs.DefaultLifetime), decode[jobs.Job](t, recorder).ExpiresAt, time.Minute)
+
+	// An expired job is approved again by the renewal
+	renewed, err := test.jobs.RenewJob(ctx, job.Id, time.Now().Add(-time.Hour), primitive.NilObjectID)
+	assert.NoError(t, err)
+	assert.True(t, renewed)
+
+	closed, _ := test.jobs.CloseExpiredJobs(ctx, time.Now())
+	assert.Equal(t, int64(1), closed)
+
+	recorder = test.request(t, http.MethodPatch, "/v2/jobs/"+job.Code+"/renew", nil, &creator)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	view := decode[map[string]interface{}](t, recorder)
+
+	stored, _ := test.jobs.GetJob(ctx, job.Code)
+	assert.Equal(t, jobs.JOB_STATUS_APPROVED, stored.Status)
+	assert.False(t, stored.IsClosed)
+	assert.WithinDuration(t, time.Now().Add(test.settings.Jobs.DefaultLifetime), stored.ExpiresAt, time.Minute)
+	assert.Equal(t, stored.ExpiresAt.Format(time.RFC3339Nano), view["expires_at"])
+
+	// A job that expired meanwhile is not renewed as an approved one
+	renewed, err = test.jobs.RenewJob(ctx, job.Id, time.Now().Add(-time.Hour), primitive.NilObjectID)
+	assert.NoError(t, err)
+	assert.True(t, renewed)
+
+	closed, _ = test.jobs.CloseExpiredJobs(ctx, time.Now())
+	assert.Equal(t, int64(1), closed)
+
+	renewed, err = test.jobs.RenewJob(ctx, job.Id, time.Now().Add(time.Hour), primitive.NilObjectID)
+	assert.NoError(t, err)
+	assert.False(t, renewed)
+
+	// A job closed by an admin before expiring stays closed
+	recorder = test.request(t, http.MethodPut, "/admin/jobs/"+job.Code, jobs.UpdateJobBody{IsClosed: true}, &admin)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = test.request(t, http.MethodPatch, "/v2/jobs/"+job.Code+"/renew", nil, &admin)
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+}
+
+func TestRecruiterJobs(t *testing.T) {
+	test := newTestServer(t, seedJobs())
+	ctx := context.Background()
+	creator, other := test.addUser(false), test.addUser(false)
+
+	recorder := test.request(t, http.MethodPost, "/jobs", jobs.CreateJobBody{Title: "Nova vaga", Company: "Acme", Url: "https://acme.test/nova-vaga"}, &creator)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	job := decode[jobs.Job](t, recorder)
+	assert.NoError(t, test.jobs.UpdateJobClicks(ctx, job.JobShortUrl))
+
+	// The listing shows only the jobs of the user, whatever their status
+	recorder = test.request(t, http.MethodPost, "/v2/jobs/mine", commons.FilterRequest{}, &creator)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	page := decode[commons.Page[jobs.Job]](t, recorder)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, job.Code, page.Data[0].Code)
+	assert.Equal(t, jobs.JOB_STATUS_PENDING_REVIEW, page.Data[0].Status)
+	assert.Equal(t, 1, page.Data[0].QtyClicks)
+
+	recorder = test.request(t, http.MethodPost, "/v2/jobs/mine", commons.FilterRequest{}, &other)
+	assert.Empty(t, decode[commons.Page[jobs.Job]](t, recorder).Data)
+
+	edit := jobs.EditJobBody{Title: "Vaga editada", Description: "Descrição", Salary: "R$ 5.000", Location: "Recife", Url: "https://acme.test/vaga-editada", ContractType: jobs.CONTRACT_TYPE_PJ}
+
+	recorder = test.request(t, http.MethodPut, "/v2/jobs/"+job.Code, edit, &other)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = test.request(t, http.MethodPut, "/v2/jobs/"+job.Code, jobs.EditJobBody{Title: "Vaga editada", Url: "acme"}, &creator)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	recorder = test.request(t, http.MethodPut, "/v2/jobs/"+job.Code, edit, &creator)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	edited := decode[jobs.Job](t, recorder)
+	assert.Equal(t, "Vaga editada", edited.Title)
+	assert.Equal(t, "https://acme.test/vaga-editada", edited.Url)
+	assert.Equal(t, jobs.CONTRACT_TYPE_PJ, edited.ContractType)
+	assert.Equal(t, "Acme", edited.Company)
+	assert.Equal(t, jobs.JOB_STATUS_PENDING_REVIEW, edited.Status)
+	assert.Equal(t, creator.Id, edited.UdatedBy)
+	assert.False(t, edited.LastUpdate.IsZero())
+
+	// A closed job is no longer edited
+	recorder = test.request(t, http.MethodPatch, "/v2/jobs/"+job.Code+"/status", jobs.ChangeJobStatusBody{Status: jobs.JOB_STATUS_CLOSED}, &creator)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = test.request(t, http.MethodPut, "/v2/jobs/"+job.Code, edit, &creator)
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+}
+
+func TestEditedApprovedJobIsReviewedAgain(t *testing.T) {
+	test := newTestServer(t, nil)
+	creator, admin := test.addUser(false), test.addUser(true)
+
+	recorder := test.request(t, http.MethodPost, "/jobs", jobs.CreateJobBody{Title: "Nova vaga", Company: "Acme", Url: "https://acme.test/nova-vaga"}, &creator)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	job := decode[jobs.Job](t, recorder)
+
+	approve := func() {
+		recorder := test.request(t, http.MethodPatch, "/v2/jobs/"+job.Code+"/status", jobs.ChangeJobStatusBody{Status: jobs.JOB_STATUS_APPROVED}, &admin)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	}
+
+	search := func() []jobs.JobViewPublic {
+		recorder := test.request(t, http.MethodPost, "/v2/jobs/search", jobs.JobFilter{Title: "vaga"}, nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		return decode[commons.Page[jobs.JobViewPublic]](t, recorder).Data
+	}
+
+	approve()
+	assert.Len(t, search(), 1)
+
+	// The edits of the creator are reviewed before they are public
+	edit := jobs.EditJobBody{Title: "Vaga editada", Url: "https://acme.test/vaga-editada"}
+
+	recorder = test.request(t, http.MethodPut, "/v2/jobs/"+job.Code, edit, &creator)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	edited := decode[jobs.Job](t, recorder)
+	assert.Equal(t, "Vaga editada", edited.Title)
+	assert.Equal(t, jobs.JOB_STATUS_PENDING_REVIEW, edited.Status)
+
+	last := edited.StatusHistory[len(edited.StatusHistory)-1]
+	assert.Equal(t, jobs.JOB_STATUS_APPROVED, last.From)
+	assert.Equal(t, jobs.JOB_STATUS_PENDING_REVIEW, last.To)
+	assert.Equal(t, creator.Id, last.By)
+
+	assert.Empty(t, search())
+	assert.Equal(t, http.StatusNotFound, test.request(t, http.MethodGet, "/v2/jobs/"+job.Code, nil, nil).Code)
+
+	// The edit read a status the job has since left
+	approve()
+
+	stale := jobs.JobTransition{From: jobs.JOB_STATUS_PENDING_REVIEW}
+	changed, err := test.jobs.EditJob(context.Background(), job.Id, jobs.EditJobBody{Title: "Vaga perdida", Url: edit.Url}, creator.Id, stale)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	stored, _ := test.jobs.GetJob(context.Background(), job.Code)
+	assert.Equal(t, "Vaga editada", stored.Title)
+	assert.Equal(t, jobs.JOB_STATUS_APPROVED, stored.Status)
+
+	// The edits of an admin keep the job approved
+
+	recorder = test.request(t, http.MethodPut, "/v2/jobs/"+job.Code, jobs.EditJobBody{Title: "Vaga revisada", Url: edit.Url}, &admin)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, jobs.JOB_STATUS_APPROVED, decode[jobs.Job](t, recorder).Status)
+
+	result := search()
+	assert.Len(t, result, 1)
+	assert.Equal(t, "Vaga revisada", result[0].Title)
+}
+
+func TestJobModeration(t *testing.T) {
+	test := newTestServer(t, nil)
+	ctx := context.Background()
+	creator, admin := test.addUser(false), test.addUser(true)
+
+	recorder := test.request(t, http.MethodPost, "/jobs", jobs.CreateJobBody{Title: "Nova vaga", Company: "Acme", Url: "https://acme.test/nova-vaga", IsDraft: true}, &creator)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	job := decode[jobs.Job](t, recorder)
+	assert.Equal(t, jobs.JOB_STATUS_DRAFT, job.Status)
+
+	status := func(user users.User, body jobs.ChangeJobStatusBody) *httptest.ResponseRecorder {
+		return test.request(t, http.MethodPatch, "/v2/jobs/"+job.Code+"/status", body, &user)
+	}
+
+	// Only the admins review a job
+	assert.Equal(t, http.StatusConflict, status(admin, jobs.ChangeJobStatusBody{Status: jobs.JOB_STATUS_APPROVED}).Code)
+	assert.Equal(t, http.StatusOK, status(creator, jobs.ChangeJobStatusBody{Status: jobs.JOB_STATUS_PENDING_REVIEW}).Code)
+	assert.Equal(t, http.StatusForbidden, status(creator, jobs.ChangeJobStatusBody{Status: jobs.JOB_STATUS_APPROVED}).Code)
+
+	recorder = status(admin, jobs.ChangeJobStatusBody{Status: jobs.JOB_STATUS_REJECTED})
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	recorder = status(admin, jobs.ChangeJobStatusBody{Status: jobs.JOB_STATUS_REJECTED, Reason: "Falta o salário"})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	rejected := decode[jobs.Job](t, recorder)
+	assert.Equal(t, jobs.JOB_STATUS_REJECTED, rejected.Status)
+	assert.Equal(t, "Falta o salário", rejected.RejectionReason)
+	assert.Len(t, test.emailSender.Emails(), 1)
+	assert.Equal(t, []string{creator.Email}, test.emailSender.Emails()[0].To)
+	assert.Contains(t, test.emailSender.Emails()[0].Body, "Falta o salário")
+
+	assert.Equal(t, http.StatusOK, status(creator, jobs.ChangeJobStatusBody{Status: jobs.JOB_STATUS_PENDING_REVIEW}).Code)
+	assert.Equal(t, http.StatusOK, status(admin, jobs.ChangeJobStatusBody{Status: jobs.JOB_STATUS_APPROVED}).Code)
+	assert.Len(t, test.emailSender.Emails(), 2)
+	assert.Equal(t, "Vaga aprovada", test.emailSender.Emails()[1].Subject)
+
+	approved, _ := test.jobs.GetJob(ctx, job.Code)
+	assert.True(t, approved.IsApproved)
+	assert.Empty(t, approved.RejectionReason)
+
+	history := []string{}
+	for _, transition := range approved.StatusHistory {
+		history = append(history, transition.From+">"+transition.To)
+	}
+	assert.Equal(t, []string{">draft", "draft>pending_review", "pending_review>rejected", "rejected>pending_review", "pending_review>approved"}, history)
+	assert.Equal(t, admin.Id, approved.StatusHistory[4].By)
+	assert.Equal(t, "Falta o salário", approved.StatusHistory[2].Reason)
+
+	// Closed is final
+	assert.Equal(t, http.StatusOK, status(creator, jobs.ChangeJobStatusBody{Status: jobs.JOB_STATUS_CLOSED}).Code)
+	assert.Equal(t, http.StatusConflict, status(creator, jobs.ChangeJobStatusBody{Status: jobs.JOB_STATUS_PENDING_REVIEW}).Code)
+}
+
+func TestCreateJobValidation(t *testing.T) {
+	test := newTestServer(t, nil)
+	user := test.addUser(false)
+
+	body := jobs.CreateJobBody{Company: "Acme", Url: "acme", ContractType: "efetivo", Description: strings.Repeat("a", 20001)}
+
+	recorder := test.request(t, http.MethodPost, "/jobs", body, &user)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	response := decode[apperrors.Response](t, recorder)
+	assert.Equal(t, apperrors.CODE_VALIDATION, response.Code)
+
+	fields := map[string]interface{}{}
+	for _, detail := range response.Details.([]interface{}) {
+		field := detail.(map[string]interface{})
+		fields[field["field"].(string)] = field["message"]
+	}
+	assert.Equal(t, "Campo obrigatório", fields["title"])
+	assert.Equal(t, "URL inválida", fields["url"])
+	assert.Equal(t, "Deve ter no máximo 20000 caracteres", fields["description"])
+	assert.Contains(t, fields["contract_type"], "Tipo de contrato inválido")
+
+	recorder = test.request(t, http.MethodPost, "/auth/signup", users.User{FirstName: "Ana", Email: "ana@vagasprajr.test", Password: "fraca"}, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Equal(t, commons.PASSWORD_POLICY_MESSAGE, decode[apperrors.Response](t, recorder).Message)
+
+	recorder = test.request(t, http.MethodPost, "/users/username", gin.H{"user_name": "a b"}, &user)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+}
+
+func TestAdminRoutesRequireAdminRole(t *testing.T) {
+	test := newTestServer(t, seedJobs())
+
+	candidate := test.addUser(false)
+	admin := test.addUser(true)
+
+	recorder := test.request(t, http.MethodPost, "/admin/jobs", commons.FilterRequest{}, &candidate)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = test.request(t, http.MethodPost, "/admin/jobs", commons.FilterRequest{
+		Filters: []commons.Filter{{Operator: "and", Fields: []commons.Field{{Name: "is_closed", Value: "true", Type: "boolean"}}}},
+	}, &admin)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	result := decode[jobs.JobsPaginatedResult](t, recorder)
+	assert.Equal(t, int64(1), result.Total)
+	assert.Equal(t, "AAAAA3", result.Data[0].Code)
+
+	// A closed job is not reopened
+	recorder = test.request(t, http.MethodPut, "/admin/jobs/AAAAA3", jobs.UpdateJobBody{IsApproved: true}, &admin)
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+
+	recorder = test.request(t, http.MethodPut, "/admin/jobs/AAAAA1", jobs.UpdateJobBody{IsApproved: true, IsClosed: true}, &admin)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	job, _ := test.jobs.GetJob(context.Background(), "AAAAA1")
+	assert.Equal(t, jobs.JOB_STATUS_CLOSED, job.Status)
+	assert.True(t, job.IsClosed)
+	assert.False(t, job.ClosedAt.IsZero())
+
+	recorder = test.request(t, http.MethodDelete, "/admin/jobs/AAAAA3", nil, &admin)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	job, _ = test.jobs.GetJob(context.Background(), "AAAAA3")
+	assert.Empty(t, job.Code)
+}
+
+func TestShortUrls(t *testing.T) {
+	test := newTestServer(t, seedJobs(), promotions.AdItem{ShortUrl: testBaseUiHost + "/r/ad0001", OriginalUrl: "https://loja.test"})
+
+	recorder := test.request(t, http.MethodGet, "/j/AAAAA1", nil, nil)
+	assert.Equal(t, http.StatusTemporaryRedirect, recorder.Code)
+	assert.Equal(t, "https://acme.test/vaga", recorder.Header().Get("Location"))
+
+	job, _ := test.jobs.GetJob(context.Background(), "AAAAA1")
+	assert.Equal(t, 1, job.QtyClicks)
+
+	recorder = test.request(t, http.MethodGet, "/r/ad0001", nil, nil)
+	assert.Equal(t, http.StatusTemporaryRedirect, recorder.Code)
+	assert.Equal(t, "https://loja.test", recorder.Header().Get("Location"))
+
+	recorder = test.request(t, http.MethodGet, "/go/XXXXXX", nil, nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestMetrics(t *testing.T) {
+	test := newTestServer(t, seedJobs())
+
+	test.request(t, http.MethodGet, "/health", nil, nil)
+	test.request(t, http.MethodGet, "/j/AAAAA1", nil, nil)
+
+	recorder := test.request(t, http.MethodGet, "/metrics", nil, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	body := recorder.Body.String()
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/health",status="200"}`)
+	assert.Contains(t, body, `http_request_duration_seconds_bucket{method="GET",route="/j/:code"`)
+	assert.Contains(t, body, `short_link_redirects_total{prefix="j"}`)
+	assert.Contains(t, body, `cache_requests_total{cache="jobs",result="hit"} 0`)
+}
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	test := newTestServer(t, nil)
+
+	test.request(t, http.MethodGet, "/health", nil, nil)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "/health", spans[0].Name())
+}
+
+func TestSignUpConfirmAndLogin(t *testing.T) {
+	test := newTestServer(t, nil)
+
+	body := users.User{FirstName: "Ana", Email: "Ana@VagasPraJr.test", Password: "Senha@Forte123"}
+
+	recorder := test.request(t, http.MethodPost, "/auth/signup", body, nil)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+
+	assert.Eventually(t, func() bool { return len(test.emailSender.Emails()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"ana@vagasprajr.test"}, test.emailSender.Emails()[0].To)
+
+	login := users.AuthRequestBody{Email: "ana@vagasprajr.test", Password: body.Password}
+
+	recorder = test.request(t, http.MethodPost, "/auth/login", login, nil)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	user, _ := test.users.GetUserByEmail(context.Background(), "ana@vagasprajr.test")
+
+	recorder = test.request(t, http.MethodGet, "/auth/signup/confirm-email/"+user.ValidationToken, nil, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = test.request(t, http.MethodPost, "/auth/login", login, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	response := decode[users.AuthResponse](t, recorder)
+	assert.True(t, response.Success)
+	assert.NotEmpty(t, response.AccessToken)
+
+	refresh := httptest.NewRequest(http.MethodGet, "/auth/refresh-token", nil)
+	refresh.Header.Set("Authorization", "Bearer "+response.AccessToken)
+	recorder = httptest.NewRecorder()
+	test.server.ServeHTTP(recorder, refresh)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	login.Password = "Senha@Errada123"
+	recorder = test.request(t, http.MethodPost, "/auth/login", login, nil)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestLoginLockout(t *testing.T) {
+	settings := config.Default()
+	settings.Login.MaxFailedAttempts = lockout.DELAY_AFTER_ATTEMPTS + 1
+	// The logins of the test would reach the rate limit of the route
+	settings.RateLimit.Enabled = false
+
+	test := newTestServerWithSettings(t, settings, nil)
+	admin := test.addUser(true)
+
+	user := users.User{
+		Id:               primitive.NewObjectID(),
+		FirstName:        "Ana",
+		Email:            "ana@vagasprajr.test",
+		Password:         "Senha@Forte123",
+		IsEmailConfirmed: true,
+	}
+	assert.NoError(t, user.SetSaltedPassword())
+	test.users.AddUser(user)
+
+	login := users.AuthRequestBody{Email: user.Email, Password: "Senha@Errada123"}
+	unknown := users.AuthRequestBody{Email: "ninguem@vagasprajr.test", Password: "Senha@Errada123"}
+
+	// An email without an account is answered as one with an account
+	details := func(recorder *httptest.ResponseRecorder) map[string]interface{} {
+		assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+		assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+		return decode[apperrors.Response](t, recorder).Details.(map[string]interface{})
+	}
+
+	// From the third failed login on, the next attempt has to wait.
+	for i := 0; i < lockout.DELAY_AFTER_ATTEMPTS; i++ {
+		assert.Equal(t, http.StatusUnauthorized, test.request(t, http.MethodPost, "/auth/login", login, nil).Code)
+		assert.Equal(t, http.StatusUnauthorized, test.request(t, http.MethodPost, "/auth/login", unknown, nil).Code)
+	}
+
+	assert.Equal(t, false, details(test.request(t, http.MethodPost, "/auth/login", login, nil))["locked"])
+	assert.Equal(t, false, details(test.request(t, http.MethodPost, "/auth/login", unknown, nil))["locked"])
+
+	// The last failed login allowed locks the email and emails the unlock link
+	// to the account.
+	time.Sleep(lockout.Delay(lockout.DELAY_AFTER_ATTEMPTS))
+
+	assert.Equal(t, http.StatusUnauthorized, test.request(t, http.MethodPost, "/auth/login", login, nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, test.request(t, http.MethodPost, "/auth/login", unknown, nil).Code)
+
+	assert.Eventually(t, func() bool { return len(test.emailSender.Emails()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Contains(t, test.emailSender.Emails()[0].Body, testBaseUiHost+"/desbloqueio?token=")
+
+	assert.Equal(t, true, details(test.request(t, http.MethodPost, "/auth/login", unknown, nil))["locked"])
+
+	login.Password = "Senha@Forte123"
+	assert.Equal(t, true, details(test.request(t, http.MethodPost, "/auth/login", login, nil))["locked"])
+
+	recorder := test.request(t, http.MethodGet, "/admin/lockouts", nil, &admin)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	lockouts := decode[[]users.UserLockout](t, recorder)
+	if assert.Len(t, lockouts, 1) {
+		assert.Equal(t, user.Email, lockouts[0].Email)
+		assert.Equal(t, 1, lockouts[0].Lockouts)
+	}
+
+	locked, _ := test.users.GetUserByEmail(context.Background(), user.Email)
+	recorder = test.request(t, http.MethodGet, "/auth/unlock/"+locked.UnlockToken, nil, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = test.request(t, http.MethodPost, "/auth/login", login, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// The unlock cleared the counters of the limiter, but the lock on the
+	// document still refuses the logins
+	locked.LockedUntil = primitive.NewDateTimeFromTime(time.Now().Add(time.Hour))
+	assert.NoError(t, test.users.LockUser(context.Background(), &locked))
+
+	assert.Equal(t, true, details(test.request(t, http.MethodPost, "/auth/login", login, nil))["locked"])
+
+	recorder = test.request(t, http.MethodDelete, "/admin/lockouts/"+user.Id.Hex(), nil, &admin)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = test.request(t, http.MethodPost, "/auth/login", login, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	lockouts, _ = test.users.GetLockedUsers(context.Background())
+	assert.Empty(t, lockouts)
+}
+
+func TestUserProfile(t *testing.T) {
+	test := newTestServer(t, nil)
+
+	user := test.addUser(false)
+
+	recorder := test.request(t, http.MethodPost, "/users/username", gin.H{"user_name": "maria123"}, &user)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = test.request(t, http.MethodPut, "/users/profile", gin.H{"first_name": "Maria", "city": "Recife", "is_public": true}, &user)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = test.request(t, http.MethodGet, "/users/profile/maria123", nil, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "Recife", decode[gin.H](t, recorder)["city"])
+
+	stored, _ := test.users.GetUserById(context.Background(), user.Id)
+	assert.Equal(t, 1, stored.ProfileViews)
+
+	recorder = test.request(t, http.MethodPatch, "/users/bookmarks", gin.H{"bookmarked_jobs": []string{"1"}}, &user)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, []string{"1"}, decode[[]string](t, recorder))
+
+	recorder = test.request(t, http.MethodDelete, "/users/profile", nil, &user)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	stored, _ = test.users.GetUserById(context.Background(), user.Id)
+	assert.True(t, stored.Id.IsZero())
+}
+
+func TestTalentsOnlyListPublicProfiles(t *testing.T) {
+	test := newTestServer(t, nil)
+
+	test.users.AddUser(users.User{Id: primitive.NewObjectID(), UserName: "publico", IsPublic: true})
+	test.users.AddUser(users.User{Id: primitive.NewObjectID(), UserName: "privado"})
+
+	recorder := test.request(t, http.MethodPost, "/talents", commons.FilterRequest{PageSize: 10}, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	result := decode[users.UserTalentsPaginatedResult](t, recorder)
+	assert.Equal(t, int64(1), result.Total)
+	assert.Equal(t, "publico", result.Data[0].UserName)
+}
+
+func TestAdReferences(t *testing.T) {
+	test := newTestServer(t, nil)
+
+	admin := test.addUser(true)
+
+	recorder := test.request(t, http.MethodPost, "/admin/ad-reference", shopping.AdReference{Description: "Livro", IsActive: true}, &admin)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+
+	recorder = test.request(t, http.MethodPost, "/admin/ad-reference", shopping.AdReference{Description: "Curso"}, &admin)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+
+	recorder = test.request(t, http.MethodGet, "/shopping/ad-references", nil, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	active := decode[[]shopping.AdReference](t, recorder)
+	assert.Len(t, active, 1)
+	assert.Equal(t, "Livro", active[0].Description)
+
+	recorder = test.request(t, http.MethodPost, "/admin/ad-references", commons.FilterRequest{PageSize: 1, Sort: "description", IsAscending: true}, &admin)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	result := decode[shopping.AdReferencesPaginatedResult](t, recorder)
+	assert.Equal(t, int64(2), result.Total)
+	assert.Equal(t, "Curso", result.Data[0].Description)
+
+	recorder = test.request(t, http.MethodDelete, "/admin/ad-references/"+active[0].Id.Hex(), nil, &admin)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = test.request(t, http.MethodGet, "/admin/ad-references/"+active[0].Id.Hex(), nil, &admin)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
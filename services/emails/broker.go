@@ -5,12 +5,13 @@ import (
 
 	"context"
 	"errors"
-	"os"
 	"strconv"
 
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/tracing"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type EmailSettings struct {
@@ -26,28 +27,12 @@ type EmailAuth struct {
 	Pass string `json:"pass"`
 }
 
-func GetEmailSettings() (EmailSettings, error) {
-
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	if err != nil {
-		return EmailSettings{}, err
-	}
-
-	db := client.Database(mongodb_database)
+func GetEmailSettings(ctx context.Context, db *models.DbContext) (EmailSettings, error) {
 
 	// Get the email settings where _id = 'mail'
-	filter := bson.D{{"_id", "mail"}}
+	filter := bson.D{{Key: "_id", Value: "mail"}}
 	var result EmailSettings
-	err = db.Collection("settings").FindOne(context.Background(), filter).Decode(&result)
+	err := db.Collection("settings").FindOne(ctx, filter).Decode(&result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return EmailSettings{}, errors.New("Configurações de email não encontradas")
@@ -57,10 +42,10 @@ func GetEmailSettings() (EmailSettings, error) {
 	return result, nil
 }
 
-func SendEmail(from string, to []string, subject string, body string) error {
+func SendEmail(ctx context.Context, db *models.DbContext, from string, to []string, subject string, body string) error {
 
 	// Get email settings
-	settings, err := GetEmailSettings()
+	settings, err := GetEmailSettings(ctx, db)
 
 	if err != nil {
 		return err
@@ -85,8 +70,12 @@ func SendEmail(from string, to []string, subject string, body string) error {
 		"Content-Type: text/plain; charset=\"UTF-8\"" + "\n\n" +
 		body
 
+	_, span := tracing.Start(ctx, "smtp.send", attribute.String("server.address", smtpHost), attribute.Int("server.port", smtpPort))
+
 	err = smtp.SendMail(smtpHost+":"+strconv.Itoa(smtpPort), auth, from, to, []byte(msg))
 
+	tracing.End(span, err)
+
 	if err != nil {
 		return err
 	}
@@ -1,8 +1,6 @@
 package emails
 
-import "os"
-
-func GetNewRecruiterOrCompanyUserEmail(validationToken string, email string) string {
+func GetNewRecruiterOrCompanyUserEmail(baseUiHost string, validationToken string, email string) string {
 	return `
 	Confirmação de email			
 	
@@ -10,7 +8,7 @@ func GetNewRecruiterOrCompanyUserEmail(validationToken string, email string) str
 	
 	Um novo usuário com email ` + email + ` se cadastrou como recruiter/ empresa.
 	Para confirmar o email, clique ou copie e cole no seu navegador favorito o link abaixo:
-	`+os.Getenv("BASE_UI_HOST")+`/confirmacao?token=` + validationToken + `
+	`+baseUiHost+`/confirmacao?token=` + validationToken + `
 
 	Atenciosamente,
 	Equipe @vagasprajr.
@@ -18,7 +16,7 @@ func GetNewRecruiterOrCompanyUserEmail(validationToken string, email string) str
 	`
 }
 
-func GetCompanyRecruiterAskingLinksEmail() string {
+func GetCompanyRecruiterAskingLinksEmail(baseUiHost string) string {
 	return `
 	Olá,
 	
@@ -26,7 +24,7 @@ func GetCompanyRecruiterAskingLinksEmail() string {
 	Estamos muito felizes em ter você conosco e só precisamos de mais um passo para validar seu cadastro como recruiter/ empresa.
 
 	Responda esse email e no corpo do email, por favor informe o nome da empresa que você representa com o link de suas redes sociais como recruiter e o link do seu perfil no LinkedIn.
-	Esta validação é necessária para garantir que apenas empresas e recrutadores possam cadastrar vagas no: ` + os.Getenv("BASE_UI_HOST") + `
+	Esta validação é necessária para garantir que apenas empresas e recrutadores possam cadastrar vagas no: ` + baseUiHost + `
 
 	Estamos ansiosos para ter você conosco postando vagas para os nossos candidatos.
 
@@ -36,7 +34,7 @@ func GetCompanyRecruiterAskingLinksEmail() string {
 	`
 }
 
-func GetWelcomeEmail(validationToken string) string {
+func GetWelcomeEmail(baseUiHost string, validationToken string) string {
 	return `
 	Confirmação de email			
 	
@@ -44,7 +42,69 @@ func GetWelcomeEmail(validationToken string) string {
 	
 	Seja bem vinda(o) ao Vagas para Jr. 
 	Para confirmar seu email, clique ou copie e cole no seu navegador favorito o link abaixo:
-	`+os.Getenv("BASE_UI_HOST")+`/confirmacao?token=` + validationToken + `
+	`+baseUiHost+`/confirmacao?token=` + validationToken + `
+
+	Atenciosamente,
+	Equipe @vagasprajr.
+	contato@vagasprajr.com.br	`
+}
+
+func GetUnlockAccountEmail(baseUiHost string, unlockToken string, lockedUntil string) string {
+	return `
+	Conta bloqueada temporariamente
+	
+	Olá,
+	
+	Sua conta no Vagas para Jr. foi bloqueada até ` + lockedUntil + ` por excesso de tentativas de login com a senha errada.
+	Se foi você, clique ou copie e cole no seu navegador favorito o link abaixo para desbloqueá-la agora:
+	`+baseUiHost+`/desbloqueio?token=` + unlockToken + `
+
+	Se não foi você, recomendamos trocar sua senha.
+
+	Atenciosamente,
+	Equipe @vagasprajr.
+	contato@vagasprajr.com.br	`
+}
+
+func GetJobExpiryReminderEmail(baseUiHost string, jobTitle string, jobCode string, expiresAt string) string {
+	return `
+	Sua vaga vai expirar
+	
+	Olá,
+	
+	A vaga "` + jobTitle + `" que você publicou no Vagas para Jr. expira em ` + expiresAt + ` e será fechada automaticamente.
+	Se ela ainda estiver aberta, renove-a pelo link abaixo para mantê-la publicada:
+	`+baseUiHost+`/vagas/` + jobCode + `
+
+	Atenciosamente,
+	Equipe @vagasprajr.
+	contato@vagasprajr.com.br	`
+}
+
+func GetJobApprovedEmail(baseUiHost string, jobTitle string, jobCode string) string {
+	return `
+	Vaga aprovada
+	
+	Olá,
+	
+	A vaga "` + jobTitle + `" que você cadastrou no Vagas para Jr. foi aprovada e já está publicada:
+	`+baseUiHost+`/vagas/` + jobCode + `
+
+	Atenciosamente,
+	Equipe @vagasprajr.
+	contato@vagasprajr.com.br	`
+}
+
+func GetJobRejectedEmail(jobTitle string, reason string) string {
+	return `
+	Vaga não aprovada
+	
+	Olá,
+	
+	A vaga "` + jobTitle + `" que você cadastrou no Vagas para Jr. não foi aprovada pelo seguinte motivo:
+	` + reason + `
+
+	Você pode corrigi-la e enviá-la novamente para revisão.
 
 	Atenciosamente,
 	Equipe @vagasprajr.
@@ -0,0 +1,123 @@
+package emails
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
+)
+
+// Sender delivers the emails sent by the controllers. An empty from or to uses
+// the addresses from the email settings.
+type Sender interface {
+	Send(ctx context.Context, from string, to []string, subject string, body string) error
+}
+
+type SmtpSender struct {
+	db *models.DbContext
+}
+
+func NewSmtpSender(db *models.DbContext) *SmtpSender {
+	return &SmtpSender{db: db}
+}
+
+func (sender *SmtpSender) Send(ctx context.Context, from string, to []string, subject string, body string) error {
+	err := SendEmail(ctx, sender.db, from, to, subject, body)
+	metrics.EmailsSent.WithLabelValues(metrics.Result(err)).Inc()
+	return err
+}
+
+// BackgroundSender sends the emails in background goroutines, so the requests
+// do not wait for the SMTP server. The emails keep being sent after the
+// request is done; Shutdown waits for the ones still in flight.
+type BackgroundSender struct {
+	sender  Sender
+	pending sync.WaitGroup
+}
+
+func NewBackgroundSender(sender Sender) *BackgroundSender {
+	return &BackgroundSender{sender: sender}
+}
+
+func (background *BackgroundSender) Send(ctx context.Context, from string, to []string, subject string, body string) error {
+
+	ctx = context.WithoutCancel(ctx)
+
+	background.pending.Add(1)
+
+	go func() {
+		defer background.pending.Done()
+
+		if err := background.sender.Send(ctx, from, to, subject, body); err != nil {
+			slog.ErrorContext(ctx, "Error sending email", "subject", subject, "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// Synchronous returns the sender wrapped by a BackgroundSender, for the
+// callers that run in background already and need to know whether the email
+// was sent, or the sender itself otherwise.
+func Synchronous(sender Sender) Sender {
+
+	if background, ok := sender.(*BackgroundSender); ok {
+		return background.sender
+	}
+
+	return sender
+}
+
+// Shutdown waits for the emails being sent or for the context to be done.
+func (background *BackgroundSender) Shutdown(ctx context.Context) error {
+
+	done := make(chan struct{})
+
+	go func() {
+		background.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type Email struct {
+	From    string
+	To      []string
+	Subject string
+	Body    string
+}
+
+// InMemorySender keeps the emails instead of sending them. It is meant for tests.
+type InMemorySender struct {
+	mutex  sync.Mutex
+	emails []Email
+}
+
+func NewInMemorySender() *InMemorySender {
+	return &InMemorySender{}
+}
+
+func (sender *InMemorySender) Send(ctx context.Context, from string, to []string, subject string, body string) error {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+
+	sender.emails = append(sender.emails, Email{From: from, To: append([]string{}, to...), Subject: subject, Body: body})
+
+	return nil
+}
+
+// Emails returns a copy of the emails sent so far.
+func (sender *InMemorySender) Emails() []Email {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+
+	return append([]Email{}, sender.emails...)
+}
@@ -0,0 +1,39 @@
+package emails
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingSender waits for release before keeping the email.
+type blockingSender struct {
+	InMemorySender
+	release chan struct{}
+}
+
+func (sender *blockingSender) Send(ctx context.Context, from string, to []string, subject string, body string) error {
+	<-sender.release
+	return sender.InMemorySender.Send(ctx, from, to, subject, body)
+}
+
+func TestBackgroundSenderShutdownWaitsForEmails(t *testing.T) {
+	sender := &blockingSender{release: make(chan struct{})}
+	background := NewBackgroundSender(sender)
+
+	requestCtx, cancel := context.WithCancel(context.Background())
+	background.Send(requestCtx, "", []string{"ana@vagasprajr.test"}, "Assunto", "Corpo")
+	cancel()
+
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer shortCancel()
+
+	assert.ErrorIs(t, background.Shutdown(shortCtx), context.DeadlineExceeded)
+
+	close(sender.release)
+
+	assert.NoError(t, background.Shutdown(context.Background()))
+	assert.Len(t, sender.Emails(), 1)
+}
@@ -0,0 +1,162 @@
+package expiry
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/jobs"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/emails"
+)
+
+// Scheduler closes the jobs past their expiry date, stamping ClosedAt, and
+// reminds the creators of the jobs about to expire. Running it on several
+// instances of the API is safe: closing is idempotent and each reminder is
+// claimed before being sent, and given back when it fails. The emails are
+// sent right away, not in background, so a failure is seen.
+type Scheduler struct {
+	settings    config.JobsConfig
+	baseUiHost  string
+	jobs        jobs.JobRepository
+	users       users.UserRepository
+	emailSender emails.Sender
+	now         func() time.Time
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+func NewScheduler(settings config.Config, jobs jobs.JobRepository, users users.UserRepository, emailSender emails.Sender) *Scheduler {
+	return &Scheduler{
+		settings:    settings.Jobs,
+		baseUiHost:  settings.BaseUiHost,
+		jobs:        jobs,
+		users:       users,
+		emailSender: emailSender,
+		now:         time.Now,
+		stop:        make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler in background every ExpiryCheckInterval, the
+// first time right away. It does nothing when the interval is zero.
+func (scheduler *Scheduler) Start() {
+
+	if scheduler.settings.ExpiryCheckInterval <= 0 {
+		return
+	}
+
+	scheduler.done.Add(1)
+
+	go func() {
+		defer scheduler.done.Done()
+
+		ticker := time.NewTicker(scheduler.settings.ExpiryCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := scheduler.Run(context.Background()); err != nil {
+				slog.Error("Error expiring the jobs", "error", err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-scheduler.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown stops the scheduler and waits for the current run, or for the
+// context to be done.
+func (scheduler *Scheduler) Shutdown(ctx context.Context) error {
+
+	close(scheduler.stop)
+
+	done := make(chan struct{})
+
+	go func() {
+		scheduler.done.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run closes the expired jobs and sends the reminders once.
+func (scheduler *Scheduler) Run(ctx context.Context) error {
+
+	now := scheduler.now()
+
+	closed, err := scheduler.jobs.CloseExpiredJobs(ctx, now)
+
+	if err != nil {
+		return err
+	}
+
+	if closed > 0 {
+		metrics.JobsExpired.Add(float64(closed))
+		slog.InfoContext(ctx, "Expired jobs closed", "count", closed)
+	}
+
+	if scheduler.settings.ExpiryReminder <= 0 {
+		return nil
+	}
+
+	expiring, err := scheduler.jobs.GetJobsToRemind(ctx, now.Add(scheduler.settings.ExpiryReminder))
+
+	if err != nil {
+		return err
+	}
+
+	for _, job := range expiring {
+		err = errors.Join(err, scheduler.remind(ctx, job))
+	}
+
+	return err
+}
+
+func (scheduler *Scheduler) remind(ctx context.Context, job jobs.Job) error {
+
+	if job.Creator.IsZero() {
+		return nil
+	}
+
+	claimed, err := scheduler.jobs.ClaimExpiryReminder(ctx, job.Id)
+
+	if err != nil || !claimed {
+		return err
+	}
+
+	if err = scheduler.sendReminder(ctx, job); err != nil {
+		return errors.Join(err, scheduler.jobs.ReleaseExpiryReminder(ctx, job.Id))
+	}
+
+	return nil
+}
+
+func (scheduler *Scheduler) sendReminder(ctx context.Context, job jobs.Job) error {
+
+	creator, err := scheduler.users.GetUserById(ctx, job.Creator)
+
+	if err != nil || creator.Email == "" {
+		return err
+	}
+
+	brasilia := commons.GetBrasiliaTime().Location()
+	body := emails.GetJobExpiryReminderEmail(scheduler.baseUiHost, job.Title, job.Code, job.ExpiresAt.In(brasilia).Format("02/01/2006 15:04"))
+
+	return scheduler.emailSender.Send(ctx, "", []string{creator.Email}, "Sua vaga vai expirar", body)
+}
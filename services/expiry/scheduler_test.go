@@ -0,0 +1,97 @@
+package expiry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/jobs"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/emails"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type failingSender struct {
+	emails.InMemorySender
+	failing bool
+}
+
+func (sender *failingSender) Send(ctx context.Context, from string, to []string, subject string, body string) error {
+	if sender.failing {
+		return errors.New("smtp indisponível")
+	}
+	return sender.InMemorySender.Send(ctx, from, to, subject, body)
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	creator := users.User{Id: primitive.NewObjectID(), Email: "recruiter@vagasprajr.test"}
+	userRepository := users.NewInMemoryUserRepository()
+	userRepository.AddUser(creator)
+
+	jobRepository := jobs.NewInMemoryJobRepository("https://vagasprajr.test",
+		jobs.Job{Id: "1", Code: "AAAAA1", Title: "Desenvolvedor Go", Creator: creator.Id, IsApproved: true, ExpiresAt: now.Add(-time.Minute)},
+		jobs.Job{Id: "2", Code: "AAAAA2", Title: "Desenvolvedor Java", Creator: creator.Id, IsApproved: true, ExpiresAt: now.Add(24 * time.Hour)},
+		jobs.Job{Id: "3", Code: "AAAAA3", Title: "Estágio Go", Creator: creator.Id, IsApproved: true, ExpiresAt: now.Add(10 * 24 * time.Hour)},
+		jobs.Job{Id: "4", Code: "AAAAA4", Title: "Vaga antiga", Creator: creator.Id, IsApproved: true},
+	)
+
+	sender := emails.NewInMemorySender()
+
+	scheduler := NewScheduler(config.Default(), jobRepository, userRepository, sender)
+	scheduler.now = func() time.Time { return now }
+
+	assert.NoError(t, scheduler.Run(ctx))
+
+	expired, _ := jobRepository.GetJob(ctx, "AAAAA1")
+	assert.True(t, expired.IsClosed)
+	assert.Equal(t, now, expired.ClosedAt)
+	assert.True(t, expired.IsExpired())
+
+	for _, code := range []string{"AAAAA2", "AAAAA3", "AAAAA4"} {
+		job, _ := jobRepository.GetJob(ctx, code)
+		assert.False(t, job.IsClosed, code)
+	}
+
+	// Only the job expiring within the reminder period is reminded, once
+	assert.Len(t, sender.Emails(), 1)
+	assert.Equal(t, []string{creator.Email}, sender.Emails()[0].To)
+	assert.Contains(t, sender.Emails()[0].Body, "Desenvolvedor Java")
+
+	assert.NoError(t, scheduler.Run(ctx))
+	assert.Len(t, sender.Emails(), 1)
+}
+
+func TestRunRemindsAgainWhenTheEmailFails(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	creator := users.User{Id: primitive.NewObjectID(), Email: "recruiter@vagasprajr.test"}
+	userRepository := users.NewInMemoryUserRepository()
+	userRepository.AddUser(creator)
+
+	jobRepository := jobs.NewInMemoryJobRepository("https://vagasprajr.test",
+		jobs.Job{Id: "1", Code: "AAAAA1", Title: "Desenvolvedor Java", Creator: creator.Id, IsApproved: true, ExpiresAt: now.Add(24 * time.Hour)},
+	)
+
+	sender := &failingSender{failing: true}
+
+	scheduler := NewScheduler(config.Default(), jobRepository, userRepository, sender)
+	scheduler.now = func() time.Time { return now }
+
+	assert.Error(t, scheduler.Run(ctx))
+	assert.Empty(t, sender.Emails())
+
+	job, _ := jobRepository.GetJob(ctx, "AAAAA1")
+	assert.False(t, job.IsExpiryReminderSent)
+
+	sender.failing = false
+
+	assert.NoError(t, scheduler.Run(ctx))
+	assert.Len(t, sender.Emails(), 1)
+}
@@ -0,0 +1,81 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/cache"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/emails"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// MongoCheck pings the primary of the MongoDB deployment.
+func MongoCheck(db *models.DbContext) Check {
+	return Check{
+		Name:     "mongodb",
+		Critical: true,
+		Check: func(ctx context.Context) error {
+			return db.Client.Ping(ctx, readpref.Primary())
+		},
+	}
+}
+
+// RedisCheck pings the Redis server of the cache. While it is down the
+// in-memory cache is used, so the API keeps serving requests.
+func RedisCheck(redis *cache.Redis) Check {
+	return Check{
+		Name:     "redis",
+		Critical: false,
+		Check: func(ctx context.Context) error {
+			return redis.Ping(ctx)
+		},
+	}
+}
+
+// DirectoryCheck creates and removes a file in the directory, e.g. the
+// uploads directory of the profile pictures.
+func DirectoryCheck(name string, directory string) Check {
+	return Check{
+		Name:     name,
+		Critical: false,
+		Check: func(ctx context.Context) error {
+			if err := os.MkdirAll(directory, 0755); err != nil {
+				return err
+			}
+
+			file, err := os.CreateTemp(directory, ".health-*")
+
+			if err != nil {
+				return err
+			}
+
+			file.Close()
+
+			return os.Remove(file.Name())
+		},
+	}
+}
+
+// SmtpSettingsCheck looks for the email settings in the settings collection.
+// Without them the emails fail, but the API keeps serving requests.
+func SmtpSettingsCheck(db *models.DbContext) Check {
+	return Check{
+		Name:     "smtp_settings",
+		Critical: false,
+		Check: func(ctx context.Context) error {
+			settings, err := emails.GetEmailSettings(ctx, db)
+
+			if err != nil {
+				return err
+			}
+
+			if settings.Host == "" {
+				return errors.New("SMTP host is not configured")
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	STATUS_UP   = "up"
+	STATUS_DOWN = "down"
+)
+
+// Check is a dependency of the API. When a critical dependency is down the
+// API is not ready to receive traffic.
+type Check struct {
+	Name     string
+	Critical bool
+	Check    func(ctx context.Context) error
+}
+
+type CheckResult struct {
+	Status    string `json:"status"`
+	Critical  bool   `json:"critical"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Run runs the checks in parallel, each one bounded by the timeout. The
+// report is down when any critical check fails. The errors are logged but
+// left out of the report, which is public and would expose the hosts and the
+// messages of the drivers.
+func Run(ctx context.Context, checks []Check, timeout time.Duration) Report {
+
+	report := Report{Status: STATUS_UP, Checks: map[string]CheckResult{}}
+
+	var mutex sync.Mutex
+	var wait sync.WaitGroup
+
+	for _, check := range checks {
+
+		wait.Add(1)
+
+		go func(check Check) {
+			defer wait.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check.Check(checkCtx)
+
+			result := CheckResult{
+				Status:    STATUS_UP,
+				Critical:  check.Critical,
+				LatencyMs: time.Since(start).Milliseconds(),
+			}
+
+			if err != nil {
+				result.Status = STATUS_DOWN
+				slog.WarnContext(ctx, "Health check failed", "check", check.Name, "critical", check.Critical, "error", err)
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			report.Checks[check.Name] = result
+
+			if err != nil && check.Critical {
+				report.Status = STATUS_DOWN
+			}
+		}(check)
+	}
+
+	wait.Wait()
+
+	return report
+}
@@ -0,0 +1,262 @@
+package lockout
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/emails"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/services/ratelimit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// DELAY_AFTER_ATTEMPTS is the number of failed logins of an email from
+	// which each new attempt must wait: 1s, then 2s, 4s... up to MAX_DELAY.
+	DELAY_AFTER_ATTEMPTS = 3
+	MAX_DELAY            = time.Minute
+)
+
+// BlockedError refuses a login attempt before the password is checked.
+type BlockedError struct {
+	Message    string
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (err *BlockedError) Error() string {
+	return err.Message
+}
+
+// Guard protects the password logins against brute force. It counts, in the
+// rate limiter, the failed logins of each email, whether or not an account
+// has it, so the answers do not tell which accounts exist, and of each IP
+// address. The lockouts of the accounts are also recorded on their documents,
+// for the unlock link and the admins.
+type Guard struct {
+	settings    config.LoginConfig
+	baseUiHost  string
+	users       users.UserRepository
+	limiter     ratelimit.Limiter
+	emailSender emails.Sender
+	now         func() time.Time
+}
+
+func NewGuard(settings config.Config, users users.UserRepository, limiter ratelimit.Limiter, emailSender emails.Sender) *Guard {
+	return &Guard{
+		settings:    settings.Login,
+		baseUiHost:  settings.BaseUiHost,
+		users:       users,
+		limiter:     limiter,
+		emailSender: emailSender,
+		now:         time.Now,
+	}
+}
+
+// Check returns a *BlockedError when the IP address has failed too many
+// logins, the email or its account is locked or its last failed login is too
+// recent.
+func (guard *Guard) Check(ctx context.Context, email string, ip string) error {
+
+	result, err := guard.limiter.Peek(ctx, guard.ipPolicy(), "ip:"+ip)
+
+	if err != nil {
+		slog.WarnContext(ctx, "Error checking the failed logins of the IP address", "error", err)
+	} else if !result.Allowed {
+		return &BlockedError{Message: "Muitas tentativas de login. Tente novamente mais tarde.", RetryAfter: result.RetryAfter}
+	}
+
+	key := emailKey(email)
+
+	result, err = guard.limiter.Peek(ctx, guard.lockPolicy(), key)
+
+	if err != nil {
+		slog.WarnContext(ctx, "Error checking the lockout of the email", "error", err)
+	} else if !result.Allowed {
+		return &BlockedError{
+			Message:    "Conta bloqueada temporariamente por excesso de tentativas. Enviamos um e-mail com o link para desbloqueá-la.",
+			RetryAfter: result.RetryAfter,
+			Locked:     true,
+		}
+	}
+
+	// The lock of the document outlives the counters of the limiter, which
+	// are lost when Redis restarts or falls back to the memory
+	user, err := guard.users.GetUserByEmail(ctx, email)
+
+	if err != nil {
+		slog.WarnContext(ctx, "Error checking the lockout of the account", "error", err)
+	} else if now := guard.now(); user.Email != "" && user.IsLocked(now) {
+		return &BlockedError{
+			Message:    "Conta bloqueada temporariamente por excesso de tentativas. Enviamos um e-mail com o link para desbloqueá-la.",
+			RetryAfter: user.LockedUntil.Time().Sub(now),
+			Locked:     true,
+		}
+	}
+
+	// The window of the delay policy is only read when the counter starts
+	result, err = guard.limiter.Peek(ctx, delayPolicy(0), key)
+
+	if err != nil {
+		slog.WarnContext(ctx, "Error checking the delay of the email", "error", err)
+	} else if !result.Allowed {
+		return &BlockedError{Message: "Muitas tentativas de login. Aguarde alguns segundos e tente novamente.", RetryAfter: result.RetryAfter}
+	}
+
+	return nil
+}
+
+// Failed records a failed login. Once the email reaches the maximum of failed
+// logins it is locked and, when an account has it, the user gets an email to
+// unlock it.
+func (guard *Guard) Failed(ctx context.Context, email string, ip string) error {
+
+	if _, err := guard.limiter.Allow(ctx, guard.ipPolicy(), "ip:"+ip); err != nil {
+		slog.WarnContext(ctx, "Error counting the failed logins of the IP address", "error", err)
+	}
+
+	key := emailKey(email)
+
+	result, err := guard.limiter.Allow(ctx, guard.failuresPolicy(), key)
+
+	if err != nil {
+		return err
+	}
+
+	failedAttempts := result.Limit - result.Remaining
+
+	if !result.Allowed {
+		failedAttempts = result.Limit + 1
+	}
+
+	user, err := guard.users.RecordFailedLogin(ctx, email)
+
+	if err != nil {
+		return err
+	}
+
+	if failedAttempts < guard.settings.MaxFailedAttempts {
+		if delay := Delay(failedAttempts); delay > 0 {
+			_, err = guard.limiter.Allow(ctx, delayPolicy(delay), key)
+		}
+		return err
+	}
+
+	if _, err = guard.limiter.Allow(ctx, guard.lockPolicy(), key); err != nil {
+		return err
+	}
+
+	guard.reset(ctx, key, guard.failuresPolicy(), delayPolicy(0))
+
+	if user.Email == "" {
+		return nil
+	}
+
+	lockedUntil := guard.now().Add(guard.settings.LockoutDuration)
+
+	user.LockedUntil = primitive.NewDateTimeFromTime(lockedUntil)
+	user.UnlockToken = commons.GetValidationToken()
+
+	if err = guard.users.LockUser(ctx, &user); err != nil {
+		return err
+	}
+
+	metrics.LoginLockouts.Inc()
+	slog.WarnContext(ctx, "Account locked after failed logins", "user_id", user.Id.Hex(), "locked_until", lockedUntil)
+
+	brasilia := commons.GetBrasiliaTime().Location()
+	body := emails.GetUnlockAccountEmail(guard.baseUiHost, user.UnlockToken, lockedUntil.In(brasilia).Format("02/01/2006 15:04"))
+
+	guard.emailSender.Send(ctx, "", []string{user.Email}, "Conta bloqueada temporariamente", body)
+
+	return nil
+}
+
+// Succeeded clears the failed logins of the user.
+func (guard *Guard) Succeeded(ctx context.Context, user users.User) error {
+
+	guard.reset(ctx, emailKey(user.Email), guard.failuresPolicy(), delayPolicy(0))
+
+	if user.FailedLoginAttempts == 0 && user.LockedUntil == 0 {
+		return nil
+	}
+
+	return guard.users.UnlockUser(ctx, user.Id)
+}
+
+// Unlock lifts the lockout of the user, from the unlock link or by an admin.
+func (guard *Guard) Unlock(ctx context.Context, user users.User) error {
+
+	guard.reset(ctx, emailKey(user.Email), guard.lockPolicy(), guard.failuresPolicy(), delayPolicy(0))
+
+	return guard.users.UnlockUser(ctx, user.Id)
+}
+
+func (guard *Guard) reset(ctx context.Context, key string, policies ...ratelimit.Policy) {
+	for _, policy := range policies {
+		if err := guard.limiter.Reset(ctx, policy, key); err != nil {
+			slog.WarnContext(ctx, "Error clearing the failed logins of the email", "policy", policy.Name, "error", err)
+		}
+	}
+}
+
+// Delay returns how long after a failed login the next attempt of the email
+// must wait.
+func Delay(failedAttempts int) time.Duration {
+
+	if failedAttempts < DELAY_AFTER_ATTEMPTS {
+		return 0
+	}
+
+	shift := failedAttempts - DELAY_AFTER_ATTEMPTS
+
+	if shift >= 6 {
+		return MAX_DELAY
+	}
+
+	return time.Second << shift
+}
+
+// emailKey identifies the email in the limiter by a hash, so the counters do
+// not hold the emails tried.
+func emailKey(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return "email:" + hex.EncodeToString(sum[:16])
+}
+
+// failuresPolicy counts the failed logins of an email, forgotten
+// LockoutDuration after the first one.
+func (guard *Guard) failuresPolicy() ratelimit.Policy {
+	return ratelimit.Policy{
+		Name:      "login_email_failures",
+		Algorithm: ratelimit.FIXED_WINDOW,
+		Limit:     guard.settings.MaxFailedAttempts,
+		Window:    guard.settings.LockoutDuration,
+	}
+}
+
+// lockPolicy and delayPolicy are counters whose single request blocks the
+// logins of the email until their window ends.
+func (guard *Guard) lockPolicy() ratelimit.Policy {
+	return ratelimit.Policy{Name: "login_email_lock", Algorithm: ratelimit.FIXED_WINDOW, Limit: 1, Window: guard.settings.LockoutDuration}
+}
+
+func delayPolicy(delay time.Duration) ratelimit.Policy {
+	return ratelimit.Policy{Name: "login_email_delay", Algorithm: ratelimit.FIXED_WINDOW, Limit: 1, Window: delay}
+}
+
+func (guard *Guard) ipPolicy() ratelimit.Policy {
+	return ratelimit.Policy{
+		Name:      "login_failures",
+		Algorithm: ratelimit.FIXED_WINDOW,
+		Limit:     guard.settings.IpMaxFailedAttempts,
+		Window:    guard.settings.IpWindow,
+	}
+}
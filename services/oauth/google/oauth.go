@@ -5,26 +5,38 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/config"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users/tokens"
 	"github.com/flaviofrancisco/vagasprajr-api-v2/services/emails"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/validation"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
-const oauthGoogleUrlAPI = "https://www.googleapis.com/oauth2/v2/userinfo?access_token="
+const oauthGoogleUrlAPI = "https://www.googleapis.com/oauth2/v2/userinfo"
+
+// httpClient traces the calls to the Google API as child spans of the request.
+// The access token goes in the Authorization header, so it is not recorded
+// with the URL.
+var httpClient = &http.Client{
+	Transport: otelhttp.NewTransport(http.DefaultTransport),
+	Timeout:   10 * time.Second,
+}
 
 const (
 	USERS_GOOGLE_COLLECTION = "users_google"
 )
 
 type GoogleTokenRequest struct {
-	AccessToken string `json:"access_token"`
+	AccessToken string `json:"access_token" binding:"required,max=4096"`
 }
 
 type GoogleUserInfo struct {
@@ -42,34 +54,61 @@ type GoogleUserInfo struct {
 	DeletedAt  primitive.DateTime `json:"deleted_at"`
 }
 
-func OAuthGoogle(context *gin.Context) {
+type Controller struct {
+	settings             config.Config
+	googleUserRepository GoogleUserRepository
+	userRepository       users.UserRepository
+	tokenStore           tokens.TokenStore
+	emailSender          emails.Sender
+}
+
+func NewController(settings config.Config, googleUserRepository GoogleUserRepository, userRepository users.UserRepository, tokenStore tokens.TokenStore, emailSender emails.Sender) *Controller {
+	return &Controller{
+		settings:             settings,
+		googleUserRepository: googleUserRepository,
+		userRepository:       userRepository,
+		tokenStore:           tokenStore,
+		emailSender:          emailSender,
+	}
+}
+
+func (controller *Controller) OAuthGoogle(context *gin.Context) {
 	
 	var token_request GoogleTokenRequest
-	err := context.BindJSON(&token_request)
+	err := validation.Bind(context, &token_request)
 	
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})		
+		context.Error(err)
 		return
 	}
 
-	if token_request.AccessToken == "" {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "Token de acesso não informado"})		
+	request, err := http.NewRequestWithContext(context.Request.Context(), http.MethodGet, oauthGoogleUrlAPI, nil)
+
+	if err != nil {
+		context.Error(err)
 		return
 	}
 
-	response, err := http.Get(oauthGoogleUrlAPI + token_request.AccessToken)
+	request.Header.Set("Authorization", "Bearer "+token_request.AccessToken)
+
+	response, err := httpClient.Do(request)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
 	defer response.Body.Close()
 
+	if response.StatusCode != http.StatusOK {
+		context.Error(apperrors.Unauthorized("Token de acesso inválido"))
+		return
+	}
+
 	contents, err := io.ReadAll(response.Body)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}
 
@@ -79,18 +118,18 @@ func OAuthGoogle(context *gin.Context) {
 	err = json.Unmarshal(contents, &googleUserInfo)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		context.Error(apperrors.Unauthorized("Token de acesso inválido").Wrap(err))
 		return
 	}
 
-	currentUser, _ := users.GetUserByEmail(googleUserInfo.Email)
+	currentUser, _ := controller.userRepository.GetUserByEmail(context.Request.Context(), googleUserInfo.Email)
 
 	if currentUser.Email == "" {
 
-		new_google_user, err := googleUserInfo.Create()
+		new_google_user, err := controller.googleUserRepository.Create(context.Request.Context(), &googleUserInfo)
 
 		if err != nil {
-			context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			context.Error(err)
 			return
 		}
 
@@ -102,17 +141,17 @@ func OAuthGoogle(context *gin.Context) {
 			OAuthImageURL: new_google_user.Picture,
 		}
 
-		err = users.CreateUser(new_user)
+		err = controller.userRepository.CreateUser(context.Request.Context(), new_user)
 
 		if err != nil {
-			context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			context.Error(err)
 			return
 		}
 
-		currentUser, err = users.GetUserByEmail(new_google_user.Email)
+		currentUser, err = controller.userRepository.GetUserByEmail(context.Request.Context(), new_google_user.Email)
 
 		if err != nil {
-			context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			context.Error(err)
 			return
 		}
 
@@ -120,22 +159,22 @@ func OAuthGoogle(context *gin.Context) {
 
 		if !currentUser.IsEmailConfirmed {
 			currentUser.ValidationToken = tokens.GenerateValidationToken()
-			go emails.SendEmail("", []string{new_google_user.Email}, "Confirmação de email", emails.GetWelcomeEmail(currentUser.ValidationToken))
+			controller.emailSender.Send(context.Request.Context(), "", []string{new_google_user.Email}, "Confirmação de email", emails.GetWelcomeEmail(controller.settings.BaseUiHost, currentUser.ValidationToken))
 		}
 
-		err = users.Update(&currentUser)
+		err = controller.userRepository.Update(context.Request.Context(), &currentUser)
 
 		if err != nil {
-			context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			context.Error(err)
 			return
 		}
 
 	} else {
 
-		currentUser, err := users.GetUserByEmail(googleUserInfo.Email)
+		currentUser, err := controller.userRepository.GetUserByEmail(context.Request.Context(), googleUserInfo.Email)
 
 		if err != nil {
-			context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			context.Error(err)
 			return
 		}
 
@@ -145,28 +184,28 @@ func OAuthGoogle(context *gin.Context) {
 		
 		currentUser.OAuthImageURL = googleUserInfo.Picture
 
-		err = currentUser.Update()
+		err = controller.userRepository.UpdateProfile(context.Request.Context(), &currentUser)
 
 		if err != nil {
-			context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			context.Error(err)
 			return
 		}
 
 		if currentUser.Email == "" {
-			context.JSON(http.StatusBadRequest, gin.H{"error": "Usuário não encontrado"})
+			context.Error(apperrors.NotFound("Usuário não encontrado"))
 			return
 		}
 
 		if googleUserInfo.Verified && !currentUser.IsEmailConfirmed {			
-			err := currentUser.ConfirmEmail()
+			err := controller.userRepository.ConfirmEmail(context.Request.Context(), &currentUser)
 			if err != nil {
-				context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				context.Error(err)
 				return
 			}
 		}
 	}
 
-	currentUser.UpdateLastLogin()
+	controller.userRepository.UpdateLastLogin(context.Request.Context(), &currentUser)
 
 	userInfo := users.UserTokenInfo{
 		Email: strings.ToLower(currentUser.Email),
@@ -181,18 +220,18 @@ func OAuthGoogle(context *gin.Context) {
 	userToken := tokens.UserToken{
 		Id: currentUser.Id,
 	}	
-	err = userToken.SetToken(userInfo)
+	err = userToken.SetToken(controller.settings.Auth, userInfo)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}	
 
-	userToken.SetTokenCookie(context)
-	err = tokens.SaveRefreshToken(userInfo)
+	userToken.SetTokenCookie(controller.settings.Auth, context)
+	err = controller.tokenStore.SaveRefreshToken(context.Request.Context(), userInfo)
 
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		context.Error(err)
 		return
 	}	
 
@@ -205,30 +244,17 @@ func OAuthGoogle(context *gin.Context) {
 		ExpirationDate: primitive.NewDateTimeFromTime(tokenExpirationDate),		
 	}
 
+	metrics.OAuthLogins.WithLabelValues("google").Inc()
+
 	context.JSON(http.StatusOK, result)
 }
 
-func (user *GoogleUserInfo) Create() (*GoogleUserInfo, error) {
-
-	mongodb_database := os.Getenv("MONGODB_DATABASE")
-	client, err := models.Connect()
-
-	// Ensure the client connection is closed once the function completes
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			panic(err)
-		}
-	}()
-
-	if err != nil {
-		return nil, err
-	}
+func (user *GoogleUserInfo) Create(ctx context.Context, db *models.DbContext) (*GoogleUserInfo, error) {
 
-	db := client.Database(mongodb_database)
 
 	user.CreatedAt = primitive.NewDateTimeFromTime(time.Now().UTC())
 
-	_, err = db.Collection(USERS_GOOGLE_COLLECTION).InsertOne(context.Background(), user)
+	_, err := db.Collection(USERS_GOOGLE_COLLECTION).InsertOne(ctx, user)
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,50 @@
+package google
+
+import (
+	"context"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/metrics"
+	"sync"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GoogleUserRepository keeps the Google profiles of the users signed in with Google.
+type GoogleUserRepository interface {
+	Create(ctx context.Context, user *GoogleUserInfo) (*GoogleUserInfo, error)
+}
+
+type MongoGoogleUserRepository struct {
+	db *models.DbContext
+}
+
+func NewMongoGoogleUserRepository(db *models.DbContext) *MongoGoogleUserRepository {
+	return &MongoGoogleUserRepository{db: db}
+}
+
+func (repository *MongoGoogleUserRepository) Create(ctx context.Context, user *GoogleUserInfo) (*GoogleUserInfo, error) {
+	defer metrics.ObserveMongoOperation("google_users", "Create")()
+	return user.Create(ctx, repository.db)
+}
+
+// InMemoryGoogleUserRepository keeps the Google profiles in memory. It is meant for tests.
+type InMemoryGoogleUserRepository struct {
+	mutex sync.Mutex
+	users []GoogleUserInfo
+}
+
+func NewInMemoryGoogleUserRepository() *InMemoryGoogleUserRepository {
+	return &InMemoryGoogleUserRepository{}
+}
+
+func (repository *InMemoryGoogleUserRepository) Create(ctx context.Context, user *GoogleUserInfo) (*GoogleUserInfo, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	user.CreatedAt = primitive.NewDateTimeFromTime(time.Now().UTC())
+	repository.users = append(repository.users, *user)
+
+	return user, nil
+}
@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Fallback is a Limiter that uses the primary limiter, usually Redis, and
+// falls back to the in-memory counters while the primary is unreachable,
+// trying it again once the retry interval elapses.
+type Fallback struct {
+	primary       Limiter
+	memory        *Memory
+	retryInterval time.Duration
+
+	mutex    sync.Mutex
+	degraded bool
+	retryAt  time.Time
+}
+
+func NewFallback(primary Limiter, memory *Memory, retryInterval time.Duration) *Fallback {
+	return &Fallback{primary: primary, memory: memory, retryInterval: retryInterval}
+}
+
+func (limiter *Fallback) Allow(ctx context.Context, policy Policy, key string) (Result, error) {
+
+	if limiter.available() {
+
+		result, err := limiter.primary.Allow(ctx, policy, key)
+
+		if err == nil {
+			limiter.recovered(ctx)
+			return result, nil
+		}
+
+		limiter.failed(ctx, err)
+	}
+
+	return limiter.memory.Allow(ctx, policy, key)
+}
+
+func (limiter *Fallback) Peek(ctx context.Context, policy Policy, key string) (Result, error) {
+
+	if limiter.available() {
+
+		result, err := limiter.primary.Peek(ctx, policy, key)
+
+		if err == nil {
+			limiter.recovered(ctx)
+			return result, nil
+		}
+
+		limiter.failed(ctx, err)
+	}
+
+	return limiter.memory.Peek(ctx, policy, key)
+}
+
+// Reset forgets the requests in both limiters, since the in-memory counters
+// may have been used while the primary was unreachable.
+func (limiter *Fallback) Reset(ctx context.Context, policy Policy, key string) error {
+
+	if limiter.available() {
+
+		if err := limiter.primary.Reset(ctx, policy, key); err != nil {
+			limiter.failed(ctx, err)
+		} else {
+			limiter.recovered(ctx)
+		}
+	}
+
+	return limiter.memory.Reset(ctx, policy, key)
+}
+
+func (limiter *Fallback) available() bool {
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	return !limiter.degraded || !time.Now().Before(limiter.retryAt)
+}
+
+func (limiter *Fallback) failed(ctx context.Context, err error) {
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if !limiter.degraded {
+		slog.WarnContext(ctx, "Rate limiter unreachable, using the in-memory counters", "error", err, "retry_interval", limiter.retryInterval)
+	}
+
+	limiter.degraded = true
+	limiter.retryAt = time.Now().Add(limiter.retryInterval)
+}
+
+func (limiter *Fallback) recovered(ctx context.Context) {
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if limiter.degraded {
+		slog.InfoContext(ctx, "Rate limiter reachable again")
+		limiter.degraded = false
+	}
+}
@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+const (
+	FIXED_WINDOW = "fixed_window"
+	TOKEN_BUCKET = "token_bucket"
+)
+
+// Policy limits the requests of a client to Limit per Window. With a fixed
+// window the counter starts at the first request and resets when the window
+// ends; with a token bucket Limit is the burst and the tokens are refilled
+// continuously, Limit per Window.
+type Policy struct {
+	Name      string
+	Algorithm string
+	Limit     int
+	Window    time.Duration
+}
+
+// Result is the outcome of a request. Reset is the time left before the
+// client has all its requests again and RetryAfter, when the request is not
+// allowed, the time left before the next one is.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Limiter counts the requests of each client, identified by the key, under a
+// policy.
+type Limiter interface {
+	// Allow counts the request and tells whether it is allowed.
+	Allow(ctx context.Context, policy Policy, key string) (Result, error)
+	// Peek tells whether the next request would be allowed, without counting
+	// it.
+	Peek(ctx context.Context, policy Policy, key string) (Result, error)
+	// Reset forgets the requests of the client.
+	Reset(ctx context.Context, policy Policy, key string) error
+}
+
+func fixedWindowResult(policy Policy, count int64, ttl time.Duration) Result {
+
+	result := Result{
+		Allowed:   count <= int64(policy.Limit),
+		Limit:     policy.Limit,
+		Remaining: int(math.Max(0, float64(int64(policy.Limit)-count))),
+		Reset:     ttl,
+	}
+
+	if !result.Allowed {
+		result.RetryAfter = ttl
+	}
+
+	return result
+}
+
+func fixedWindowPeekResult(policy Policy, count int64, ttl time.Duration) Result {
+
+	result := fixedWindowResult(policy, count+1, ttl)
+	result.Remaining = int(math.Max(0, float64(int64(policy.Limit)-count)))
+
+	return result
+}
+
+// refill returns the tokens of the bucket after the time elapsed since the
+// last request.
+func refill(policy Policy, tokens float64, elapsed time.Duration) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(policy.Limit), tokens+float64(elapsed)*refillRate(policy))
+}
+
+// takeToken refills the bucket for the time elapsed since the last request
+// and takes a token, if there is one. It returns the tokens left.
+func takeToken(policy Policy, tokens float64, elapsed time.Duration) (bool, float64) {
+
+	tokens = refill(policy, tokens, elapsed)
+
+	if tokens < 1 {
+		return false, tokens
+	}
+
+	return true, tokens - 1
+}
+
+func tokenBucketResult(policy Policy, allowed bool, tokens float64) Result {
+
+	rate := refillRate(policy)
+
+	result := Result{
+		Allowed:   allowed,
+		Limit:     policy.Limit,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(policy.Limit) - tokens) / rate),
+	}
+
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) / rate)
+	}
+
+	return result
+}
+
+// refillRate is the number of tokens added per nanosecond.
+func refillRate(policy Policy) float64 {
+	return float64(policy.Limit) / float64(policy.Window)
+}
@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryFixedWindow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewMemory()
+	limiter.now = func() time.Time { return now }
+	policy := Policy{Name: "login", Algorithm: FIXED_WINDOW, Limit: 2, Window: time.Minute}
+
+	result, _ := limiter.Allow(ctx, policy, "ip:10.0.0.1")
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+
+	limiter.Allow(ctx, policy, "ip:10.0.0.1")
+
+	now = now.Add(20 * time.Second)
+	result, _ = limiter.Allow(ctx, policy, "ip:10.0.0.1")
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 40*time.Second, result.RetryAfter)
+
+	result, _ = limiter.Allow(ctx, policy, "ip:10.0.0.2")
+	assert.True(t, result.Allowed)
+
+	now = now.Add(40 * time.Second)
+	result, _ = limiter.Allow(ctx, policy, "ip:10.0.0.1")
+	assert.True(t, result.Allowed)
+}
+
+func TestMemoryTokenBucket(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewMemory()
+	limiter.now = func() time.Time { return now }
+	policy := Policy{Name: "search", Algorithm: TOKEN_BUCKET, Limit: 2, Window: time.Minute}
+
+	limiter.Allow(ctx, policy, "ip:10.0.0.1")
+	result, _ := limiter.Allow(ctx, policy, "ip:10.0.0.1")
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, time.Minute, result.Reset)
+
+	result, _ = limiter.Allow(ctx, policy, "ip:10.0.0.1")
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 30*time.Second, result.RetryAfter)
+
+	// A token is refilled every 30 seconds.
+	now = now.Add(30 * time.Second)
+	result, _ = limiter.Allow(ctx, policy, "ip:10.0.0.1")
+	assert.True(t, result.Allowed)
+}
+
+func TestMemoryPeekDoesNotCount(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewMemory()
+	policy := Policy{Name: "login_failures", Algorithm: FIXED_WINDOW, Limit: 1, Window: time.Minute}
+
+	result, _ := limiter.Peek(ctx, policy, "ip:10.0.0.1")
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+
+	limiter.Allow(ctx, policy, "ip:10.0.0.1")
+
+	result, _ = limiter.Peek(ctx, policy, "ip:10.0.0.1")
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Greater(t, result.RetryAfter, time.Duration(0))
+}
+
+func TestMemoryReset(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewMemory()
+	policy := Policy{Name: "login", Algorithm: FIXED_WINDOW, Limit: 1, Window: time.Minute}
+
+	limiter.Allow(ctx, policy, "email:a")
+	result, _ := limiter.Peek(ctx, policy, "email:a")
+	assert.False(t, result.Allowed)
+
+	assert.NoError(t, limiter.Reset(ctx, policy, "email:a"))
+
+	result, _ = limiter.Peek(ctx, policy, "email:a")
+	assert.True(t, result.Allowed)
+}
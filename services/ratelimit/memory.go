@@ -0,0 +1,147 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the expired counters are removed.
+const sweepInterval = time.Minute
+
+// Memory keeps the counters in the process, so each instance of the API
+// limits the requests on its own.
+type Memory struct {
+	mutex     sync.Mutex
+	windows   map[string]memoryWindow
+	buckets   map[string]memoryBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type memoryWindow struct {
+	count     int64
+	expiresAt time.Time
+}
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	expiresAt time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		windows: map[string]memoryWindow{},
+		buckets: map[string]memoryBucket{},
+		now:     time.Now,
+	}
+}
+
+func (limiter *Memory) Allow(ctx context.Context, policy Policy, key string) (Result, error) {
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := limiter.now()
+	limiter.sweep(now)
+
+	key = policy.Name + ":" + key
+
+	switch policy.Algorithm {
+	case FIXED_WINDOW:
+		window, ok := limiter.windows[key]
+
+		if !ok || !now.Before(window.expiresAt) {
+			window = memoryWindow{expiresAt: now.Add(policy.Window)}
+		}
+
+		window.count++
+		limiter.windows[key] = window
+
+		return fixedWindowResult(policy, window.count, window.expiresAt.Sub(now)), nil
+
+	case TOKEN_BUCKET:
+		bucket, ok := limiter.buckets[key]
+
+		if !ok || !now.Before(bucket.expiresAt) {
+			bucket = memoryBucket{tokens: float64(policy.Limit), updatedAt: now}
+		}
+
+		allowed, tokens := takeToken(policy, bucket.tokens, now.Sub(bucket.updatedAt))
+
+		limiter.buckets[key] = memoryBucket{tokens: tokens, updatedAt: now, expiresAt: now.Add(policy.Window)}
+
+		return tokenBucketResult(policy, allowed, tokens), nil
+	}
+
+	return Result{}, fmt.Errorf("invalid rate limit algorithm %q", policy.Algorithm)
+}
+
+func (limiter *Memory) Peek(ctx context.Context, policy Policy, key string) (Result, error) {
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := limiter.now()
+
+	key = policy.Name + ":" + key
+
+	switch policy.Algorithm {
+	case FIXED_WINDOW:
+		window, ok := limiter.windows[key]
+
+		if !ok || !now.Before(window.expiresAt) {
+			return fixedWindowPeekResult(policy, 0, 0), nil
+		}
+
+		return fixedWindowPeekResult(policy, window.count, window.expiresAt.Sub(now)), nil
+
+	case TOKEN_BUCKET:
+		bucket, ok := limiter.buckets[key]
+		tokens := float64(policy.Limit)
+
+		if ok && now.Before(bucket.expiresAt) {
+			tokens = refill(policy, bucket.tokens, now.Sub(bucket.updatedAt))
+		}
+
+		return tokenBucketResult(policy, tokens >= 1, tokens), nil
+	}
+
+	return Result{}, fmt.Errorf("invalid rate limit algorithm %q", policy.Algorithm)
+}
+
+func (limiter *Memory) Reset(ctx context.Context, policy Policy, key string) error {
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	key = policy.Name + ":" + key
+
+	delete(limiter.windows, key)
+	delete(limiter.buckets, key)
+
+	return nil
+}
+
+func (limiter *Memory) sweep(now time.Time) {
+
+	if now.Sub(limiter.lastSweep) < sweepInterval {
+		return
+	}
+
+	limiter.lastSweep = now
+
+	for key, window := range limiter.windows {
+		if !now.Before(window.expiresAt) {
+			delete(limiter.windows, key)
+		}
+	}
+
+	for key, bucket := range limiter.buckets {
+		if !now.Before(bucket.expiresAt) {
+			delete(limiter.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/cache"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/tracing"
+	"github.com/go-redis/redis"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	REDIS_KEY_PREFIX = "ratelimit:"
+)
+
+// The scripts run atomically, so the instances of the API share the
+// counters. They return the counter, or the tokens left, and the time to live
+// of the key in milliseconds.
+var fixedWindowScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {count, ttl}
+`)
+
+var tokenBucketScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated_at")
+local tokens = tonumber(state[1])
+local updated_at = tonumber(state[2])
+if tokens == nil or updated_at == nil then
+	tokens = limit
+	updated_at = now
+end
+tokens = math.min(limit, tokens + math.max(0, now - updated_at) * limit / window)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "updated_at", now)
+redis.call("PEXPIRE", KEYS[1], window)
+return {allowed, tostring(tokens)}
+`)
+
+// Redis keeps the counters in Redis, shared by the instances of the API.
+type Redis struct {
+	redis *cache.Redis
+}
+
+func NewRedis(redis *cache.Redis) *Redis {
+	return &Redis{redis: redis}
+}
+
+func (limiter *Redis) Allow(ctx context.Context, policy Policy, key string) (Result, error) {
+
+	key = REDIS_KEY_PREFIX + policy.Name + ":" + key
+
+	_, span := tracing.Start(ctx, "redis.EVALSHA", attribute.String("db.system", "redis"), attribute.String("db.redis.key", key))
+
+	result, err := limiter.run(policy, key)
+
+	tracing.End(span, err)
+
+	return result, err
+}
+
+func (limiter *Redis) Peek(ctx context.Context, policy Policy, key string) (Result, error) {
+
+	key = REDIS_KEY_PREFIX + policy.Name + ":" + key
+
+	_, span := tracing.Start(ctx, "redis.PEEK", attribute.String("db.system", "redis"), attribute.String("db.redis.key", key))
+
+	result, err := limiter.peek(policy, key)
+
+	tracing.End(span, err)
+
+	return result, err
+}
+
+func (limiter *Redis) Reset(ctx context.Context, policy Policy, key string) error {
+
+	key = REDIS_KEY_PREFIX + policy.Name + ":" + key
+
+	_, span := tracing.Start(ctx, "redis.DEL", attribute.String("db.system", "redis"), attribute.String("db.redis.key", key))
+
+	err := limiter.redis.RedisClient.Del(key).Err()
+
+	tracing.End(span, err)
+
+	return err
+}
+
+func (limiter *Redis) peek(policy Policy, key string) (Result, error) {
+
+	client := limiter.redis.RedisClient
+
+	switch policy.Algorithm {
+	case FIXED_WINDOW:
+		count, err := client.Get(key).Int64()
+
+		if err == redis.Nil {
+			return fixedWindowPeekResult(policy, 0, 0), nil
+		}
+
+		if err != nil {
+			return Result{}, err
+		}
+
+		ttl, err := client.PTTL(key).Result()
+
+		if err != nil {
+			return Result{}, err
+		}
+
+		return fixedWindowPeekResult(policy, count, ttl), nil
+
+	case TOKEN_BUCKET:
+		values, err := client.HMGet(key, "tokens", "updated_at").Result()
+
+		if err != nil {
+			return Result{}, err
+		}
+
+		tokens := float64(policy.Limit)
+
+		if len(values) == 2 && values[0] != nil && values[1] != nil {
+
+			stored, errTokens := strconv.ParseFloat(fmt.Sprint(values[0]), 64)
+			updatedAt, errUpdatedAt := strconv.ParseInt(fmt.Sprint(values[1]), 10, 64)
+
+			if errTokens != nil || errUpdatedAt != nil {
+				return Result{}, fmt.Errorf("unexpected rate limit state %v", values)
+			}
+
+			tokens = refill(policy, stored, time.Since(time.UnixMilli(updatedAt)))
+		}
+
+		return tokenBucketResult(policy, tokens >= 1, tokens), nil
+	}
+
+	return Result{}, fmt.Errorf("invalid rate limit algorithm %q", policy.Algorithm)
+}
+
+func (limiter *Redis) run(policy Policy, key string) (Result, error) {
+
+	window := policy.Window.Milliseconds()
+
+	switch policy.Algorithm {
+	case FIXED_WINDOW:
+		values, err := fixedWindowScript.Run(limiter.redis.RedisClient, []string{key}, window).Result()
+
+		if err != nil {
+			return Result{}, err
+		}
+
+		reply, ok := values.([]interface{})
+
+		if !ok || len(reply) != 2 {
+			return Result{}, fmt.Errorf("unexpected rate limit reply %v", values)
+		}
+
+		count, _ := reply[0].(int64)
+		ttl, _ := reply[1].(int64)
+
+		return fixedWindowResult(policy, count, time.Duration(ttl)*time.Millisecond), nil
+
+	case TOKEN_BUCKET:
+		now := time.Now().UnixMilli()
+
+		values, err := tokenBucketScript.Run(limiter.redis.RedisClient, []string{key}, policy.Limit, window, now).Result()
+
+		if err != nil {
+			return Result{}, err
+		}
+
+		reply, ok := values.([]interface{})
+
+		if !ok || len(reply) != 2 {
+			return Result{}, fmt.Errorf("unexpected rate limit reply %v", values)
+		}
+
+		allowed, _ := reply[0].(int64)
+		text, _ := reply[1].(string)
+		tokens, err := strconv.ParseFloat(text, 64)
+
+		if err != nil {
+			return Result{}, err
+		}
+
+		return tokenBucketResult(policy, allowed == 1, tokens), nil
+	}
+
+	return Result{}, fmt.Errorf("invalid rate limit algorithm %q", policy.Algorithm)
+}
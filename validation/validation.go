@@ -0,0 +1,141 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/flaviofrancisco/vagasprajr-api-v2/apperrors"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/commons"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/jobs"
+	"github.com/flaviofrancisco/vagasprajr-api-v2/models/users"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	USER_NAME_MESSAGE = "Nome de usuário inválido. Somete use letras e números; não use espaço e deve ter no mínimo 3 caracteres. Por favor, escolha outro."
+	INVALID_MESSAGE   = "Dados inválidos"
+)
+
+var register sync.Once
+
+// Register adds the custom validators to the validator of gin and makes the
+// field errors carry the JSON names of the fields. It is safe to call more
+// than once.
+func Register() {
+	register.Do(func() {
+		engine := binding.Validator.Engine().(*validator.Validate)
+
+		engine.RegisterTagNameFunc(jsonName)
+
+		engine.RegisterValidation("username", func(field validator.FieldLevel) bool {
+			return users.IsUserNameValid(field.Field().String())
+		})
+		engine.RegisterValidation("password", func(field validator.FieldLevel) bool {
+			return commons.ValidatePassword(field.Field().String()) == nil
+		})
+		engine.RegisterValidation("contract_type", func(field validator.FieldLevel) bool {
+			return slices.Contains(jobs.CONTRACT_TYPES, field.Field().String())
+		})
+		engine.RegisterValidation("job_mode", func(field validator.FieldLevel) bool {
+			return slices.Contains(jobs.JOB_MODES, field.Field().String())
+		})
+	})
+}
+
+// Bind reads the JSON body of the request into body and validates it by the
+// binding tags. A body that cannot be read is a BadRequest and invalid values
+// are a Validation error with one detail per field.
+func Bind(c *gin.Context, body interface{}) error {
+	Register()
+
+	err := c.ShouldBindJSON(body)
+
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrors validator.ValidationErrors
+
+	if !errors.As(err, &fieldErrors) {
+		return apperrors.BadRequest(apperrors.INVALID_BODY_MESSAGE).Wrap(err)
+	}
+
+	fields := make([]apperrors.FieldError, 0, len(fieldErrors))
+
+	for _, fieldError := range fieldErrors {
+		fields = append(fields, apperrors.FieldError{Field: fieldPath(fieldError), Message: message(fieldError)})
+	}
+
+	message := INVALID_MESSAGE
+	if len(fields) == 1 {
+		message = fields[0].Message
+	}
+
+	return apperrors.Validation(message, fields...).Wrap(err)
+}
+
+// jsonName names the fields as the clients send them.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// fieldPath is the path of the field in the body, such as links[0].url,
+// without the name of the body type.
+func fieldPath(fieldError validator.FieldError) string {
+	_, path, found := strings.Cut(fieldError.Namespace(), ".")
+	if !found {
+		return fieldError.Field()
+	}
+	return path
+}
+
+func message(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "Campo obrigatório"
+	case "email":
+		return "E-mail inválido"
+	case "url", "http_url":
+		return "URL inválida"
+	case "max":
+		return limitMessage(fieldError, "no máximo")
+	case "min":
+		return limitMessage(fieldError, "no mínimo")
+	case "oneof":
+		return "Deve ser um de: " + strings.Join(strings.Fields(fieldError.Param()), ", ")
+	case "excludesall":
+		return "Contém caracteres não permitidos"
+	case "username":
+		return USER_NAME_MESSAGE
+	case "password":
+		return commons.PASSWORD_POLICY_MESSAGE
+	case "contract_type":
+		return "Tipo de contrato inválido. Deve ser um de: " + strings.Join(jobs.CONTRACT_TYPES, ", ")
+	case "job_mode":
+		return "Modalidade inválida. Deve ser uma de: " + strings.Join(jobs.JOB_MODES, ", ")
+	}
+	return "Valor inválido"
+}
+
+func limitMessage(fieldError validator.FieldError, limit string) string {
+	switch fieldError.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("Deve ter %s %s caracteres", limit, fieldError.Param())
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fmt.Sprintf("Deve ter %s %s itens", limit, fieldError.Param())
+	}
+	return fmt.Sprintf("Deve ser %s %s", limit, fieldError.Param())
+}